@@ -3,14 +3,18 @@ package cmd
 import (
 	"io"
 
+	multierr "github.com/hashicorp/go-multierror"
 	"github.com/juju/errors"
 	commonoptions "github.com/slosive/sloscribe/cmd/options/common"
 	initoptions "github.com/slosive/sloscribe/cmd/options/init"
+	"github.com/slosive/sloscribe/internal/diagnostics"
 	"github.com/slosive/sloscribe/internal/generate"
 	"github.com/slosive/sloscribe/internal/logging"
 	"github.com/slosive/sloscribe/internal/parser"
-	"github.com/slosive/sloscribe/internal/parser/lang"
 	"github.com/slosive/sloscribe/internal/parser/options"
+	"github.com/slosive/sloscribe/internal/parser/specification/openslo"
+	"github.com/slosive/sloscribe/internal/parser/specification/prometheus"
+	"github.com/slosive/sloscribe/internal/parser/specification/pyrra"
 	"github.com/slosive/sloscribe/internal/parser/specification/sloth"
 	"github.com/spf13/cobra"
 )
@@ -29,6 +33,7 @@ func specInitCmd(common *commonoptions.Options) *cobra.Command {
 		Short:         "Init generates the Sloth definition specification from source code comments.",
 		Long:          `The init command parses files in the target directory for comments using the @sloth tags`,
 		SilenceErrors: true,
+		SilenceUsage:  true,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			logger := logging.LoggerFromContext(cmd.Context())
 			logger = logger.WithName("init")
@@ -42,18 +47,23 @@ func specInitCmd(common *commonoptions.Options) *cobra.Command {
 			case "sloth-k8s":
 				targetSpecParser = sloth.Parser(true)
 				outputKubernetes = true
+			case "openslo":
+				targetSpecParser = openslo.Parser(opts.Window)
+			case "pyrra":
+				targetSpecParser = pyrra.Parser(opts.Window)
+				outputKubernetes = true
+			case "prometheus-rules":
+				targetSpecParser = prometheus.Parser(false, opts.Window)
+			case "prometheus-rules-k8s":
+				targetSpecParser = prometheus.Parser(true, opts.Window)
+				outputKubernetes = true
 			default:
 				targetSpecParser = sloth.Parser(false)
 			}
 
-			switch opts.SourceLanguage {
-			case lang.Rust:
-				err := errors.New("The rust parser has not been fully implemented and shouldn't be used! It will have unexpected behaviours.")
-				logger.Error(err, "")
-				return err
-				targetLanguage = options.Language(lang.Rust)
-			default:
-				targetLanguage = options.Language(lang.Go)
+			targetLanguage = options.Language(opts.SourceLanguage)
+			if languages := opts.SourceLanguage.Languages(); len(languages) == 1 {
+				targetLanguage = options.Language(languages[0])
 			}
 
 			if opts.Source == "-" {
@@ -72,8 +82,13 @@ func specInitCmd(common *commonoptions.Options) *cobra.Command {
 
 			parser, err := parser.New(
 				targetLanguage,
+				options.LanguagePlugins(opts.LanguagePlugins...),
 				targetSpecParser,
 				options.Logger(&logger),
+				options.Diagnostics(diagnostics.NewPrinter(cmd.ErrOrStderr())),
+				options.Strict(opts.Strict),
+				options.ValidateQueries(opts.ValidateQueries),
+				options.Variables(opts.Variables),
 				options.SourceFile(opts.Source),
 				options.SourceContent(inputReader),
 				options.Include(opts.IncludedDirs...))
@@ -91,6 +106,7 @@ func specInitCmd(common *commonoptions.Options) *cobra.Command {
 			logger.Info("Source code was parsed ✅")
 
 			// check if the user has selected a target service to output
+			var problems error
 			selectedServices := services
 			for index, serviceName := range opts.Services {
 				// clear out the selectedServices
@@ -100,11 +116,18 @@ func specInitCmd(common *commonoptions.Options) *cobra.Command {
 
 				service, ok := services[serviceName]
 				if !ok {
-					logger.Warn(errors.Errorf("selected service specification %q was not found in the parser output", serviceName), "")
+					err := errors.Errorf("selected service specification %q was not found in the parser output", serviceName)
+					problems = multierr.Append(problems, err)
+					logger.Warn(err, "")
 				} else {
 					selectedServices[serviceName] = service
 				}
 			}
+			if opts.Strict && problems != nil {
+				err := diagnostics.Strict(problems)
+				logger.Error(err, "Service selector error, please try again")
+				return err
+			}
 
 			// Only print to file if the user has selected the to-file option
 			if opts.ToFile {
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	commonoptions "github.com/slosive/sloscribe/cmd/options/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// documents returns the YAML documents of the init output, the services are printed in any order
+func documents(output []byte) []string {
+	var docs []string
+	for _, doc := range strings.Split(string(output), "---\n") {
+		if doc = strings.TrimSpace(doc); doc != "" {
+			docs = append(docs, doc)
+		}
+	}
+	return docs
+}
+
+func TestInit(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Successfully parse a go only directory with the go packages parser by default", func(t *testing.T) {
+		// golang.yaml is the output of the go packages parser, the default before the languages were auto detected.
+		// Unlike the auto detection, the go packages parser doesn't skip the vendor directories.
+		expected, err := os.ReadFile("testdata/golang.yaml")
+		require.NoError(t, err)
+
+		var out bytes.Buffer
+		cmd := specInitCmd(commonoptions.New())
+		cmd.SetOut(&out)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs([]string{"--dirs", "testdata/golang"})
+		require.NoError(t, cmd.ExecuteContext(context.Background()))
+
+		assert.ElementsMatch(t, documents(expected), documents(out.Bytes()))
+	})
+}
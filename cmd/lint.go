@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"io"
+
+	commonoptions "github.com/slosive/sloscribe/cmd/options/common"
+	lintoptions "github.com/slosive/sloscribe/cmd/options/lint"
+	"github.com/slosive/sloscribe/internal/diagnostics"
+	"github.com/slosive/sloscribe/internal/lint"
+	"github.com/slosive/sloscribe/internal/logging"
+	"github.com/slosive/sloscribe/internal/parser"
+	"github.com/slosive/sloscribe/internal/parser/options"
+	"github.com/slosive/sloscribe/internal/parser/specification/sloth"
+	"github.com/spf13/cobra"
+)
+
+func lintCmd(common *commonoptions.Options) *cobra.Command {
+	opts := lintoptions.New(common)
+	var inputReader io.ReadCloser
+	var targetLanguage options.Option
+	cmd := &cobra.Command{
+		Use:           "lint",
+		Short:         "Lint checks the SLOs of the source code comments before generating the specifications.",
+		Long:          `The lint command parses files in the target directory for comments using the @sloth tags, and reports the SLOs sloth would reject`,
+		SilenceErrors: true,
+		SilenceUsage:  true,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			logger := logging.LoggerFromContext(cmd.Context())
+			logger = logger.WithName("lint")
+
+			if err := opts.Complete(); err != nil {
+				logger.Error(err, "flag argument error")
+				return err
+			}
+
+			targetLanguage = options.Language(opts.SourceLanguage)
+			if languages := opts.SourceLanguage.Languages(); len(languages) == 1 {
+				targetLanguage = options.Language(languages[0])
+			}
+
+			if opts.Source == "-" {
+				inputReader = io.NopCloser(cmd.InOrStdin())
+			}
+
+			cmd.SetContext(logging.ContextWithLogger(cmd.Context(), logger))
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			logger := logging.LoggerFromContext(cmd.Context())
+			logger.Info("Linting source code SLO definitions 🔍",
+				"directories", opts.IncludedDirs,
+				"source", opts.Source,
+			)
+
+			// the annotation diagnostics are printed together with the lint problems,
+			// which are positioned at the annotations declaring the SLOs
+			var problems diagnostics.List
+			positions := diagnostics.NewPositions()
+			parser, err := parser.New(
+				targetLanguage,
+				options.LanguagePlugins(opts.LanguagePlugins...),
+				sloth.Parser(false),
+				options.Logger(&logger),
+				options.Diagnostics(&problems),
+				options.Positions(positions),
+				options.ValidateQueries(true),
+				options.Variables(opts.Variables),
+				options.SourceFile(opts.Source),
+				options.SourceContent(inputReader),
+				options.Include(opts.IncludedDirs...))
+			if err != nil {
+				logger.Error(err, "Parser initialization error, please try again")
+				return err
+			}
+
+			services, err := parser.Parse(cmd.Context())
+			if err != nil {
+				logger.Error(err, "Parsing error, please try again")
+				return err
+			}
+
+			problems = append(problems, lint.Lint(services, positions)...)
+			printer := diagnostics.NewPrinter(cmd.OutOrStdout())
+			for _, problem := range problems {
+				printer.Report(problem)
+			}
+
+			if problems.HasErrors() {
+				return diagnostics.Strict(problems)
+			}
+
+			logger.Info("No SLO problems were found ✅")
+			return nil
+		},
+	}
+	opts = opts.Prepare(cmd)
+	return cmd
+}
@@ -0,0 +1,120 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	multierr "github.com/hashicorp/go-multierror"
+	"github.com/juju/errors"
+	"github.com/slosive/sloscribe/internal/parser/lang"
+	"github.com/spf13/pflag"
+)
+
+// SourceOptions is the list of options/flag selecting the annotated source files and how they are parsed,
+// shared by the commands parsing the annotations.
+type SourceOptions struct {
+	IncludedDirs    []string
+	Source          string
+	SourceLanguage  lang.Target
+	LanguagePlugins []string
+	Vars            []string
+	// Variables are the annotation variables parsed from Vars
+	Variables map[string]string
+}
+
+// CompleteSource checks the source options and parses the annotation variables
+func (o *SourceOptions) CompleteSource() error {
+	var err error
+	// @aloe code unsupported_language
+	// @aloe title Unsupported TargetLanguage Error
+	// @aloe summary The language passed to the --lang flag is not supported.
+	// @aloe details The source language passed to the --lang flag is not currently supported by the tool.
+	// The following are the supported languages: go(default), auto, rust, python, typescript, java, kotlin.
+	// Other languages are parsed by the sloscribe-lang-<name> language plugin found in PATH.
+	// WebAssembly language modules are loaded with the --lang-plugin flag.
+	if ok := lang.IsSupportedLanguage(o.SourceLanguage); !ok {
+		err = multierr.Append(err, errors.Errorf("unsupported language %q was passed to --lang flag", o.SourceLanguage))
+	}
+
+	// @aloe code invalid_language_plugin
+	// @aloe title Invalid Language Plugin Error
+	// @aloe summary The language module passed to the --lang-plugin flag is not valid.
+	// @aloe details The language module passed to the --lang-plugin flag doesn't exist or isn't a WebAssembly module.
+	// Language modules are WebAssembly files with the .wasm extension, i.e: --lang-plugin ./ini.wasm.
+	for _, plugin := range o.LanguagePlugins {
+		if info, statErr := os.Stat(plugin); statErr != nil || info.IsDir() || filepath.Ext(plugin) != ".wasm" {
+			err = multierr.Append(err, errors.Errorf("invalid language module %q was passed to --lang-plugin flag", plugin))
+		}
+	}
+
+	variables, varsErr := parseVariables(o.Vars)
+	if varsErr != nil {
+		err = multierr.Append(err, varsErr)
+	}
+	o.Variables = variables
+	return err
+}
+
+// parseVariables parses the key=value annotation variables passed to the --var flag
+func parseVariables(vars []string) (map[string]string, error) {
+	var err error
+	variables := make(map[string]string, len(vars))
+	for _, v := range vars {
+		key, value, ok := strings.Cut(v, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			// @aloe code invalid_variable_flag
+			// @aloe title Invalid Variable Flag
+			// @aloe summary The variable passed to the --var flag is not a key=value pair.
+			// @aloe details The variables passed to the --var flag override the annotation variables declared with @sloth.var,
+			// they must be key=value pairs, i.e: --var namespace=chatgpt.
+			err = multierr.Append(err, errors.Errorf("invalid variable %q was passed to --var flag, expected key=value", v))
+			continue
+		}
+		variables[strings.TrimSpace(key)] = value
+	}
+	return variables, err
+}
+
+func getWorkingDirOrDie() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		panic(err)
+	}
+	return dir
+}
+
+// AddSourceFlags assigns the source flags to the flag set
+func (o *SourceOptions) AddSourceFlags(fs *pflag.FlagSet) {
+	fs.StringSliceVar(
+		&o.IncludedDirs,
+		"dirs",
+		[]string{getWorkingDirOrDie()},
+		"Comma separated list of directories to be recursively parsed by the tool",
+	)
+	fs.StringVar(
+		(*string)(&o.SourceLanguage),
+		"lang",
+		string(lang.Go),
+		"Comma separated list of target source code languages, auto detects the language of each source file. Available: go(default), auto, rust, python, typescript, java, kotlin, or the name of a sloscribe-lang-<name> plugin in PATH.",
+	)
+	fs.StringSliceVar(
+		&o.LanguagePlugins,
+		"lang-plugin",
+		[]string{},
+		"Comma separated list of WebAssembly language modules extracting the comments of the source files. Example: --lang-plugin ./ini.wasm",
+	)
+	fs.StringArrayVar(
+		&o.Vars,
+		"var",
+		[]string{},
+		"Annotation variable overriding the one declared with @sloth.var, can be repeated. Example: --var namespace=chatgpt",
+	)
+	fs.StringVarP(
+		&o.Source,
+		"file",
+		"f",
+		"",
+		"Source code file to parse for annotations. Example: ./metrics.go",
+	)
+}
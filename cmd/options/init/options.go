@@ -1,13 +1,13 @@
 package init
 
 import (
-	"os"
+	"regexp"
 
 	multierr "github.com/hashicorp/go-multierror"
 	"github.com/juju/errors"
 	"github.com/slosive/sloscribe/cmd/options/common"
 	"github.com/slosive/sloscribe/internal/generate"
-	"github.com/slosive/sloscribe/internal/parser/lang"
+	"github.com/slosive/sloscribe/internal/parser/specification/prometheus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
@@ -16,14 +16,15 @@ type (
 	// Options is the list of options/flag available to the application,
 	// plus the clients needed by the application to function.
 	Options struct {
-		Formats        []string
-		IncludedDirs   []string
-		Source         string
-		SourceLanguage lang.Target
-		Specification  string
-		ToFile         bool
-		Services       []string
-		Target         string
+		Formats         []string
+		Specification   string
+		ToFile          bool
+		Services        []string
+		Strict          bool
+		ValidateQueries bool
+		Target          string
+		Window          string
+		common.SourceOptions
 		*common.Options
 	}
 )
@@ -55,51 +56,42 @@ func (o *Options) Complete() error {
 		}
 	}
 
-	// @aloe code unsupported_language
-	// @aloe title Unsupported TargetLanguage Error
-	// @aloe summary The language passed to the --lang flag is not supported.
-	// @aloe details The source language passed to the --lang flag is not currently supported by the tool.
-	// The following are the supported languages: go, wasm(experimental).
-	if ok := lang.IsSupportedLanguage(o.SourceLanguage); !ok {
-		err = multierr.Append(err, errors.Errorf("unsupported language %q was passed to --lang flag", o.SourceLanguage))
+	if sourceErr := o.CompleteSource(); sourceErr != nil {
+		err = multierr.Append(err, sourceErr)
 	}
-	return err
-}
 
-func getWorkingDirOrDie() string {
-	dir, err := os.Getwd()
-	if err != nil {
-		panic(err)
+	// @aloe code invalid_slo_window
+	// @aloe title Invalid SLO Window Error
+	// @aloe summary The time window passed to the --window flag is not valid.
+	// @aloe details The time window passed to the --window flag is not a valid prometheus duration.
+	// The window is a number followed by one of the units: s, m, h, d, w, y, i.e: 30d or 4w.
+	if !windowPattern.MatchString(o.Window) {
+		err = multierr.Append(err, errors.Errorf("invalid time window %q was passed to --window flag", o.Window))
+	} else if o.Target == "prometheus-rules" || o.Target == "prometheus-rules-k8s" {
+		// @aloe code unsupported_slo_window
+		// @aloe title Unsupported SLO Window Error
+		// @aloe summary The time window passed to the --window flag is not supported by the specification.
+		// @aloe details The prometheus-rules and prometheus-rules-k8s specifications generate the multi-window multi-burn-rate alerts
+		// of the 28d and 30d SLO periods only, the same as sloth. Pass --window 28d, 4w or 30d.
+		if periodErr := prometheus.ValidatePeriod(o.Window); periodErr != nil {
+			err = multierr.Append(err, errors.Annotatef(periodErr, "unsupported time window was passed to --window flag for the %s specification", o.Target))
+		}
 	}
-	return dir
+
+	return err
 }
 
+// windowPattern matches the prometheus durations, i.e: 4w or 1d12h
+var windowPattern = regexp.MustCompile(`^([0-9]+(ms|s|m|h|d|w|y))+$`)
+
 func (o *Options) addAppFlags(fs *pflag.FlagSet) {
-	fs.StringSliceVar(
-		&o.IncludedDirs,
-		"dirs",
-		[]string{getWorkingDirOrDie()},
-		"Comma separated list of directories to be recursively parsed by the tool",
-	)
+	o.AddSourceFlags(fs)
 	fs.StringSliceVar(
 		&o.Formats,
 		"format",
 		[]string{"yaml"},
 		"Format of the output returned by the tool. Available: yaml, json.",
 	)
-	fs.StringVar(
-		(*string)(&o.SourceLanguage),
-		"lang",
-		"go",
-		"Target source code language. Available: go.",
-	)
-	fs.StringVarP(
-		&o.Source,
-		"file",
-		"f",
-		"",
-		"Source code file to parse for annotations. Example: ./metrics.go",
-	)
 	fs.BoolVar(
 		&o.ToFile,
 		"to-file",
@@ -112,10 +104,28 @@ func (o *Options) addAppFlags(fs *pflag.FlagSet) {
 		[]string{},
 		"Comma separated list of service specification names. These will select the output service specifications returned by the tool. Example: --service-selector app1,app3 ",
 	)
+	fs.BoolVar(
+		&o.Strict,
+		"strict",
+		false,
+		"Tells the tool to fail, with exit code 2, if any annotation problem is found, i.e: a malformed annotation, a missing service or a service selector not found.",
+	)
+	fs.BoolVar(
+		&o.ValidateQueries,
+		"validate-queries",
+		false,
+		"Tells the tool to validate the PromQL queries of the SLIs, the invalid queries are reported at the position of their annotation.",
+	)
 	fs.StringVar(
 		&o.Target,
 		"specification",
 		"sloth",
-		"The SLO specification the tool should parse the source file for. Available: sloth, sloth-k8s.",
+		"The SLO specification the tool should parse the source file for. Available: sloth, sloth-k8s, openslo, pyrra, prometheus-rules, prometheus-rules-k8s.",
+	)
+	fs.StringVar(
+		&o.Window,
+		"window",
+		"30d",
+		"The time window of the SLOs, used by the openslo, pyrra and prometheus-rules specifications, the prometheus-rules specifications support 28d and 30d only. Example: 4w",
 	)
 }
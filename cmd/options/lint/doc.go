@@ -0,0 +1,2 @@
+// Package lint contains the different options present under the lint command.
+package lint
@@ -7,11 +7,16 @@ import (
 	"context"
 	"os"
 
+	"github.com/juju/errors"
 	commonoptions "github.com/slosive/sloscribe/cmd/options/common"
+	"github.com/slosive/sloscribe/internal/diagnostics"
 	"github.com/slosive/sloscribe/internal/logging"
 	"github.com/spf13/cobra"
 )
 
+// ExitCodeStrict is the exit code of the tool when annotation problems are found in strict mode
+const ExitCodeStrict = 2
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd *cobra.Command
 
@@ -40,9 +45,14 @@ func cmd(opts *commonoptions.Options) *cobra.Command {
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
+// The problems found in strict mode exit with ExitCodeStrict, the other errors with 1.
 func Execute(ctx context.Context) {
 	err := rootCmd.ExecuteContext(ctx)
 	if err != nil {
+		var strictErr *diagnostics.StrictError
+		if errors.As(err, &strictErr) {
+			os.Exit(ExitCodeStrict)
+		}
 		os.Exit(1)
 	}
 }
@@ -50,5 +60,6 @@ func init() {
 	opts := commonoptions.New()
 	rootCmd = cmd(opts)
 	rootCmd.AddCommand(specInitCmd(opts))
+	rootCmd.AddCommand(lintCmd(opts))
 	rootCmd.AddCommand(versionCmd)
 }
@@ -0,0 +1,18 @@
+package main
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// @sloth service chatgpt
+// @sloth labels team platform
+
+var (
+	// @sloth.slo name chat-gpt-availability
+	// @sloth.slo objective 95.0
+	// @sloth.sli error_query sum(rate(tenant_failed_login_operations_total{client="chat-gpt"}[{{.window}}])) OR on() vector(0)
+	// @sloth.sli total_query sum(rate(tenant_login_operations_total{client="chat-gpt"}[{{.window}}]))
+	// @sloth.slo description 95% of logins to the chat-gpt app should be successful.
+	// @sloth.alerting name ChatGPTAvailability
+	metricTenantLogins = prometheus.NewCounterVec(prometheus.CounterOpts{Name: "tenant_login_operations_total"}, []string{"client"})
+)
+
+func main() {}
@@ -0,0 +1,8 @@
+package metrics
+
+// @sloth service auth0
+// @sloth.slo name auth0-latency
+// @sloth.slo objective 99.0
+// @sloth.sli error_ratio_query sum(rate(auth0_slow_requests_total[{{.window}}]))
+// @sloth.alerting name Auth0Latency
+var slowRequests = 1
@@ -0,0 +1,296 @@
+package diagnostics
+
+import (
+	"fmt"
+	"go/token"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	multierr "github.com/hashicorp/go-multierror"
+)
+
+type (
+	// Severity is the severity of a diagnostic, errors discard the annotations they are reported for
+	Severity string
+
+	// Code is the stable code identifying the kind of diagnostic, i.e: invalid_annotation_value
+	Code string
+
+	// Diagnostic is a problem found in an annotation, at a position in a source file
+	Diagnostic struct {
+		// Pos is the position of the annotation in the source file
+		Pos      token.Position
+		Severity Severity
+		Code     Code
+		Message  string
+	}
+
+	// List is a list of diagnostics, returned as a single error
+	List []Diagnostic
+
+	// Reporter receives the diagnostics reported while parsing the source files
+	Reporter interface {
+		Report(d Diagnostic)
+	}
+
+	// Printer is a Reporter printing the diagnostics to a writer, one per line
+	Printer struct {
+		w  io.Writer
+		mu sync.Mutex
+	}
+
+	// Positions are the positions of the annotations declaring the services and the SLOs in the source files.
+	// The problems found once the annotations are parsed, i.e: by the lint rules, are reported at these positions.
+	Positions struct {
+		// slos are the positions of the SLOs, indexed by service and SLO name
+		slos map[string]map[string]SLOPosition
+		// services are the positions of the @sloth service annotations, a service can be declared more than once
+		services []ServicePosition
+	}
+
+	// ServicePosition is the position of a @sloth service annotation
+	ServicePosition struct {
+		// Service is the service name, as declared
+		Service string
+		Pos     token.Position
+	}
+
+	// SLOPosition is the position of the annotations declaring an SLO
+	SLOPosition struct {
+		// SLO is the position of the @sloth.slo name annotation
+		SLO token.Position
+		// SLI is the position of the first @sloth.sli annotation, the position of the SLO if it doesn't declare its SLI
+		SLI token.Position
+	}
+
+	// StrictError is the error returned by the parsers running in strict mode,
+	// Problems aggregates every problem, i.e: warnings and diagnostics, found while parsing the source files
+	StrictError struct {
+		Problems error
+	}
+)
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+const (
+	// @aloe code annotation_syntax_error
+	// @aloe title Annotation Syntax Error
+	// @aloe summary The annotation doesn't follow the @sloth annotations syntax.
+	// @aloe details The annotation doesn't follow the @sloth annotations syntax, i.e: a missing value or an unknown scope.
+	// The annotations are written as: @sloth[.scope] attribute value, i.e: @sloth.slo objective 99.9.
+	CodeSyntaxError Code = "annotation_syntax_error"
+
+	// @aloe code unknown_annotation_attribute
+	// @aloe title Unknown Annotation Attribute
+	// @aloe summary The annotation attribute is not available in the annotation scope.
+	// @aloe details The annotation attribute is not available in the annotation scope and is ignored, i.e: @sloth.sli objective 99.9.
+	// Check the attributes available in each scope of the sloth specification.
+	CodeUnknownAttribute Code = "unknown_annotation_attribute"
+
+	// @aloe code invalid_annotation_value
+	// @aloe title Invalid Annotation Value
+	// @aloe summary The annotation value doesn't match the type of the attribute.
+	// @aloe details The annotation value doesn't match the type of the attribute, i.e: an objective which isn't a number,
+	// a disable which isn't a boolean or labels which aren't key value pairs.
+	CodeInvalidValue Code = "invalid_annotation_value"
+
+	// @aloe code invalid_promql_query
+	// @aloe title Invalid PromQL Query
+	// @aloe summary The SLI query is not a valid PromQL expression.
+	// @aloe details The error_query, total_query or error_ratio_query of the SLI is not a valid PromQL expression,
+	// the {{.window}} template is replaced by a sample duration before the query is parsed, i.e: sum(rate(http_requests_total[{{.window}}])).
+	CodeInvalidQuery Code = "invalid_promql_query"
+
+	// @aloe code conflicting_slo_definition
+	// @aloe title Conflicting SLO Definition
+	// @aloe summary The SLO is already defined with different values.
+	// @aloe details The annotations of an SLO, found in several comment groups, set the same field with different values,
+	// i.e: two different objectives. The annotations of the SLOs with the same name are merged, the conflicting comment group is skipped.
+	CodeConflictingSLO Code = "conflicting_slo_definition"
+
+	// @aloe code invalid_slo_template
+	// @aloe title Invalid SLO Template
+	// @aloe summary The SLO template doesn't exist or can't be instantiated.
+	// @aloe details The template used by @sloth.slo use isn't declared by a @sloth.template annotation, is declared twice,
+	// or one of its placeholders, i.e: {{.handler}}, isn't set by the key=value parameters of the use annotation.
+	CodeInvalidTemplate Code = "invalid_slo_template"
+
+	// @aloe code invalid_annotation_variable
+	// @aloe title Invalid Annotation Variable
+	// @aloe summary The variable referenced by an annotation isn't declared, or is declared twice with different values.
+	// @aloe details The ${name} references must be declared by a @sloth.var name value annotation, in the same comment group, in a comment group
+	// of the same service or of the same package, or be set with the --var name=value flag. A variable can't be declared twice in the same scope
+	// with different values.
+	CodeInvalidVariable Code = "invalid_annotation_variable"
+
+	// @aloe code unresolved_go_constant
+	// @aloe title Unresolved Go Constant
+	// @aloe summary The Go constant referenced by an annotation can't be resolved.
+	// @aloe details The {{go "Ident"}} references are replaced by the value of the Ident constant, declared at package level in the package
+	// of the annotation. The identifier isn't declared, isn't a constant, its value depends on an imported package or the annotation isn't in a Go source file.
+	CodeUnresolvedConstant Code = "unresolved_go_constant"
+
+	// @aloe code unresolved_metric
+	// @aloe title Unresolved Metric
+	// @aloe summary The Prometheus metric referenced by an SLI query can't be resolved.
+	// @aloe details The {{.metric}} references are replaced by the name of the metric created by the Go declaration the annotations document,
+	// i.e: prometheus.NewCounter(prometheus.CounterOpts{...}), the {{.metrics.<var>}} references by the name of the metric created by the var
+	// package level variable. The declaration doesn't create a metric, the variable isn't declared or the annotation isn't in a Go source file.
+	CodeUnresolvedMetric Code = "unresolved_metric"
+
+	// @aloe code undeclared_metric
+	// @aloe title Undeclared Metric
+	// @aloe summary An SLI query references a metric or a label which isn't declared in the source code.
+	// @aloe details The metrics and labels of the SLI queries are checked against the metrics declared in the Go packages, i.e: by prometheus.NewCounterVec
+	// or promauto.NewHistogram, and the label names of the *Vec metrics. The check catches the typos in the metric and label names,
+	// it only runs if the source code declares metrics, on the queries annotated in the Go source files.
+	// The job and instance labels added by prometheus are always accepted.
+	CodeUndeclaredMetric Code = "undeclared_metric"
+)
+
+// New returns an error diagnostic
+func New(pos token.Position, code Code, format string, args ...any) Diagnostic {
+	return Diagnostic{
+		Pos:      pos,
+		Severity: SeverityError,
+		Code:     code,
+		Message:  fmt.Sprintf(format, args...),
+	}
+}
+
+// Warning returns a warning diagnostic
+func Warning(pos token.Position, code Code, format string, args ...any) Diagnostic {
+	d := New(pos, code, format, args...)
+	d.Severity = SeverityWarning
+	return d
+}
+
+// Error returns the diagnostic as a compiler error, i.e: metrics.go:12:4: error: invalid objective [invalid_annotation_value].
+// The position is omitted if the diagnostic isn't positioned in a source file.
+func (d Diagnostic) Error() string {
+	if !d.Pos.IsValid() && d.Pos.Filename == "" {
+		return fmt.Sprintf("%s: %s [%s]", d.Severity, d.Message, d.Code)
+	}
+	return fmt.Sprintf("%s: %s: %s [%s]", d.Pos, d.Severity, d.Message, d.Code)
+}
+
+// Error returns the diagnostics, one per line
+func (l List) Error() string {
+	lines := make([]string, 0, len(l))
+	for _, d := range l {
+		lines = append(lines, d.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Report appends the diagnostic to the list, so that the list can be used as a Reporter
+func (l *List) Report(d Diagnostic) {
+	*l = append(*l, d)
+}
+
+// HasErrors returns true if any of the diagnostics is an error
+func (l List) HasErrors() bool {
+	for _, d := range l {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// NewPrinter returns a Reporter printing the diagnostics to w
+func NewPrinter(w io.Writer) *Printer {
+	return &Printer{w: w}
+}
+
+// Report prints the diagnostic
+func (p *Printer) Report(d Diagnostic) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, _ = fmt.Fprintln(p.w, d.Error())
+}
+
+// NewPositions returns the empty Positions of the annotations
+func NewPositions() *Positions {
+	return &Positions{slos: map[string]map[string]SLOPosition{}}
+}
+
+// Add records the position of the SLO of the service, the position of an SLO declared more than once is the first one
+func (p *Positions) Add(service, slo string, pos SLOPosition) {
+	if p.slos[service] == nil {
+		p.slos[service] = map[string]SLOPosition{}
+	}
+	if _, ok := p.slos[service][slo]; !ok {
+		p.slos[service][slo] = pos
+	}
+}
+
+// SLO returns the position of the annotations declaring the SLO of the service, empty if unknown
+func (p *Positions) SLO(service, slo string) SLOPosition {
+	if p == nil {
+		return SLOPosition{}
+	}
+	return p.slos[service][slo]
+}
+
+// AddService records the position of a @sloth service annotation
+func (p *Positions) AddService(service string, pos token.Position) {
+	p.services = append(p.services, ServicePosition{Service: service, Pos: pos})
+}
+
+// Services returns the positions of the @sloth service annotations, sorted by position
+func (p *Positions) Services() []ServicePosition {
+	if p == nil {
+		return nil
+	}
+	services := append([]ServicePosition(nil), p.services...)
+	sort.SliceStable(services, func(i, j int) bool {
+		a, b := services[i].Pos, services[j].Pos
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return services
+}
+
+// Strict returns the StrictError of the problems, nil if there are none
+func Strict(problems error) error {
+	if problems == nil {
+		return nil
+	}
+	return &StrictError{Problems: problems}
+}
+
+// Error returns the summary of the problems found in strict mode, the problems themselves are reported as they are found
+func (e *StrictError) Error() string {
+	return fmt.Sprintf("%d problem(s) found", count(e.Problems))
+}
+
+// count returns the number of problems aggregated in the error
+func count(err error) int {
+	switch problems := err.(type) {
+	case List:
+		return len(problems)
+	case *multierr.Error:
+		n := 0
+		for _, problem := range problems.Errors {
+			n += count(problem)
+		}
+		return n
+	}
+	return 1
+}
+
+// Unwrap returns the problems found in strict mode
+func (e *StrictError) Unwrap() error {
+	return e.Problems
+}
@@ -0,0 +1,78 @@
+package diagnostics
+
+import (
+	"bytes"
+	"go/token"
+	"testing"
+
+	multierr "github.com/hashicorp/go-multierror"
+	"github.com/juju/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiagnostic(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Successfully format the diagnostic as a compiler error", func(t *testing.T) {
+		d := New(token.Position{Filename: "metrics.go", Line: 12, Column: 4}, CodeInvalidValue, "invalid objective %q", "high")
+		assert.Equal(t, `metrics.go:12:4: error: invalid objective "high" [invalid_annotation_value]`, d.Error())
+	})
+
+	t.Run("Successfully format the diagnostic without a file name", func(t *testing.T) {
+		d := Warning(token.Position{Line: 2, Column: 1}, CodeUnknownAttribute, "unknown attribute")
+		assert.Equal(t, `2:1: warning: unknown attribute [unknown_annotation_attribute]`, d.Error())
+	})
+
+	t.Run("Successfully format the diagnostic without a position", func(t *testing.T) {
+		d := New(token.Position{}, CodeInvalidValue, "invalid objective")
+		assert.Equal(t, `error: invalid objective [invalid_annotation_value]`, d.Error())
+	})
+
+	t.Run("Successfully return true if the list contains an error", func(t *testing.T) {
+		l := List{Warning(token.Position{}, CodeUnknownAttribute, "unknown attribute")}
+		assert.False(t, l.HasErrors())
+		l = append(l, New(token.Position{}, CodeSyntaxError, "unexpected token"))
+		assert.True(t, l.HasErrors())
+	})
+
+	t.Run("Successfully print the diagnostics one per line", func(t *testing.T) {
+		var w bytes.Buffer
+		p := NewPrinter(&w)
+		p.Report(New(token.Position{Filename: "main.go", Line: 1, Column: 4}, CodeSyntaxError, "unexpected token"))
+		p.Report(Warning(token.Position{Filename: "main.go", Line: 2, Column: 4}, CodeUnknownAttribute, "unknown attribute"))
+		assert.Equal(t, `main.go:1:4: error: unexpected token [annotation_syntax_error]
+main.go:2:4: warning: unknown attribute [unknown_annotation_attribute]
+`, w.String())
+	})
+}
+
+func TestStrict(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Successfully return nil if no problem was found", func(t *testing.T) {
+		assert.NoError(t, Strict(nil))
+	})
+
+	t.Run("Successfully wrap the problems in a StrictError", func(t *testing.T) {
+		problems := List{Warning(token.Position{Line: 2, Column: 1}, CodeUnknownAttribute, "unknown attribute")}
+		err := Strict(problems)
+
+		var strictErr *StrictError
+		require.ErrorAs(t, err, &strictErr)
+		assert.Equal(t, "1 problem(s) found", err.Error())
+
+		var list List
+		assert.ErrorAs(t, err, &list)
+	})
+
+	t.Run("Successfully summarize the aggregated problems", func(t *testing.T) {
+		var problems error
+		problems = multierr.Append(problems, errors.New("selected service specification \"foo\" was not found in the parser output"))
+		problems = multierr.Append(problems, List{
+			New(token.Position{Line: 1, Column: 1}, CodeSyntaxError, "unexpected token"),
+			Warning(token.Position{Line: 2, Column: 1}, CodeUnknownAttribute, "unknown attribute"),
+		})
+		assert.Equal(t, "3 problem(s) found", Strict(problems).Error())
+	})
+}
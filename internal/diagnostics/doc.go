@@ -0,0 +1,3 @@
+// Package diagnostics contains the diagnostics reported for the annotations found in the source files,
+// printed like compiler errors: file:line:col: severity: message [code]
+package diagnostics
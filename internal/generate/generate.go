@@ -20,6 +20,10 @@ const DefaultServiceDefinitionDir = "slo_definitions"
 // ErrUnsupportedFormat is returned if the output format is unsupported
 var ErrUnsupportedFormat = errors.New("the specification is in an invalid format")
 
+// Documents is a specification made of several documents, i.e: the OpenSLO Service, SLO and SLI documents of a service.
+// The documents are written as a multi-document yaml stream or as a json array.
+type Documents []any
+
 func IsValidOutputFormat(format string) bool {
 	format = strings.ToLower(strings.TrimSpace(format))
 	switch format {
@@ -38,7 +42,7 @@ func WriteK8Specifications(writer io.Writer, header []byte, specs map[string]any
 			format = strings.ToLower(strings.TrimSpace(format))
 			switch format {
 			case "yaml":
-				body, err := k8syaml.Marshal(spec)
+				body, err := marshalYAML(spec, k8syaml.Marshal)
 				if err != nil {
 					return err
 				}
@@ -81,7 +85,7 @@ func WriteSpecifications(writer io.Writer, header []byte, specs map[string]any,
 			format = strings.ToLower(strings.TrimSpace(format))
 			switch format {
 			case "json":
-				body, err := json.Marshal(spec)
+				body, err := marshalJSON(spec)
 				if err != nil {
 					return err
 				}
@@ -96,7 +100,7 @@ func WriteSpecifications(writer io.Writer, header []byte, specs map[string]any,
 					return err
 				}
 			case "yaml":
-				body, err := yaml.Marshal(spec)
+				body, err := marshalYAML(spec, yaml.Marshal)
 				if err != nil {
 					return err
 				}
@@ -130,6 +134,31 @@ func WriteSpecifications(writer io.Writer, header []byte, specs map[string]any,
 	return nil
 }
 
+// marshalJSON returns the json encoding of the specification, the Documents are encoded as a json array
+func marshalJSON(spec any) ([]byte, error) {
+	if docs, ok := spec.(Documents); ok {
+		return json.Marshal([]any(docs))
+	}
+	return json.Marshal(spec)
+}
+
+// marshalYAML returns the yaml encoding of the specification, the Documents are separated by the yaml document separator
+func marshalYAML(spec any, marshal func(any) ([]byte, error)) ([]byte, error) {
+	docs, ok := spec.(Documents)
+	if !ok {
+		return marshal(spec)
+	}
+	bodies := make([][]byte, 0, len(docs))
+	for _, doc := range docs {
+		body, err := marshal(doc)
+		if err != nil {
+			return nil, err
+		}
+		bodies = append(bodies, body)
+	}
+	return bytes.Join(bodies, []byte("---\n")), nil
+}
+
 func clean(files ...string) error {
 	for _, file := range files {
 		if _, err := os.Stat(file); !errors.Is(err, os.ErrNotExist) {
@@ -139,6 +139,34 @@ service: app1
 	})
 }
 
+func TestWriteDocuments(t *testing.T) {
+	documents := map[string]any{
+		"app1": Documents{
+			map[string]string{"kind": "Service"},
+			map[string]string{"kind": "SLO"},
+		},
+	}
+
+	t.Run("successfully write the yaml documents to the byte writer", func(t *testing.T) {
+		var w = bytes.NewBuffer([]byte{})
+		require.NoError(t, WriteSpecifications(w, nil, documents, false, "", "yaml"))
+		assert.Equal(t, "---\n\nkind: Service\n---\nkind: SLO\n", w.String())
+	})
+
+	t.Run("successfully write the json documents to the byte writer", func(t *testing.T) {
+		var w = bytes.NewBuffer([]byte{})
+		require.NoError(t, WriteSpecifications(w, nil, documents, false, "", "json"))
+		assert.Equal(t, `
+[{"kind":"Service"},{"kind":"SLO"}]`, w.String())
+	})
+
+	t.Run("successfully write the kubernetes yaml documents to the byte writer", func(t *testing.T) {
+		var w = bytes.NewBuffer([]byte{})
+		require.NoError(t, WriteK8Specifications(w, nil, documents, false, "", "yaml"))
+		assert.Equal(t, "---\n\nkind: Service\n---\nkind: SLO\n", w.String())
+	})
+}
+
 func TestIsValidOutputFormat(t *testing.T) {
 	t.Run("Successfully return true if the input format is json", func(t *testing.T) {
 		assert.True(t, IsValidOutputFormat("json"))
@@ -0,0 +1,3 @@
+// Package lint contains the semantic checks of the sloth specifications parsed from the annotations,
+// reporting the SLOs that sloth would reject when generating the Prometheus rules.
+package lint
@@ -0,0 +1,219 @@
+package lint
+
+import (
+	"fmt"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	sloth "github.com/slok/sloth/pkg/prometheus/api/v1"
+	"github.com/slosive/sloscribe/internal/diagnostics"
+)
+
+const (
+	// @aloe code invalid_slo_objective
+	// @aloe title Invalid SLO Objective
+	// @aloe summary The SLO objective is not within (0,100].
+	// @aloe details The SLO objective is the percentage of good events, it must be greater than 0 and lower or equal to 100,
+	// i.e: @sloth.slo objective 99.9.
+	CodeInvalidObjective diagnostics.Code = "invalid_slo_objective"
+
+	// @aloe code invalid_sli
+	// @aloe title Invalid SLI
+	// @aloe summary The SLI is not exactly one of events, raw or plugin.
+	// @aloe details The SLI must be exactly one of events, raw or plugin, i.e: an SLI with an error_query and an error_ratio_query is rejected.
+	// The events SLIs must have both the error_query and the total_query.
+	CodeInvalidSLI diagnostics.Code = "invalid_sli"
+
+	// @aloe code missing_window_template
+	// @aloe title Missing Window Template
+	// @aloe summary The SLI query doesn't use the {{.window}} template.
+	// @aloe details The SLI query doesn't use the {{.window}} template, which sloth replaces with the time windows of the rules,
+	// i.e: sum(rate(http_request_duration_seconds_count{code=~"5.."}[{{.window}}])).
+	CodeMissingWindow diagnostics.Code = "missing_window_template"
+
+	// @aloe code invalid_slo_name
+	// @aloe title Invalid SLO Name
+	// @aloe summary The SLO name is not a valid DNS label.
+	// @aloe details The SLO name is used to name the generated rules and resources, it must be a valid DNS label:
+	// at most 63 lowercase alphanumeric characters or '-', starting and ending with an alphanumeric character.
+	CodeInvalidName diagnostics.Code = "invalid_slo_name"
+
+	// @aloe code duplicate_service
+	// @aloe title Duplicate Service
+	// @aloe summary The service name is used by more than one service specification.
+	// @aloe details The service name is used by more than one service specification, the names are compared ignoring the case
+	// as the service specifications are written to files named after the service.
+	// A service declared in more than one directory is reported too, as its SLOs would be merged into the same specification,
+	// the service can be declared more than once in the same directory, i.e: in the files of a Go package.
+	CodeDuplicateService diagnostics.Code = "duplicate_service"
+
+	// @aloe code missing_alert_name
+	// @aloe title Missing Alert Name
+	// @aloe summary The SLO alerting doesn't have a name while the page or ticket alerts are enabled.
+	// @aloe details The SLO alerting doesn't have a name while the page or ticket alerts are enabled,
+	// i.e: @sloth.alerting name MyServiceHighErrorRate, or disable both alerts with @sloth.alerting.page disable true and @sloth.alerting.ticket disable true.
+	CodeMissingAlertName diagnostics.Code = "missing_alert_name"
+)
+
+var (
+	// windowTemplate matches the sloth window template in the SLI queries, i.e: {{.window}}
+	windowTemplate = regexp.MustCompile(`{{\s*\.window\s*}}`)
+	// dnsLabel matches the RFC 1123 DNS labels
+	dnsLabel = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+)
+
+// maxDNSLabelLength is the maximum length of a RFC 1123 DNS label
+const maxDNSLabelLength = 63
+
+// check is a semantic check of an SLO of the service specification, pos is the position of the annotations declaring the SLO
+type check func(spec *sloth.Spec, slo sloth.SLO, pos diagnostics.SLOPosition) diagnostics.List
+
+// checks are the semantic checks run on each SLO
+var checks = []check{
+	checkObjective,
+	checkSLI,
+	checkWindow,
+	checkName,
+	checkAlerting,
+}
+
+// Lint runs the semantic checks on the sloth service specifications, as returned by the sloth parser.
+// The problems are returned as error diagnostics, sorted by service and SLO, positioned at the annotations declaring the services and the SLOs.
+func Lint(specs map[string]any, positions *diagnostics.Positions) diagnostics.List {
+	var services []*sloth.Spec
+	for _, spec := range specs {
+		if s, ok := spec.(*sloth.Spec); ok {
+			services = append(services, s)
+		}
+	}
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].Service < services[j].Service
+	})
+
+	problems := checkServices(positions.Services())
+	for _, spec := range services {
+		for _, slo := range spec.SLOs {
+			for _, c := range checks {
+				problems = append(problems, c(spec, slo, positions.SLO(spec.Service, slo.Name))...)
+			}
+		}
+	}
+	return problems
+}
+
+// problem returns the error diagnostic of an SLO, at the position of the annotation the problem is found in
+func problem(pos token.Position, spec *sloth.Spec, slo sloth.SLO, code diagnostics.Code, format string, args ...any) diagnostics.Diagnostic {
+	d := diagnostics.New(pos, code, format, args...)
+	d.Message = fmt.Sprintf("service %q SLO %q: %s", spec.Service, slo.Name, d.Message)
+	return d
+}
+
+// checkServices checks the service names are unique, ignoring the case, before the service specifications are merged.
+// A service declared more than once in the same directory, i.e: in the files of a package, is merged and isn't reported.
+func checkServices(services []diagnostics.ServicePosition) diagnostics.List {
+	var problems diagnostics.List
+	first := map[string]diagnostics.ServicePosition{}
+	for _, service := range services {
+		key := strings.ToLower(service.Service)
+		declared, ok := first[key]
+		switch {
+		case !ok:
+			first[key] = service
+		case declared.Service != service.Service:
+			problems = append(problems, diagnostics.New(service.Pos, CodeDuplicateService,
+				"service %q has the same name as service %q declared at %s", service.Service, declared.Service, declared.Pos))
+		case filepath.Dir(declared.Pos.Filename) != filepath.Dir(service.Pos.Filename):
+			problems = append(problems, diagnostics.New(service.Pos, CodeDuplicateService,
+				"service %q is already declared at %s, in another directory", service.Service, declared.Pos))
+		}
+	}
+	return problems
+}
+
+// checkObjective checks the objective is within (0,100]
+func checkObjective(spec *sloth.Spec, slo sloth.SLO, pos diagnostics.SLOPosition) diagnostics.List {
+	if slo.Objective > 0 && slo.Objective <= 100 {
+		return nil
+	}
+	return diagnostics.List{problem(pos.SLO, spec, slo, CodeInvalidObjective, "objective %v is not within (0,100]", slo.Objective)}
+}
+
+// checkSLI checks the SLI is exactly one of events, raw or plugin, and the events SLI has both queries
+func checkSLI(spec *sloth.Spec, slo sloth.SLO, pos diagnostics.SLOPosition) diagnostics.List {
+	var kinds []string
+	if slo.SLI.Events != nil {
+		kinds = append(kinds, "events")
+	}
+	if slo.SLI.Raw != nil {
+		kinds = append(kinds, "raw")
+	}
+	if slo.SLI.Plugin != nil {
+		kinds = append(kinds, "plugin")
+	}
+	switch {
+	case len(kinds) == 0:
+		return diagnostics.List{problem(pos.SLI, spec, slo, CodeInvalidSLI, "the SLI is missing, one of events, raw or plugin is required")}
+	case len(kinds) > 1:
+		return diagnostics.List{problem(pos.SLI, spec, slo, CodeInvalidSLI, "the SLI is %s, only one of events, raw or plugin is allowed", strings.Join(kinds, " and "))}
+	}
+
+	var problems diagnostics.List
+	if events := slo.SLI.Events; events != nil {
+		if events.ErrorQuery == "" {
+			problems = append(problems, problem(pos.SLI, spec, slo, CodeInvalidSLI, "the events SLI is missing the error_query"))
+		}
+		if events.TotalQuery == "" {
+			problems = append(problems, problem(pos.SLI, spec, slo, CodeInvalidSLI, "the events SLI is missing the total_query"))
+		}
+	}
+	if raw := slo.SLI.Raw; raw != nil && raw.ErrorRatioQuery == "" {
+		problems = append(problems, problem(pos.SLI, spec, slo, CodeInvalidSLI, "the raw SLI is missing the error_ratio_query"))
+	}
+	if plugin := slo.SLI.Plugin; plugin != nil && plugin.ID == "" {
+		problems = append(problems, problem(pos.SLI, spec, slo, CodeInvalidSLI, "the plugin SLI is missing the plugin id"))
+	}
+	return problems
+}
+
+// checkWindow checks the SLI queries use the {{.window}} template
+func checkWindow(spec *sloth.Spec, slo sloth.SLO, pos diagnostics.SLOPosition) diagnostics.List {
+	queries := map[string]string{}
+	if events := slo.SLI.Events; events != nil {
+		queries["error_query"] = events.ErrorQuery
+		queries["total_query"] = events.TotalQuery
+	}
+	if raw := slo.SLI.Raw; raw != nil {
+		queries["error_ratio_query"] = raw.ErrorRatioQuery
+	}
+
+	var problems diagnostics.List
+	for _, attribute := range []string{"error_query", "total_query", "error_ratio_query"} {
+		query, ok := queries[attribute]
+		// the missing queries are reported by checkSLI
+		if !ok || query == "" || windowTemplate.MatchString(query) {
+			continue
+		}
+		problems = append(problems, problem(pos.SLI, spec, slo, CodeMissingWindow, "the %s doesn't use the {{.window}} template", attribute))
+	}
+	return problems
+}
+
+// checkName checks the SLO name is a DNS label
+func checkName(spec *sloth.Spec, slo sloth.SLO, pos diagnostics.SLOPosition) diagnostics.List {
+	if len(slo.Name) <= maxDNSLabelLength && dnsLabel.MatchString(slo.Name) {
+		return nil
+	}
+	return diagnostics.List{problem(pos.SLO, spec, slo, CodeInvalidName, "the name is not a valid DNS label, it must be at most %d lowercase alphanumeric characters or '-'", maxDNSLabelLength)}
+}
+
+// checkAlerting checks the alerting has a name if the page or ticket alerts are enabled
+func checkAlerting(spec *sloth.Spec, slo sloth.SLO, pos diagnostics.SLOPosition) diagnostics.List {
+	alerting := slo.Alerting
+	if alerting.Name != "" || (alerting.PageAlert.Disable && alerting.TicketAlert.Disable) {
+		return nil
+	}
+	return diagnostics.List{problem(pos.SLO, spec, slo, CodeMissingAlertName, "the alerting name is missing, it is required unless both the page and ticket alerts are disabled")}
+}
@@ -0,0 +1,158 @@
+package lint
+
+import (
+	"go/token"
+	"testing"
+
+	sloth "github.com/slok/sloth/pkg/prometheus/api/v1"
+	"github.com/slosive/sloscribe/internal/diagnostics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// validSLO returns an SLO passing all the checks
+func validSLO() sloth.SLO {
+	return sloth.SLO{
+		Name:      "requests-availability",
+		Objective: 99.9,
+		SLI: sloth.SLI{
+			Events: &sloth.SLIEvents{
+				ErrorQuery: `sum(rate(http_request_duration_seconds_count{code=~"(5..|429)"}[{{.window}}]))`,
+				TotalQuery: `sum(rate(http_request_duration_seconds_count[{{ .window }}]))`,
+			},
+		},
+		Alerting: sloth.Alerting{Name: "MyServiceHighErrorRate"},
+	}
+}
+
+// codes returns the codes of the diagnostics
+func codes(problems diagnostics.List) []diagnostics.Code {
+	var result []diagnostics.Code
+	for _, p := range problems {
+		result = append(result, p.Code)
+	}
+	return result
+}
+
+func TestLint(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Successfully return no problems for a valid SLO", func(t *testing.T) {
+		problems := Lint(map[string]any{"myservice": &sloth.Spec{Service: "myservice", SLOs: []sloth.SLO{validSLO()}}}, nil)
+		assert.Empty(t, problems)
+	})
+
+	t.Run("Successfully report the objective not within (0,100]", func(t *testing.T) {
+		for _, objective := range []float64{0, -1, 100.1} {
+			slo := validSLO()
+			slo.Objective = objective
+			problems := Lint(map[string]any{"myservice": &sloth.Spec{Service: "myservice", SLOs: []sloth.SLO{slo}}}, nil)
+			assert.Equal(t, []diagnostics.Code{CodeInvalidObjective}, codes(problems), objective)
+		}
+
+		slo := validSLO()
+		slo.Objective = 100
+		assert.Empty(t, Lint(map[string]any{"myservice": &sloth.Spec{Service: "myservice", SLOs: []sloth.SLO{slo}}}, nil))
+	})
+
+	t.Run("Successfully report the SLI which isn't exactly one of events, raw or plugin", func(t *testing.T) {
+		slo := validSLO()
+		slo.SLI.Raw = &sloth.SLIRaw{ErrorRatioQuery: `sum(rate(errors[{{.window}}]))`}
+		problems := Lint(map[string]any{"myservice": &sloth.Spec{Service: "myservice", SLOs: []sloth.SLO{slo}}}, nil)
+		require.Equal(t, []diagnostics.Code{CodeInvalidSLI}, codes(problems))
+		assert.Equal(t, `error: service "myservice" SLO "requests-availability": the SLI is events and raw, only one of events, raw or plugin is allowed [invalid_sli]`, problems[0].Error())
+
+		slo.SLI = sloth.SLI{}
+		problems = Lint(map[string]any{"myservice": &sloth.Spec{Service: "myservice", SLOs: []sloth.SLO{slo}}}, nil)
+		assert.Equal(t, []diagnostics.Code{CodeInvalidSLI}, codes(problems))
+	})
+
+	t.Run("Successfully report the events SLI missing a query", func(t *testing.T) {
+		slo := validSLO()
+		slo.SLI.Events.TotalQuery = ""
+		problems := Lint(map[string]any{"myservice": &sloth.Spec{Service: "myservice", SLOs: []sloth.SLO{slo}}}, nil)
+		require.Equal(t, []diagnostics.Code{CodeInvalidSLI}, codes(problems))
+		assert.Contains(t, problems[0].Message, "missing the total_query")
+	})
+
+	t.Run("Successfully report the queries without the window template", func(t *testing.T) {
+		slo := validSLO()
+		slo.SLI.Events.ErrorQuery = `sum(rate(http_request_duration_seconds_count{code=~"(5..|429)"}[5m]))`
+		problems := Lint(map[string]any{"myservice": &sloth.Spec{Service: "myservice", SLOs: []sloth.SLO{slo}}}, nil)
+		require.Equal(t, []diagnostics.Code{CodeMissingWindow}, codes(problems))
+		assert.Contains(t, problems[0].Message, "the error_query doesn't use the {{.window}} template")
+
+		slo.SLI = sloth.SLI{Plugin: &sloth.SLIPlugin{ID: "sloth-common/kubernetes/apiserver/availability"}}
+		assert.Empty(t, Lint(map[string]any{"myservice": &sloth.Spec{Service: "myservice", SLOs: []sloth.SLO{slo}}}, nil))
+	})
+
+	t.Run("Successfully report the SLO names which aren't DNS labels", func(t *testing.T) {
+		for _, name := range []string{"", "Availability", "requests_availability", "-availability", "requests.availability", string(make([]byte, 64))} {
+			slo := validSLO()
+			slo.Name = name
+			problems := Lint(map[string]any{"myservice": &sloth.Spec{Service: "myservice", SLOs: []sloth.SLO{slo}}}, nil)
+			assert.Equal(t, []diagnostics.Code{CodeInvalidName}, codes(problems), name)
+		}
+	})
+
+	t.Run("Successfully position the problems at the annotations declaring the SLO and its SLI", func(t *testing.T) {
+		slo := validSLO()
+		slo.Objective = 0
+		slo.SLI.Events.ErrorQuery = `sum(rate(http_request_duration_seconds_count{code=~"(5..|429)"}[5m]))`
+		positions := diagnostics.NewPositions()
+		positions.Add("myservice", slo.Name, diagnostics.SLOPosition{
+			SLO: token.Position{Filename: "main.go", Line: 3, Column: 4},
+			SLI: token.Position{Filename: "main.go", Line: 5, Column: 4},
+		})
+		problems := Lint(map[string]any{"myservice": &sloth.Spec{Service: "myservice", SLOs: []sloth.SLO{slo}}}, positions)
+		require.Equal(t, []diagnostics.Code{CodeInvalidObjective, CodeMissingWindow}, codes(problems))
+		assert.Equal(t, "main.go:3:4", problems[0].Pos.String())
+		assert.Equal(t, "main.go:5:4", problems[1].Pos.String())
+	})
+
+	t.Run("Successfully report the service names which aren't unique", func(t *testing.T) {
+		positions := diagnostics.NewPositions()
+		positions.AddService("myservice", token.Position{Filename: "main.go", Line: 3, Column: 4})
+		positions.AddService("MyService", token.Position{Filename: "api.go", Line: 1, Column: 4})
+		problems := Lint(map[string]any{
+			"myservice": &sloth.Spec{Service: "myservice", SLOs: []sloth.SLO{validSLO()}},
+			"MyService": &sloth.Spec{Service: "MyService", SLOs: []sloth.SLO{validSLO()}},
+		}, positions)
+		require.Equal(t, []diagnostics.Code{CodeDuplicateService}, codes(problems))
+		assert.Equal(t, "main.go:3:4", problems[0].Pos.String())
+		assert.Equal(t, `service "myservice" has the same name as service "MyService" declared at api.go:1:4`, problems[0].Message)
+	})
+
+	t.Run("Successfully report the services declared with the same name in different directories", func(t *testing.T) {
+		positions := diagnostics.NewPositions()
+		positions.AddService("myservice", token.Position{Filename: "billing/main.go", Line: 3, Column: 4})
+		positions.AddService("myservice", token.Position{Filename: "auth/main.go", Line: 3, Column: 4})
+		problems := Lint(map[string]any{
+			"myservice": &sloth.Spec{Service: "myservice", SLOs: []sloth.SLO{validSLO()}},
+		}, positions)
+		require.Equal(t, []diagnostics.Code{CodeDuplicateService}, codes(problems))
+		assert.Equal(t, "billing/main.go:3:4", problems[0].Pos.String())
+		assert.Equal(t, `service "myservice" is already declared at auth/main.go:3:4, in another directory`, problems[0].Message)
+	})
+
+	t.Run("Successfully merge the services declared with the same name in the same directory", func(t *testing.T) {
+		positions := diagnostics.NewPositions()
+		positions.AddService("myservice", token.Position{Filename: "api/main.go", Line: 3, Column: 4})
+		positions.AddService("myservice", token.Position{Filename: "api/alerts.go", Line: 1, Column: 4})
+		problems := Lint(map[string]any{
+			"myservice": &sloth.Spec{Service: "myservice", SLOs: []sloth.SLO{validSLO()}},
+		}, positions)
+		assert.Empty(t, problems)
+	})
+
+	t.Run("Successfully report the alerting without a name if the alerts are enabled", func(t *testing.T) {
+		slo := validSLO()
+		slo.Alerting.Name = ""
+		slo.Alerting.PageAlert.Disable = true
+		problems := Lint(map[string]any{"myservice": &sloth.Spec{Service: "myservice", SLOs: []sloth.SLO{slo}}}, nil)
+		assert.Equal(t, []diagnostics.Code{CodeMissingAlertName}, codes(problems))
+
+		slo.Alerting.TicketAlert.Disable = true
+		assert.Empty(t, Lint(map[string]any{"myservice": &sloth.Spec{Service: "myservice", SLOs: []sloth.SLO{slo}}}, nil))
+	})
+}
@@ -1,20 +1,70 @@
 package lang
 
+import (
+	"os/exec"
+	"strings"
+)
+
 type (
-	// Target represents the language for the source file parsed by the generator
+	// Target represents the language for the source file parsed by the generator.
+	// Several languages can be targeted at once, as a comma separated list, i.e: go,python
 	Target string
 )
 
 const (
-	Go   = Target("go")
-	Rust = Target("rust")
+	Go         = Target("go")
+	Rust       = Target("rust")
+	Python     = Target("python")
+	TypeScript = Target("typescript")
+	Java       = Target("java")
+	Kotlin     = Target("kotlin")
+	// Auto targets all the supported languages, the language of each source file is detected from its extension or shebang
+	Auto = Target("auto")
 )
 
-// IsSupportedLanguage returns true is the input language is a supported language
+// PluginPrefix is the prefix of the language plugin executables, i.e: sloscribe-lang-ini is the plugin for the ini language
+const PluginPrefix = "sloscribe-lang-"
+
+// Plugin returns the name of the plugin executable for the language, i.e: sloscribe-lang-ini
+func (t Target) Plugin() string {
+	return PluginPrefix + string(t)
+}
+
+// IsPlugin returns true if the language is parsed by a plugin executable found in PATH
+func IsPlugin(l Target) bool {
+	if l == "" || strings.ContainsAny(string(l), `/\`) {
+		return false
+	}
+	_, err := exec.LookPath(l.Plugin())
+	return err == nil
+}
+
+// Languages returns the list of languages in the target, i.e: [go python] for go,python
+func (t Target) Languages() []Target {
+	var languages []Target
+	for _, l := range strings.Split(string(t), ",") {
+		if l = strings.ToLower(strings.TrimSpace(l)); l != "" {
+			languages = append(languages, Target(l))
+		}
+	}
+	return languages
+}
+
+// IsSupportedLanguage returns true is the input language is a supported language or a language plugin found in PATH,
+// if multiple languages are targeted all of them need to be supported
 func IsSupportedLanguage(l Target) bool {
-	switch l {
-	case Go, Rust:
-		return true
+	languages := l.Languages()
+	if len(languages) == 0 {
+		return false
+	}
+	for _, language := range languages {
+		switch language {
+		case Go, Rust, Python, TypeScript, Java, Kotlin, Auto:
+		default:
+			if !IsPlugin(language) {
+				return false
+			}
+		}
 	}
-	return false
+	return true
 }
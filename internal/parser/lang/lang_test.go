@@ -1,8 +1,12 @@
 package lang
 
 import (
-	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"runtime"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestIsSupportedLanguage(t *testing.T) {
@@ -16,7 +20,74 @@ func TestIsSupportedLanguage(t *testing.T) {
 		assert.True(t, IsSupportedLanguage(Rust))
 	})
 
+	t.Run("Successfully return true if Python is the target language", func(t *testing.T) {
+		assert.True(t, IsSupportedLanguage(Python))
+	})
+
+	t.Run("Successfully return true if TypeScript is the target language", func(t *testing.T) {
+		assert.True(t, IsSupportedLanguage(TypeScript))
+	})
+
+	t.Run("Successfully return true if Java is the target language", func(t *testing.T) {
+		assert.True(t, IsSupportedLanguage(Java))
+	})
+
+	t.Run("Successfully return true if Kotlin is the target language", func(t *testing.T) {
+		assert.True(t, IsSupportedLanguage(Kotlin))
+	})
+
+	t.Run("Successfully return true if auto is the target language", func(t *testing.T) {
+		assert.True(t, IsSupportedLanguage(Auto))
+	})
+
+	t.Run("Successfully return true if multiple supported languages are targeted", func(t *testing.T) {
+		assert.True(t, IsSupportedLanguage("go, python,rust"))
+	})
+
 	t.Run("Fail to return true if the language is different from the supported ones", func(t *testing.T) {
-		assert.False(t, IsSupportedLanguage("Python"))
+		assert.False(t, IsSupportedLanguage("cobol"))
+	})
+
+	t.Run("Fail to return true if any of the targeted languages is not supported", func(t *testing.T) {
+		assert.False(t, IsSupportedLanguage("go,cobol"))
+	})
+
+	t.Run("Fail to return true if no language is targeted", func(t *testing.T) {
+		assert.False(t, IsSupportedLanguage(" , "))
+	})
+}
+
+func TestLanguages(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Successfully return the single target language", func(t *testing.T) {
+		assert.Equal(t, []Target{Go}, Go.Languages())
+	})
+
+	t.Run("Successfully return the list of target languages", func(t *testing.T) {
+		assert.Equal(t, []Target{Go, Python, Rust}, Target("go, Python,,rust").Languages())
+	})
+}
+
+func TestIsPlugin(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin executables are looked up using unix file permissions")
+	}
+
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "sloscribe-lang-ini"), []byte("#!/bin/sh\n"), 0755))
+	t.Setenv("PATH", dir)
+
+	t.Run("Successfully return true if the language plugin is in PATH", func(t *testing.T) {
+		assert.True(t, IsPlugin("ini"))
+		assert.True(t, IsSupportedLanguage("go,ini"))
+	})
+
+	t.Run("Fail to return true if the language plugin is not in PATH", func(t *testing.T) {
+		assert.False(t, IsPlugin("toml"))
+	})
+
+	t.Run("Fail to return true if the language is a path", func(t *testing.T) {
+		assert.False(t, IsPlugin("../ini"))
 	})
 }
@@ -3,6 +3,7 @@ package options
 import (
 	"io"
 
+	"github.com/slosive/sloscribe/internal/diagnostics"
 	"github.com/slosive/sloscribe/internal/logging"
 	"github.com/slosive/sloscribe/internal/parser/lang"
 	"github.com/slosive/sloscribe/internal/parser/specification"
@@ -20,6 +21,10 @@ type (
 		// Option: func Language(lang lang.Target) Option
 		TargetLanguage lang.Target
 
+		// LanguagePlugins are the paths to the WebAssembly language modules used to extract the source files comments, i.e: ini.wasm.
+		// Option: func LanguagePlugins(paths ...string) Option
+		LanguagePlugins []string
+
 		// IncludedDirs is the array containing all the directories that will be parsed by the parser.
 		// SourceFile and SourceContent will override this, if present.
 		// Option: func Include(dirs ...string) Option
@@ -29,6 +34,26 @@ type (
 		// Option: func Logger(logger *logging.Logger) Option
 		Logger *logging.Logger
 
+		// Reporter receives the diagnostics of the annotations found in the source files, these are logged as warnings if nil
+		// Option: func Diagnostics(reporter diagnostics.Reporter) Option
+		Reporter diagnostics.Reporter
+
+		// Positions receives the positions of the annotations declaring the parsed SLOs, used to position the problems found in the specifications
+		// Option: func Positions(positions *diagnostics.Positions) Option
+		Positions *diagnostics.Positions
+
+		// Strict tells the parser to fail if any problem is found in the annotations, every warning is returned as an error.
+		// Option: func Strict(strict bool) Option
+		Strict bool
+
+		// ValidateQueries tells the parser to validate the PromQL queries of the SLIs, the invalid queries are reported as diagnostics.
+		// Option: func ValidateQueries(validate bool) Option
+		ValidateQueries bool
+
+		// Variables are the annotation variables overriding the ones declared with @sloth.var
+		// Option: func Variables(vars map[string]string) Option
+		Variables map[string]string
+
 		// SourceFile is the file the parser will parse. Shouldn't be used together with SourceContent
 		// Option: func SourceFile(file string) Option
 		SourceFile string
@@ -57,6 +82,41 @@ func Logger(logger *logging.Logger) Option {
 	}
 }
 
+// Diagnostics configure the reporter of the diagnostics of the annotations found in the source files
+func Diagnostics(reporter diagnostics.Reporter) Option {
+	return func(e *Options) {
+		e.Reporter = reporter
+	}
+}
+
+// Positions configure the positions receiving the positions of the annotations declaring the parsed SLOs
+func Positions(positions *diagnostics.Positions) Option {
+	return func(e *Options) {
+		e.Positions = positions
+	}
+}
+
+// Strict configure the parser to return every warning, aggregated in a diagnostics.StrictError
+func Strict(strict bool) Option {
+	return func(e *Options) {
+		e.Strict = strict
+	}
+}
+
+// ValidateQueries configure the parser to validate the PromQL queries of the SLIs
+func ValidateQueries(validate bool) Option {
+	return func(e *Options) {
+		e.ValidateQueries = validate
+	}
+}
+
+// Variables configure the parser to override the annotation variables declared with @sloth.var
+func Variables(vars map[string]string) Option {
+	return func(e *Options) {
+		e.Variables = vars
+	}
+}
+
 // SourceFile configure the parser to parse a specific file
 // Shouldn't be used together with SourceContent
 func SourceFile(file string) Option {
@@ -80,6 +140,13 @@ func Language(lang lang.Target) Option {
 	}
 }
 
+// LanguagePlugins configure the parser to parse the source files using the WebAssembly language modules
+func LanguagePlugins(paths ...string) Option {
+	return func(o *Options) {
+		o.LanguagePlugins = paths
+	}
+}
+
 // Specification configure the parser to parse for a specific target specification
 func Specification(target specification.Target) Option {
 	return func(o *Options) {
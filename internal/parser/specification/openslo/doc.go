@@ -0,0 +1,3 @@
+// Package openslo contains the parser targeting OpenSLO v1 as a specification.
+// The sloth annotations are parsed by the sloth parser and converted to the OpenSLO Service, SLO and SLI documents.
+package openslo
@@ -0,0 +1,102 @@
+package openslo
+
+// The OpenSLO v1 documents, see https://github.com/OpenSLO/OpenSLO#specification
+type (
+	// Metadata is the metadata of the OpenSLO documents
+	Metadata struct {
+		Name        string            `json:"name" yaml:"name"`
+		DisplayName string            `json:"displayName,omitempty" yaml:"displayName,omitempty"`
+		Labels      map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+		Annotations map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+	}
+
+	// Service is the OpenSLO Service document, a group of SLOs
+	Service struct {
+		APIVersion string      `json:"apiVersion" yaml:"apiVersion"`
+		Kind       string      `json:"kind" yaml:"kind"`
+		Metadata   Metadata    `json:"metadata" yaml:"metadata"`
+		Spec       ServiceSpec `json:"spec" yaml:"spec"`
+	}
+
+	ServiceSpec struct {
+		Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	}
+
+	// SLI is the OpenSLO SLI document, the service level indicator of a SLO
+	SLI struct {
+		APIVersion string   `json:"apiVersion" yaml:"apiVersion"`
+		Kind       string   `json:"kind" yaml:"kind"`
+		Metadata   Metadata `json:"metadata" yaml:"metadata"`
+		Spec       SLISpec  `json:"spec" yaml:"spec"`
+	}
+
+	SLISpec struct {
+		Description string       `json:"description,omitempty" yaml:"description,omitempty"`
+		RatioMetric *RatioMetric `json:"ratioMetric,omitempty" yaml:"ratioMetric,omitempty"`
+	}
+
+	// RatioMetric is the ratio of the good, or bad, events to the total events.
+	// Raw is the precomputed ratio, its RawType is either success or failure.
+	RatioMetric struct {
+		Counter bool       `json:"counter" yaml:"counter"`
+		Good    *MetricRef `json:"good,omitempty" yaml:"good,omitempty"`
+		Bad     *MetricRef `json:"bad,omitempty" yaml:"bad,omitempty"`
+		Total   *MetricRef `json:"total,omitempty" yaml:"total,omitempty"`
+		RawType string     `json:"rawType,omitempty" yaml:"rawType,omitempty"`
+		Raw     *MetricRef `json:"raw,omitempty" yaml:"raw,omitempty"`
+	}
+
+	MetricRef struct {
+		MetricSource MetricSource `json:"metricSource" yaml:"metricSource"`
+	}
+
+	// MetricSource is the data source of the metric, i.e: prometheus
+	MetricSource struct {
+		Type string            `json:"type" yaml:"type"`
+		Spec map[string]string `json:"spec" yaml:"spec"`
+	}
+
+	// SLO is the OpenSLO SLO document
+	SLO struct {
+		APIVersion string   `json:"apiVersion" yaml:"apiVersion"`
+		Kind       string   `json:"kind" yaml:"kind"`
+		Metadata   Metadata `json:"metadata" yaml:"metadata"`
+		Spec       SLOSpec  `json:"spec" yaml:"spec"`
+	}
+
+	SLOSpec struct {
+		Description     string       `json:"description,omitempty" yaml:"description,omitempty"`
+		Service         string       `json:"service" yaml:"service"`
+		IndicatorRef    string       `json:"indicatorRef" yaml:"indicatorRef"`
+		BudgetingMethod string       `json:"budgetingMethod" yaml:"budgetingMethod"`
+		TimeWindow      []TimeWindow `json:"timeWindow" yaml:"timeWindow"`
+		Objectives      []Objective  `json:"objectives" yaml:"objectives"`
+	}
+
+	TimeWindow struct {
+		Duration  string `json:"duration" yaml:"duration"`
+		IsRolling bool   `json:"isRolling" yaml:"isRolling"`
+	}
+
+	// Objective is the target ratio of good events, i.e: 0.995
+	Objective struct {
+		DisplayName string  `json:"displayName,omitempty" yaml:"displayName,omitempty"`
+		Target      float64 `json:"target" yaml:"target"`
+	}
+)
+
+const (
+	// APIVersion is the OpenSLO specification version
+	APIVersion = "openslo/v1"
+
+	KindService = "Service"
+	KindSLI     = "SLI"
+	KindSLO     = "SLO"
+
+	// BudgetingMethodOccurrences computes the error budget from the ratio of bad events to the total events
+	BudgetingMethodOccurrences = "Occurrences"
+	// MetricSourcePrometheus is the metric source type of the prometheus queries
+	MetricSourcePrometheus = "Prometheus"
+	// RawTypeFailure is the raw type of the error ratio queries
+	RawTypeFailure = "failure"
+)
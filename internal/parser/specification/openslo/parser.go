@@ -0,0 +1,169 @@
+package openslo
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	multierr "github.com/hashicorp/go-multierror"
+	"github.com/juju/errors"
+	sloth "github.com/slok/sloth/pkg/prometheus/api/v1"
+	"github.com/slosive/sloscribe/internal/diagnostics"
+	"github.com/slosive/sloscribe/internal/generate"
+	"github.com/slosive/sloscribe/internal/logging"
+	"github.com/slosive/sloscribe/internal/parser/options"
+	"github.com/slosive/sloscribe/internal/parser/specification"
+	slothparser "github.com/slosive/sloscribe/internal/parser/specification/sloth"
+)
+
+const (
+	// DefaultTimeWindow is the rolling time window of the SLOs, it is the same as the sloth default SLO period
+	DefaultTimeWindow = "30d"
+	// DefaultQueryWindow replaces the {{.window}} template in the sloth queries, OpenSLO queries aren't templated
+	DefaultQueryWindow = "5m"
+)
+
+// windowTemplate matches the sloth query window template, i.e: {{.window}}
+var windowTemplate = regexp.MustCompile(`{{\s*\.window\s*}}`)
+
+// parser converts the sloth specifications, returned by the sloth parser, to OpenSLO documents
+type parser struct {
+	slothParser specification.Target
+	timeWindow  string
+	logger      *logging.Logger
+	// strict tells the parser to return the problems found while converting the specifications as an error
+	strict bool
+	// problems aggregates the warnings logged while converting the specifications
+	problems error
+}
+
+// Parser returns the options.Option to run the parser targeting OpenSLO as a specification.
+// The timeWindow is the rolling time window of the SLOs, i.e: 4w, DefaultTimeWindow is used if empty.
+func Parser(timeWindow string) options.Option {
+	return func(opts *options.Options) {
+		slothparser.Parser(false)(opts)
+		if timeWindow == "" {
+			timeWindow = DefaultTimeWindow
+		}
+		opts.TargetSpecification = &parser{slothParser: opts.TargetSpecification, timeWindow: timeWindow, logger: opts.Logger, strict: opts.Strict}
+	}
+}
+
+// Parse returns the OpenSLO documents, as generate.Documents, of each service found by the sloth parser.
+// The SLOs that can't be converted are skipped.
+func (p *parser) Parse(ctx context.Context) (map[string]any, error) {
+	specs, err := p.slothParser.Parse(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(map[string]any, len(specs))
+	for name, spec := range specs {
+		s, ok := spec.(*sloth.Spec)
+		if !ok {
+			continue
+		}
+		docs, err := Documents(s, p.timeWindow)
+		if err != nil {
+			p.warn(err, "service", s.Service)
+		}
+		results[name] = docs
+	}
+	if p.strict {
+		if err := diagnostics.Strict(p.problems); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// Documents returns the OpenSLO Service document of the sloth service, followed by the SLI and SLO documents of each SLO.
+// The SLOs that can't be converted are skipped, their errors are returned aggregated in a multierror.
+func Documents(spec *sloth.Spec, timeWindow string) (generate.Documents, error) {
+	var problems error
+	docs := generate.Documents{
+		&Service{
+			APIVersion: APIVersion,
+			Kind:       KindService,
+			Metadata: Metadata{
+				Name:   spec.Service,
+				Labels: spec.Labels,
+			},
+		},
+	}
+
+	for _, slo := range spec.SLOs {
+		metric, err := ratioMetric(slo.SLI)
+		if err != nil {
+			problems = multierr.Append(problems, errors.Annotatef(err, "SLO %q can't be converted to an OpenSLO SLI", slo.Name))
+			continue
+		}
+		name := fmt.Sprintf("%s-%s", spec.Service, slo.Name)
+		metadata := Metadata{
+			Name:        name,
+			DisplayName: slo.Name,
+			Labels:      slo.Labels,
+		}
+
+		docs = append(docs, &SLI{
+			APIVersion: APIVersion,
+			Kind:       KindSLI,
+			Metadata:   metadata,
+			Spec: SLISpec{
+				RatioMetric: metric,
+			},
+		}, &SLO{
+			APIVersion: APIVersion,
+			Kind:       KindSLO,
+			Metadata:   metadata,
+			Spec: SLOSpec{
+				Description:     slo.Description,
+				Service:         spec.Service,
+				IndicatorRef:    name,
+				BudgetingMethod: BudgetingMethodOccurrences,
+				TimeWindow:      []TimeWindow{{Duration: timeWindow, IsRolling: true}},
+				Objectives:      []Objective{{Target: slo.Objective / 100}},
+			},
+		})
+	}
+	return docs, problems
+}
+
+// ratioMetric returns the OpenSLO ratio metric of the sloth SLI, events SLIs are mapped to the bad and total metrics,
+// raw SLIs to the failure raw metric. It returns an error if the SLI has no queries, i.e: plugin SLIs.
+func ratioMetric(sli sloth.SLI) (*RatioMetric, error) {
+	switch {
+	case sli.Events != nil:
+		return &RatioMetric{
+			Bad:   prometheusMetric(sli.Events.ErrorQuery),
+			Total: prometheusMetric(sli.Events.TotalQuery),
+		}, nil
+	case sli.Raw != nil:
+		return &RatioMetric{
+			RawType: RawTypeFailure,
+			Raw:     prometheusMetric(sli.Raw.ErrorRatioQuery),
+		}, nil
+	case sli.Plugin != nil:
+		return nil, errors.Errorf("the plugin %q SLI doesn't have queries, only error_query and total_query or error_ratio_query SLIs are supported", sli.Plugin.ID)
+	}
+	return nil, errors.New("the SLI doesn't have queries, only error_query and total_query or error_ratio_query SLIs are supported")
+}
+
+// prometheusMetric returns the prometheus metric source of the sloth query, the {{.window}} template is replaced by DefaultQueryWindow
+func prometheusMetric(query string) *MetricRef {
+	return &MetricRef{
+		MetricSource: MetricSource{
+			Type: MetricSourcePrometheus,
+			Spec: map[string]string{
+				"query": windowTemplate.ReplaceAllString(query, DefaultQueryWindow),
+			},
+		},
+	}
+}
+
+func (p *parser) warn(err error, keyValues ...interface{}) {
+	p.problems = multierr.Append(p.problems, err)
+	if p.logger != nil {
+		p.logger.Warn(err, keyValues...)
+	}
+}
@@ -0,0 +1,189 @@
+package openslo
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	sloth "github.com/slok/sloth/pkg/prometheus/api/v1"
+	"github.com/slosive/sloscribe/internal/diagnostics"
+	"github.com/slosive/sloscribe/internal/generate"
+	"github.com/slosive/sloscribe/internal/logging"
+	sloscribe "github.com/slosive/sloscribe/internal/parser"
+	"github.com/slosive/sloscribe/internal/parser/lang"
+	"github.com/slosive/sloscribe/internal/parser/options"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocuments(t *testing.T) {
+	t.Parallel()
+
+	spec := &sloth.Spec{
+		Version: sloth.Version,
+		Service: "chatgpt",
+		Labels:  map[string]string{"team": "platform"},
+		SLOs: []sloth.SLO{
+			{
+				Name:        "availability",
+				Description: "95% of logins should be successful.",
+				Objective:   95,
+				SLI: sloth.SLI{Events: &sloth.SLIEvents{
+					ErrorQuery: `sum(rate(tenant_failed_login_operations_total{client="chat-gpt"}[{{.window}}]))`,
+					TotalQuery: `sum(rate(tenant_login_operations_total{client="chat-gpt"}[{{ .window }}]))`,
+				}},
+			},
+			{
+				Name:      "latency",
+				Objective: 99.5,
+				SLI: sloth.SLI{Raw: &sloth.SLIRaw{
+					ErrorRatioQuery: `sum(rate(tenant_slow_requests_total[{{.window}}]))`,
+				}},
+			},
+		},
+	}
+
+	t.Run("Successfully convert the sloth service to OpenSLO documents", func(t *testing.T) {
+		docs, err := Documents(spec, DefaultTimeWindow)
+		require.NoError(t, err)
+		require.Len(t, docs, 5)
+
+		service, ok := docs[0].(*Service)
+		require.True(t, ok)
+		assert.Equal(t, APIVersion, service.APIVersion)
+		assert.Equal(t, KindService, service.Kind)
+		assert.Equal(t, "chatgpt", service.Metadata.Name)
+		assert.Equal(t, map[string]string{"team": "platform"}, service.Metadata.Labels)
+
+		sli, ok := docs[1].(*SLI)
+		require.True(t, ok)
+		assert.Equal(t, "chatgpt-availability", sli.Metadata.Name)
+		require.NotNil(t, sli.Spec.RatioMetric)
+		assert.Equal(t, MetricSourcePrometheus, sli.Spec.RatioMetric.Bad.MetricSource.Type)
+		assert.Equal(t, `sum(rate(tenant_failed_login_operations_total{client="chat-gpt"}[5m]))`, sli.Spec.RatioMetric.Bad.MetricSource.Spec["query"])
+		assert.Equal(t, `sum(rate(tenant_login_operations_total{client="chat-gpt"}[5m]))`, sli.Spec.RatioMetric.Total.MetricSource.Spec["query"])
+
+		slo, ok := docs[2].(*SLO)
+		require.True(t, ok)
+		assert.Equal(t, "chatgpt-availability", slo.Metadata.Name)
+		assert.Equal(t, "availability", slo.Metadata.DisplayName)
+		assert.Equal(t, "chatgpt", slo.Spec.Service)
+		assert.Equal(t, "chatgpt-availability", slo.Spec.IndicatorRef)
+		assert.Equal(t, BudgetingMethodOccurrences, slo.Spec.BudgetingMethod)
+		assert.Equal(t, []TimeWindow{{Duration: DefaultTimeWindow, IsRolling: true}}, slo.Spec.TimeWindow)
+		assert.Equal(t, []Objective{{Target: 0.95}}, slo.Spec.Objectives)
+
+		raw, ok := docs[3].(*SLI)
+		require.True(t, ok)
+		assert.Equal(t, RawTypeFailure, raw.Spec.RatioMetric.RawType)
+		assert.Equal(t, `sum(rate(tenant_slow_requests_total[5m]))`, raw.Spec.RatioMetric.Raw.MetricSource.Spec["query"])
+		assert.Nil(t, raw.Spec.RatioMetric.Bad)
+	})
+
+	t.Run("Successfully skip the plugin SLIs, which don't have queries", func(t *testing.T) {
+		withPlugin := *spec
+		withPlugin.SLOs = append([]sloth.SLO{{
+			Name:      "apiserver",
+			Objective: 99,
+			SLI:       sloth.SLI{Plugin: &sloth.SLIPlugin{ID: "sloth-common/kubernetes/apiserver/availability"}},
+		}}, spec.SLOs...)
+
+		docs, err := Documents(&withPlugin, DefaultTimeWindow)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `SLO "apiserver" can't be converted to an OpenSLO SLI: the plugin "sloth-common/kubernetes/apiserver/availability" SLI doesn't have queries`)
+		require.Len(t, docs, 5)
+		for _, doc := range docs[1:] {
+			if sli, ok := doc.(*SLI); ok {
+				assert.NotNil(t, sli.Spec.RatioMetric)
+				assert.NotEqual(t, "chatgpt-apiserver", sli.Metadata.Name)
+			}
+		}
+	})
+
+	t.Run("Successfully write the OpenSLO documents as yaml", func(t *testing.T) {
+		docs, err := Documents(spec, DefaultTimeWindow)
+		require.NoError(t, err)
+		var w bytes.Buffer
+		require.NoError(t, generate.WriteSpecifications(&w, nil, map[string]any{"chatgpt": docs}, false, "", "yaml"))
+		assert.Equal(t, 5, strings.Count(w.String(), "apiVersion: openslo/v1"))
+		assert.Contains(t, w.String(), `
+kind: SLI
+metadata:
+    name: chatgpt-availability
+    displayName: availability
+spec:
+    ratioMetric:
+        counter: false
+        bad:
+            metricSource:
+                type: Prometheus
+                spec:
+                    query: sum(rate(tenant_failed_login_operations_total{client="chat-gpt"}[5m]))
+`)
+	})
+
+	t.Run("Successfully write the OpenSLO documents as json", func(t *testing.T) {
+		docs, err := Documents(spec, DefaultTimeWindow)
+		require.NoError(t, err)
+		var w bytes.Buffer
+		require.NoError(t, generate.WriteSpecifications(&w, nil, map[string]any{"chatgpt": docs}, false, "", "json"))
+		assert.True(t, strings.HasPrefix(w.String(), "\n[{\"apiVersion\":\"openslo/v1\",\"kind\":\"Service\""))
+	})
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Successfully parse the sloth annotations as OpenSLO documents", func(t *testing.T) {
+		logger := logging.NewStandardLogger()
+		p, err := sloscribe.New(
+			options.Language(lang.Go),
+			Parser(""),
+			options.Logger(&logger),
+			options.SourceContent(io.NopCloser(strings.NewReader(`package main
+// @sloth service chatgpt
+// @sloth.slo name availability
+// @sloth.slo objective 95.0
+// @sloth.sli error_query sum(rate(tenant_failed_login_operations_total[{{.window}}]))
+// @sloth.sli total_query sum(rate(tenant_login_operations_total[{{.window}}]))
+func main() {}
+`))),
+		)
+		require.NoError(t, err)
+
+		specs, err := p.Parse(context.Background())
+		require.NoError(t, err)
+		docs, ok := specs["chatgpt"].(generate.Documents)
+		require.True(t, ok)
+		require.Len(t, docs, 3)
+		assert.IsType(t, &Service{}, docs[0])
+		assert.IsType(t, &SLI{}, docs[1])
+		assert.IsType(t, &SLO{}, docs[2])
+	})
+	t.Run("Fail to parse the plugin SLIs in strict mode", func(t *testing.T) {
+		logger := logging.NewStandardLogger()
+		p, err := sloscribe.New(
+			options.Language(lang.Go),
+			Parser(""),
+			options.Logger(&logger),
+			options.Strict(true),
+			options.SourceContent(io.NopCloser(strings.NewReader(`package main
+// @sloth service kubernetes
+// @sloth.slo name apiserver
+// @sloth.slo objective 99.0
+// @sloth.sli plugin sloth-common/kubernetes/apiserver/availability
+func main() {}
+`))),
+		)
+		require.NoError(t, err)
+
+		specs, err := p.Parse(context.Background())
+		assert.Nil(t, specs)
+
+		var strictErr *diagnostics.StrictError
+		require.ErrorAs(t, err, &strictErr)
+		assert.Contains(t, strictErr.Problems.Error(), `SLO "apiserver" can't be converted to an OpenSLO SLI`)
+	})
+}
@@ -0,0 +1,8 @@
+// Package prometheus contains the parser targeting the Prometheus rules as a specification.
+// The sloth annotations are parsed by the sloth parser and each SLO is converted to the SLI recording rules,
+// the SLO metadata recording rules and the multi-window multi-burn-rate alert rules, the same way sloth generate does.
+//
+// The sloth rules generation lives in its internal packages (internal/prometheus and internal/alert), which can't be imported,
+// so it is ported here for the 28d and 30d SLO periods. The testdata golden files are the output of sloth generate,
+// they must be regenerated with the new sloth version whenever the sloth dependency is upgraded.
+package prometheus
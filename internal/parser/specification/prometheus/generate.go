@@ -0,0 +1,322 @@
+package prometheus
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/juju/errors"
+	sloth "github.com/slok/sloth/pkg/prometheus/api/v1"
+)
+
+// The rules are generated the same way as sloth v0.11.0 does, so that the sloth dashboards work with the generated rules.
+const (
+	slothVersion = "v0.11.0"
+
+	sliErrorMetricFmt = "slo:sli_error:ratio_rate%s"
+
+	metricSLOObjectiveRatio                  = "slo:objective:ratio"
+	metricSLOErrorBudgetRatio                = "slo:error_budget:ratio"
+	metricSLOTimePeriodDays                  = "slo:time_period:days"
+	metricSLOCurrentBurnRateRatio            = "slo:current_burn_rate:ratio"
+	metricSLOPeriodBurnRateRatio             = "slo:period_burn_rate:ratio"
+	metricSLOPeriodErrorBudgetRemainingRatio = "slo:period_error_budget_remaining:ratio"
+	metricSLOInfo                            = "sloth_slo_info"
+
+	sloNameLabelName      = "sloth_slo"
+	sloIDLabelName        = "sloth_id"
+	sloServiceLabelName   = "sloth_service"
+	sloWindowLabelName    = "sloth_window"
+	sloSeverityLabelName  = "sloth_severity"
+	sloVersionLabelName   = "sloth_version"
+	sloModeLabelName      = "sloth_mode"
+	sloSpecLabelName      = "sloth_spec"
+	sloObjectiveLabelName = "sloth_objective"
+)
+
+// ErrNoSLORules is returned when no rules were generated for the service, i.e: the service has no SLOs
+var ErrNoSLORules = errors.New("0 SLO Prometheus rules generated")
+
+var (
+	eventsSLIExprTpl = template.Must(template.New("eventsSLIExpr").Parse(`({{ .ErrorQuery }})
+/
+({{ .TotalQuery }})
+`))
+
+	optimizedSLIExprTpl = template.Must(template.New("optimizedSLIExpr").Parse(`sum_over_time({{ .Metric }}{{ .Filter }}[{{ .Window }}])
+/ ignoring ({{ .WindowLabel }})
+count_over_time({{ .Metric }}{{ .Filter }}[{{ .Window }}])
+`))
+
+	burnRateExprTpl = template.Must(template.New("burnRateExpr").Parse(`{{ .SLIErrorMetric }}{{ .Filter }}
+/ on({{ .SLOIDName }}, {{ .SLOLabelName }}, {{ .SLOServiceName }}) group_left
+{{ .ErrorBudgetRatioMetric }}{{ .Filter }}
+`))
+
+	// mwmbAlertTpl is the multi-window multi-burn-rate alert expression
+	mwmbAlertTpl = template.Must(template.New("mwmbAlert").Parse(`(
+    max({{ .QuickShortMetric }}{{ .Filter }} > ({{ .QuickBurnFactor }} * {{ .ErrorBudgetRatio }})) without ({{ .WindowLabel }})
+    and
+    max({{ .QuickLongMetric }}{{ .Filter }} > ({{ .QuickBurnFactor }} * {{ .ErrorBudgetRatio }})) without ({{ .WindowLabel }})
+)
+or
+(
+    max({{ .SlowShortMetric }}{{ .Filter }} > ({{ .SlowBurnFactor }} * {{ .ErrorBudgetRatio }})) without ({{ .WindowLabel }})
+    and
+    max({{ .SlowLongMetric }}{{ .Filter }} > ({{ .SlowBurnFactor }} * {{ .ErrorBudgetRatio }})) without ({{ .WindowLabel }})
+)
+`))
+)
+
+// generator generates the Prometheus rules of the SLOs for an SLO period
+type generator struct {
+	period  time.Duration
+	windows alertWindows
+	// mode and spec are the sloth_mode and sloth_spec labels of the sloth_slo_info metric
+	mode string
+	spec string
+}
+
+// newGenerator returns the generator of the SLOs rules for the SLO period, i.e: 30d
+func newGenerator(period string, kubernetes bool) (*generator, error) {
+	d, err := parseDuration(period)
+	if err != nil {
+		return nil, err
+	}
+	windows, ok := getAlertWindows(d)
+	if !ok {
+		// the period is quoted as written, i.e: 7d isn't reformatted as 1w
+		return nil, errors.Errorf("the %q SLO period is not supported, supported periods are 28d and 30d", period)
+	}
+	g := &generator{period: d, windows: windows, mode: "cli-gen-prom", spec: sloth.Version}
+	if kubernetes {
+		g.mode = "cli-gen-k8s"
+		g.spec = "sloth.slok.dev/v1"
+	}
+	return g, nil
+}
+
+// Generate returns the SLI recording rules, the metadata recording rules and the alert rules groups of each SLO of the service
+func (g *generator) Generate(spec *sloth.Spec) ([]RuleGroup, error) {
+	var groups []RuleGroup
+	for _, slo := range spec.SLOs {
+		id := fmt.Sprintf("%s-%s", spec.Service, slo.Name)
+		ids := map[string]string{
+			sloIDLabelName:      id,
+			sloNameLabelName:    slo.Name,
+			sloServiceLabelName: spec.Service,
+		}
+		labels := mergeLabels(spec.Labels, slo.Labels)
+
+		sliRules, err := g.sliRecordingRules(slo, ids, labels)
+		if err != nil {
+			return nil, errors.Annotatef(err, "could not create %q SLO SLI recording rules", id)
+		}
+		metadataRules, err := g.metadataRecordingRules(slo, ids, labels)
+		if err != nil {
+			return nil, errors.Annotatef(err, "could not create %q SLO metadata recording rules", id)
+		}
+		alertRules, err := g.alertRules(slo, ids)
+		if err != nil {
+			return nil, errors.Annotatef(err, "could not create %q SLO alert rules", id)
+		}
+
+		groups = append(groups,
+			RuleGroup{Name: "sloth-slo-sli-recordings-" + id, Rules: sliRules},
+			RuleGroup{Name: "sloth-slo-meta-recordings-" + id, Rules: metadataRules},
+		)
+		if len(alertRules) > 0 {
+			groups = append(groups, RuleGroup{Name: "sloth-slo-alerts-" + id, Rules: alertRules})
+		}
+	}
+	return groups, nil
+}
+
+// sliRecordingRules returns the SLI error ratio recording rules for each alert window and the SLO period.
+// The SLO period rule is computed from the shortest window rule, to reduce the load on Prometheus.
+func (g *generator) sliRecordingRules(slo sloth.SLO, ids, labels map[string]string) ([]Rule, error) {
+	var rules []Rule
+	for _, window := range g.windows.windows() {
+		expr, err := sliExpr(slo.SLI, window)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, Rule{
+			Record: sliErrorMetric(window),
+			Expr:   expr,
+			Labels: mergeLabels(ids, map[string]string{sloWindowLabelName: formatDuration(window)}, labels),
+		})
+	}
+
+	expr, err := render(optimizedSLIExprTpl, map[string]string{
+		"Metric":      sliErrorMetric(g.windows.pageQuick.shortWindow),
+		"Filter":      promFilter(ids),
+		"Window":      formatDuration(g.period),
+		"WindowLabel": sloWindowLabelName,
+	})
+	if err != nil {
+		return nil, err
+	}
+	rules = append(rules, Rule{
+		Record: sliErrorMetric(g.period),
+		Expr:   expr,
+		Labels: mergeLabels(ids, map[string]string{sloWindowLabelName: formatDuration(g.period)}, labels),
+	})
+	return rules, nil
+}
+
+// sliExpr returns the SLI error ratio expression for the window, the {{.window}} template in the queries is replaced by the window
+func sliExpr(sli sloth.SLI, window time.Duration) (string, error) {
+	var expr string
+	switch {
+	case sli.Events != nil:
+		e, err := render(eventsSLIExprTpl, sli.Events)
+		if err != nil {
+			return "", err
+		}
+		expr = e
+	case sli.Raw != nil:
+		expr = fmt.Sprintf("(%s)", sli.Raw.ErrorRatioQuery)
+	case sli.Plugin != nil:
+		return "", errors.Errorf("SLI plugin %q can't be used to generate the rules, only error_query, total_query and error_ratio_query SLIs are supported", sli.Plugin.ID)
+	default:
+		return "", errors.New("invalid SLI type, the SLI has no queries")
+	}
+
+	tpl, err := template.New("sliExpr").Option("missingkey=error").Parse(expr)
+	if err != nil {
+		return "", errors.Annotate(err, "could not create SLI expression template")
+	}
+	return render(tpl, map[string]string{"window": formatDuration(window)})
+}
+
+// metadataRecordingRules returns the SLO objective, error budget and burn rates recording rules
+func (g *generator) metadataRecordingRules(slo sloth.SLO, ids, labels map[string]string) ([]Rule, error) {
+	labels = mergeLabels(ids, labels)
+	filter := promFilter(ids)
+	objectiveRatio := slo.Objective / 100
+
+	currentBurnRate, err := render(burnRateExprTpl, burnRateData(sliErrorMetric(g.windows.pageQuick.shortWindow), filter))
+	if err != nil {
+		return nil, err
+	}
+	periodBurnRate, err := render(burnRateExprTpl, burnRateData(sliErrorMetric(g.period), filter))
+	if err != nil {
+		return nil, err
+	}
+
+	return []Rule{
+		{Record: metricSLOObjectiveRatio, Expr: fmt.Sprintf(`vector(%g)`, objectiveRatio), Labels: labels},
+		{Record: metricSLOErrorBudgetRatio, Expr: fmt.Sprintf(`vector(1-%g)`, objectiveRatio), Labels: labels},
+		{Record: metricSLOTimePeriodDays, Expr: fmt.Sprintf(`vector(%g)`, g.period.Hours()/24), Labels: labels},
+		{Record: metricSLOCurrentBurnRateRatio, Expr: currentBurnRate, Labels: labels},
+		{Record: metricSLOPeriodBurnRateRatio, Expr: periodBurnRate, Labels: labels},
+		{Record: metricSLOPeriodErrorBudgetRemainingRatio, Expr: fmt.Sprintf(`1 - %s%s`, metricSLOPeriodBurnRateRatio, filter), Labels: labels},
+		{Record: metricSLOInfo, Expr: `vector(1)`, Labels: mergeLabels(labels, map[string]string{
+			sloVersionLabelName:   slothVersion,
+			sloModeLabelName:      g.mode,
+			sloSpecLabelName:      g.spec,
+			sloObjectiveLabelName: strconv.FormatFloat(slo.Objective, 'f', -1, 64),
+		})},
+	}, nil
+}
+
+func burnRateData(sliErrorMetric, filter string) map[string]string {
+	return map[string]string{
+		"SLIErrorMetric":         sliErrorMetric,
+		"Filter":                 filter,
+		"SLOIDName":              sloIDLabelName,
+		"SLOLabelName":           sloNameLabelName,
+		"SLOServiceName":         sloServiceLabelName,
+		"ErrorBudgetRatioMetric": metricSLOErrorBudgetRatio,
+	}
+}
+
+// alertRules returns the page and ticket multi-window multi-burn-rate alert rules, unless they are disabled
+func (g *generator) alertRules(slo sloth.SLO, ids map[string]string) ([]Rule, error) {
+	var rules []Rule
+	alerts := []struct {
+		severity    string
+		alert       sloth.Alert
+		quick, slow alertWindow
+	}{
+		{"page", slo.Alerting.PageAlert, g.windows.pageQuick, g.windows.pageSlow},
+		{"ticket", slo.Alerting.TicketAlert, g.windows.ticketQuick, g.windows.ticketSlow},
+	}
+	for _, a := range alerts {
+		if a.alert.Disable {
+			continue
+		}
+		if slo.Alerting.Name == "" {
+			return nil, errors.Errorf("the %s alert name is required, set it with @sloth.alerting name", a.severity)
+		}
+
+		expr, err := render(mwmbAlertTpl, map[string]any{
+			"Filter":           promFilter(ids),
+			"ErrorBudgetRatio": (100 - slo.Objective) / 100,
+			"QuickShortMetric": sliErrorMetric(a.quick.shortWindow),
+			"QuickLongMetric":  sliErrorMetric(a.quick.longWindow),
+			"QuickBurnFactor":  a.quick.burnRateFactor(g.period),
+			"SlowShortMetric":  sliErrorMetric(a.slow.shortWindow),
+			"SlowLongMetric":   sliErrorMetric(a.slow.longWindow),
+			"SlowBurnFactor":   a.slow.burnRateFactor(g.period),
+			"WindowLabel":      sloWindowLabelName,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		rules = append(rules, Rule{
+			Alert: slo.Alerting.Name,
+			Expr:  expr,
+			// the SLO labels aren't added, the alerts inherit them from the recording rules
+			Labels: mergeLabels(map[string]string{sloSeverityLabelName: a.severity}, slo.Alerting.Labels, a.alert.Labels),
+			Annotations: mergeLabels(map[string]string{
+				"title":   fmt.Sprintf("(%s) {{$labels.%s}} {{$labels.%s}} SLO error budget burn rate is too fast.", a.severity, sloServiceLabelName, sloNameLabelName),
+				"summary": fmt.Sprintf("{{$labels.%s}} {{$labels.%s}} SLO error budget burn rate is over expected.", sloServiceLabelName, sloNameLabelName),
+			}, slo.Alerting.Annotations, a.alert.Annotations),
+		})
+	}
+	return rules, nil
+}
+
+func sliErrorMetric(window time.Duration) string {
+	return fmt.Sprintf(sliErrorMetricFmt, formatDuration(window))
+}
+
+// promFilter returns the prometheus label matchers of the labels, sorted by name, i.e: {sloth_id="foo", sloth_slo="bar"}
+func promFilter(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	matchers := make([]string, 0, len(names))
+	for _, name := range names {
+		matchers = append(matchers, fmt.Sprintf("%s=%q", name, labels[name]))
+	}
+	return "{" + strings.Join(matchers, ", ") + "}"
+}
+
+func mergeLabels(ms ...map[string]string) map[string]string {
+	res := map[string]string{}
+	for _, m := range ms {
+		for k, v := range m {
+			res[k] = v
+		}
+	}
+	return res
+}
+
+func render(tpl *template.Template, data any) (string, error) {
+	var b bytes.Buffer
+	if err := tpl.Execute(&b, data); err != nil {
+		return "", errors.Annotate(err, "could not render the rule expression")
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,117 @@
+package prometheus
+
+import (
+	"context"
+
+	multierr "github.com/hashicorp/go-multierror"
+	sloth "github.com/slok/sloth/pkg/prometheus/api/v1"
+	"github.com/slosive/sloscribe/internal/diagnostics"
+	"github.com/slosive/sloscribe/internal/logging"
+	"github.com/slosive/sloscribe/internal/parser/options"
+	"github.com/slosive/sloscribe/internal/parser/specification"
+	slothparser "github.com/slosive/sloscribe/internal/parser/specification/sloth"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefaultPeriod is the SLO period used to generate the rules, it is the same as the sloth default SLO period
+const DefaultPeriod = "30d"
+
+// parser generates the Prometheus rules of the sloth specifications returned by the sloth parser
+type parser struct {
+	slothParser specification.Target
+	period      string
+	kubernetes  bool
+	logger      *logging.Logger
+	// strict tells the parser to return the problems found while converting the specifications as an error
+	strict bool
+	// problems aggregates the warnings logged while converting the specifications
+	problems error
+}
+
+// ValidatePeriod returns an error if the rules can't be generated for the SLO period, i.e: 7d
+func ValidatePeriod(period string) error {
+	_, err := newGenerator(period, false)
+	return err
+}
+
+// Parser returns the options.Option to run the parser targeting the Prometheus rules as a specification.
+// The period is the SLO period, i.e: 30d, DefaultPeriod is used if empty.
+// If kubernetes is true, the rules of each service are returned in a prometheus-operator PrometheusRule.
+func Parser(kubernetes bool, period string) options.Option {
+	return func(opts *options.Options) {
+		slothparser.Parser(false)(opts)
+		if period == "" {
+			period = DefaultPeriod
+		}
+		opts.TargetSpecification = &parser{
+			slothParser: opts.TargetSpecification,
+			period:      period,
+			kubernetes:  kubernetes,
+			logger:      opts.Logger,
+			strict:      opts.Strict,
+		}
+	}
+}
+
+// Parse returns the Prometheus rules, as RuleGroups or PrometheusRule, of each service found by the sloth parser.
+// The services whose rules can't be generated are skipped.
+func (p *parser) Parse(ctx context.Context) (map[string]any, error) {
+	g, err := newGenerator(p.period, p.kubernetes)
+	if err != nil {
+		return nil, err
+	}
+
+	specs, err := p.slothParser.Parse(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(map[string]any, len(specs))
+	for name, spec := range specs {
+		s, ok := spec.(*sloth.Spec)
+		if !ok {
+			continue
+		}
+		groups, err := g.Generate(s)
+		if err != nil {
+			p.warn(err, "service", s.Service)
+			continue
+		}
+		if len(groups) == 0 {
+			p.warn(ErrNoSLORules, "service", s.Service)
+			continue
+		}
+
+		if !p.kubernetes {
+			results[name] = &RuleGroups{Groups: groups}
+			continue
+		}
+		results[name] = &PrometheusRule{
+			TypeMeta: v1.TypeMeta{
+				APIVersion: PrometheusRuleAPIVersion,
+				Kind:       PrometheusRuleKind,
+			},
+			ObjectMeta: v1.ObjectMeta{
+				Name: s.Service,
+				Labels: map[string]string{
+					"app.kubernetes.io/component":  "SLO",
+					"app.kubernetes.io/managed-by": "sloscribe",
+				},
+			},
+			Spec: RuleGroups{Groups: groups},
+		}
+	}
+	if p.strict {
+		if err := diagnostics.Strict(p.problems); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+func (p *parser) warn(err error, keyValues ...interface{}) {
+	p.problems = multierr.Append(p.problems, err)
+	if p.logger != nil {
+		p.logger.Warn(err, keyValues...)
+	}
+}
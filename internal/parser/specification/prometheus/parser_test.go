@@ -0,0 +1,225 @@
+package prometheus
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	sloth "github.com/slok/sloth/pkg/prometheus/api/v1"
+	"github.com/slosive/sloscribe/internal/generate"
+	"github.com/slosive/sloscribe/internal/logging"
+	sloscribe "github.com/slosive/sloscribe/internal/parser"
+	"github.com/slosive/sloscribe/internal/parser/lang"
+	"github.com/slosive/sloscribe/internal/parser/options"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	// The golden files are the output of sloth v0.11.0 for the specs, i.e:
+	// sloth generate -i testdata/bar.slo.yaml -o testdata/bar.yaml --default-slo-period=28d
+	// the kubernetes golden file is generated from the same spec as a PrometheusServiceLevel, testdata/baz.sloth-k8s.yaml.
+	goldens := []struct {
+		spec, golden, period string
+		kubernetes           bool
+	}{
+		{spec: "foo.slo.yaml", golden: "foo.yaml", period: "30d"},
+		{spec: "bar.slo.yaml", golden: "bar.yaml", period: "28d"},
+		{spec: "baz.slo.yaml", golden: "baz.yaml", period: "30d", kubernetes: true},
+	}
+	for _, tc := range goldens {
+		tc := tc
+		t.Run("Successfully generate the same rules as sloth generate for "+tc.spec, func(t *testing.T) {
+			body, err := os.ReadFile("./testdata/" + tc.spec)
+			require.NoError(t, err)
+			var spec sloth.Spec
+			require.NoError(t, yaml.Unmarshal(body, &spec))
+
+			body, err = os.ReadFile("./testdata/" + tc.golden)
+			require.NoError(t, err)
+			var expected struct {
+				RuleGroups `yaml:",inline"`
+				Spec       RuleGroups `yaml:"spec"`
+			}
+			require.NoError(t, yaml.Unmarshal(body, &expected))
+			if tc.kubernetes {
+				expected.RuleGroups = expected.Spec
+			}
+			require.NotEmpty(t, expected.Groups)
+
+			g, err := newGenerator(tc.period, tc.kubernetes)
+			require.NoError(t, err)
+			groups, err := g.Generate(&spec)
+			require.NoError(t, err)
+			assert.Equal(t, expected.Groups, groups)
+		})
+	}
+
+	t.Run("Successfully generate the rules of a raw SLI for a 4 weeks period", func(t *testing.T) {
+		g, err := newGenerator("4w", false)
+		require.NoError(t, err)
+		groups, err := g.Generate(&sloth.Spec{
+			Service: "foo",
+			SLOs: []sloth.SLO{{
+				Name:      "latency",
+				Objective: 99,
+				SLI:       sloth.SLI{Raw: &sloth.SLIRaw{ErrorRatioQuery: `sum(rate(slow_requests_total[{{.window}}]))`}},
+				Alerting:  sloth.Alerting{PageAlert: sloth.Alert{Disable: true}, TicketAlert: sloth.Alert{Disable: true}},
+			}},
+		})
+		require.NoError(t, err)
+		require.Len(t, groups, 2)
+		assert.Equal(t, "sloth-slo-sli-recordings-foo-latency", groups[0].Name)
+		assert.Equal(t, "(sum(rate(slow_requests_total[5m])))", groups[0].Rules[0].Expr)
+		assert.Equal(t, "slo:sli_error:ratio_rate4w", groups[0].Rules[len(groups[0].Rules)-1].Record)
+		assert.Equal(t, "vector(28)", groups[1].Rules[2].Expr)
+	})
+
+	t.Run("Fail to generate the alert rules without an alert name", func(t *testing.T) {
+		g, err := newGenerator(DefaultPeriod, false)
+		require.NoError(t, err)
+		_, err = g.Generate(&sloth.Spec{
+			Service: "foo",
+			SLOs: []sloth.SLO{{
+				Name:      "latency",
+				Objective: 99,
+				SLI:       sloth.SLI{Raw: &sloth.SLIRaw{ErrorRatioQuery: `sum(rate(slow_requests_total[{{.window}}]))`}},
+			}},
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("Fail to generate the rules of an unknown query template variable", func(t *testing.T) {
+		g, err := newGenerator(DefaultPeriod, false)
+		require.NoError(t, err)
+		_, err = g.Generate(&sloth.Spec{
+			Service: "foo",
+			SLOs: []sloth.SLO{{
+				Name:     "latency",
+				SLI:      sloth.SLI{Raw: &sloth.SLIRaw{ErrorRatioQuery: `sum(rate(slow_requests_total[{{.interval}}]))`}},
+				Alerting: sloth.Alerting{PageAlert: sloth.Alert{Disable: true}, TicketAlert: sloth.Alert{Disable: true}},
+			}},
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("Fail to generate the rules for an unsupported period", func(t *testing.T) {
+		_, err := newGenerator("7d", false)
+		require.Error(t, err)
+		assert.Equal(t, `the "7d" SLO period is not supported, supported periods are 28d and 30d`, err.Error())
+		assert.Error(t, ValidatePeriod("7d"))
+		assert.NoError(t, ValidatePeriod("4w"))
+	})
+}
+
+func TestDuration(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Successfully format the prometheus durations", func(t *testing.T) {
+		assert.Equal(t, "5m", formatDuration(5*time.Minute))
+		assert.Equal(t, "1d", formatDuration(24*time.Hour))
+		assert.Equal(t, "30d", formatDuration(30*24*time.Hour))
+		assert.Equal(t, "4w", formatDuration(28*24*time.Hour))
+		assert.Equal(t, "1h30m", formatDuration(90*time.Minute))
+	})
+
+	t.Run("Successfully parse the prometheus durations", func(t *testing.T) {
+		d, err := parseDuration("4w")
+		require.NoError(t, err)
+		assert.Equal(t, 28*24*time.Hour, d)
+
+		d, err = parseDuration("1h30m")
+		require.NoError(t, err)
+		assert.Equal(t, 90*time.Minute, d)
+
+		d, err = parseDuration("500ms")
+		require.NoError(t, err)
+		assert.Equal(t, 500*time.Millisecond, d)
+	})
+
+	t.Run("Fail to parse an invalid duration", func(t *testing.T) {
+		for _, d := range []string{"", "30", "d", "30x"} {
+			_, err := parseDuration(d)
+			assert.Error(t, err, d)
+		}
+	})
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	source := `package main
+// @sloth service chatgpt
+// @sloth.slo name availability
+// @sloth.slo objective 95.0
+// @sloth.sli error_query sum(rate(tenant_failed_login_operations_total[{{.window}}]))
+// @sloth.sli total_query sum(rate(tenant_login_operations_total[{{.window}}]))
+// @sloth.alerting name ChatGPTAvailability
+func main() {}
+`
+
+	parse := func(t *testing.T, kubernetes bool) map[string]any {
+		logger := logging.NewStandardLogger()
+		p, err := sloscribe.New(
+			options.Language(lang.Go),
+			Parser(kubernetes, ""),
+			options.Logger(&logger),
+			options.SourceContent(io.NopCloser(strings.NewReader(source))),
+		)
+		require.NoError(t, err)
+		specs, err := p.Parse(context.Background())
+		require.NoError(t, err)
+		return specs
+	}
+
+	t.Run("Successfully parse the sloth annotations as a prometheus rules file", func(t *testing.T) {
+		specs := parse(t, false)
+		rules, ok := specs["chatgpt"].(*RuleGroups)
+		require.True(t, ok)
+		require.Len(t, rules.Groups, 3)
+
+		var w bytes.Buffer
+		require.NoError(t, generate.WriteSpecifications(&w, nil, specs, false, "", "yaml"))
+		assert.Contains(t, w.String(), `groups:
+    - name: sloth-slo-sli-recordings-chatgpt-availability
+      rules:
+        - record: slo:sli_error:ratio_rate5m
+          expr: |
+            (sum(rate(tenant_failed_login_operations_total[5m])))
+            /
+            (sum(rate(tenant_login_operations_total[5m])))
+`)
+	})
+
+	t.Run("Successfully parse the sloth annotations as a PrometheusRule", func(t *testing.T) {
+		specs := parse(t, true)
+		rule, ok := specs["chatgpt"].(*PrometheusRule)
+		require.True(t, ok)
+		assert.Equal(t, PrometheusRuleAPIVersion, rule.APIVersion)
+		assert.Equal(t, PrometheusRuleKind, rule.Kind)
+		assert.Equal(t, "chatgpt", rule.Name)
+		require.Len(t, rule.Spec.Groups, 3)
+
+		var w bytes.Buffer
+		require.NoError(t, generate.WriteK8Specifications(&w, nil, specs, false, "", "yaml"))
+		assert.Contains(t, w.String(), `kind: PrometheusRule
+metadata:
+  creationTimestamp: null
+  labels:
+    app.kubernetes.io/component: SLO
+    app.kubernetes.io/managed-by: sloscribe
+  name: chatgpt
+spec:
+  groups:
+  - name: sloth-slo-sli-recordings-chatgpt-availability
+`)
+		assert.Contains(t, w.String(), "sloth_mode: cli-gen-k8s")
+	})
+}
@@ -0,0 +1,40 @@
+package prometheus
+
+import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type (
+	// RuleGroups is the Prometheus rules file, i.e: the file loaded through rule_files
+	RuleGroups struct {
+		Groups []RuleGroup `json:"groups" yaml:"groups"`
+	}
+
+	RuleGroup struct {
+		Name  string `json:"name" yaml:"name"`
+		Rules []Rule `json:"rules" yaml:"rules"`
+	}
+
+	// Rule is either a recording rule or an alert rule
+	Rule struct {
+		Record      string            `json:"record,omitempty" yaml:"record,omitempty"`
+		Alert       string            `json:"alert,omitempty" yaml:"alert,omitempty"`
+		Expr        string            `json:"expr" yaml:"expr"`
+		Labels      map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+		Annotations map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+	}
+
+	// PrometheusRule is the prometheus-operator custom resource containing the Prometheus rules
+	PrometheusRule struct {
+		v1.TypeMeta   `json:",inline"`
+		v1.ObjectMeta `json:"metadata,omitempty"`
+		Spec          RuleGroups `json:"spec"`
+	}
+)
+
+const (
+	// PrometheusRuleAPIVersion is the prometheus-operator custom resources API version
+	PrometheusRuleAPIVersion = "monitoring.coreos.com/v1"
+	// PrometheusRuleKind is the kind of the prometheus-operator rules custom resource
+	PrometheusRuleKind = "PrometheusRule"
+)
@@ -0,0 +1,150 @@
+package prometheus
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+)
+
+type (
+	// alertWindow is the windows of a multi-window multi-burn-rate alert.
+	// The alert fires if both windows consume the error budget percent, in the SLO period, faster than expected.
+	alertWindow struct {
+		errorBudgetPercent float64
+		shortWindow        time.Duration
+		longWindow         time.Duration
+	}
+
+	// alertWindows are the windows of the page and ticket alerts of an SLO period
+	alertWindows struct {
+		pageQuick   alertWindow
+		pageSlow    alertWindow
+		ticketQuick alertWindow
+		ticketSlow  alertWindow
+	}
+)
+
+const day = 24 * time.Hour
+
+// googleAlertWindows are the month windows recommended by https://sre.google/workbook/alerting-on-slos/#recommended_parameters_for_an_slo_based_a.
+// These are the same windows used by sloth for the 28d and 30d SLO periods.
+var googleAlertWindows = alertWindows{
+	pageQuick:   alertWindow{errorBudgetPercent: 2, shortWindow: 5 * time.Minute, longWindow: time.Hour},
+	pageSlow:    alertWindow{errorBudgetPercent: 5, shortWindow: 30 * time.Minute, longWindow: 6 * time.Hour},
+	ticketQuick: alertWindow{errorBudgetPercent: 10, shortWindow: 2 * time.Hour, longWindow: day},
+	ticketSlow:  alertWindow{errorBudgetPercent: 10, shortWindow: 6 * time.Hour, longWindow: 3 * day},
+}
+
+// getAlertWindows returns the alert windows of the SLO period, only the 28d and 30d periods are supported
+func getAlertWindows(period time.Duration) (alertWindows, bool) {
+	switch period {
+	case 28 * day, 30 * day:
+		return googleAlertWindows, true
+	}
+	return alertWindows{}, false
+}
+
+// burnRateFactor returns the speed needed to consume the error budget percent, in the SLO period, during the alert long window
+func (w alertWindow) burnRateFactor(period time.Duration) float64 {
+	hoursRequiredConsumption := w.errorBudgetPercent * period.Hours() / 100
+	return hoursRequiredConsumption / w.longWindow.Hours()
+}
+
+// windows returns the distinct alert windows sorted from the shortest to the longest
+func (w alertWindows) windows() []time.Duration {
+	var windows []time.Duration
+	seen := map[time.Duration]bool{}
+	for _, window := range []time.Duration{
+		w.pageQuick.shortWindow, w.pageQuick.longWindow,
+		w.pageSlow.shortWindow, w.pageSlow.longWindow,
+		w.ticketQuick.shortWindow, w.ticketQuick.longWindow,
+		w.ticketSlow.shortWindow, w.ticketSlow.longWindow,
+	} {
+		if !seen[window] {
+			seen[window] = true
+			windows = append(windows, window)
+		}
+	}
+	sort.Slice(windows, func(i, j int) bool { return windows[i] < windows[j] })
+	return windows
+}
+
+// durationUnits are the prometheus duration units, from the longest to the shortest.
+// Years and weeks are only used when they divide the duration exactly, 30d reads better than 4w2d.
+var durationUnits = []struct {
+	unit     string
+	duration time.Duration
+	exact    bool
+}{
+	{"y", 365 * day, true},
+	{"w", 7 * day, true},
+	{"d", day, false},
+	{"h", time.Hour, false},
+	{"m", time.Minute, false},
+	{"s", time.Second, false},
+	{"ms", time.Millisecond, false},
+}
+
+// formatDuration returns the prometheus duration, i.e: 30d, 4w or 1h30m
+func formatDuration(d time.Duration) string {
+	if d == 0 {
+		return "0s"
+	}
+	var b strings.Builder
+	for _, u := range durationUnits {
+		if u.exact && d%u.duration != 0 {
+			continue
+		}
+		if n := d / u.duration; n > 0 {
+			b.WriteString(strconv.FormatInt(int64(n), 10))
+			b.WriteString(u.unit)
+			d -= n * u.duration
+		}
+	}
+	return b.String()
+}
+
+// parseDuration parses the prometheus duration, i.e: 30d, 4w or 1h30m
+func parseDuration(s string) (time.Duration, error) {
+	var d time.Duration
+	rest := s
+	for rest != "" {
+		i := 0
+		for i < len(rest) && rest[i] >= '0' && rest[i] <= '9' {
+			i++
+		}
+		if i == 0 {
+			return 0, errors.Errorf("invalid duration %q", s)
+		}
+		n, err := strconv.ParseInt(rest[:i], 10, 64)
+		if err != nil {
+			return 0, errors.Annotatef(err, "invalid duration %q", s)
+		}
+		rest = rest[i:]
+
+		found := false
+		// ms is checked before m
+		for _, u := range []string{"ms", "y", "w", "d", "h", "m", "s"} {
+			if strings.HasPrefix(rest, u) {
+				for _, unit := range durationUnits {
+					if unit.unit == u {
+						d += time.Duration(n) * unit.duration
+					}
+				}
+				rest = rest[len(u):]
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, errors.Errorf("invalid duration %q, unknown unit", s)
+		}
+	}
+	if s == "" {
+		return 0, errors.New("empty duration")
+	}
+	return d, nil
+}
@@ -0,0 +1,3 @@
+// Package pyrra contains the parser targeting the Pyrra ServiceLevelObjective custom resource as a specification.
+// The sloth annotations are parsed by the sloth parser and each SLO is converted to a pyrra.dev/v1alpha1 ServiceLevelObjective.
+package pyrra
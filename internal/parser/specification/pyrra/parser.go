@@ -0,0 +1,179 @@
+package pyrra
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	multierr "github.com/hashicorp/go-multierror"
+	"github.com/juju/errors"
+	sloth "github.com/slok/sloth/pkg/prometheus/api/v1"
+	"github.com/slosive/sloscribe/internal/diagnostics"
+	"github.com/slosive/sloscribe/internal/generate"
+	"github.com/slosive/sloscribe/internal/logging"
+	"github.com/slosive/sloscribe/internal/parser/options"
+	"github.com/slosive/sloscribe/internal/parser/specification"
+	slothparser "github.com/slosive/sloscribe/internal/parser/specification/sloth"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefaultWindow is the time window of the SLOs, it is the same as the sloth default SLO period
+const DefaultWindow = "30d"
+
+var (
+	// rangeSelector matches the metric selector of the sloth query range vector, i.e: http_requests_total{code="500"}[{{.window}}]
+	rangeSelector = regexp.MustCompile(`([a-zA-Z_:][a-zA-Z0-9_:]*\s*(?:{[^}]*})?|{[^}]*})\s*\[\s*{{\s*\.window\s*}}\s*]`)
+	// grouping matches the labels of the query aggregation, i.e: sum by (handler)
+	grouping = regexp.MustCompile(`\bby\s*\(([^)]*)\)`)
+)
+
+// parser converts the sloth specifications, returned by the sloth parser, to Pyrra ServiceLevelObjectives
+type parser struct {
+	slothParser specification.Target
+	window      string
+	logger      *logging.Logger
+	// strict tells the parser to return the problems found while converting the specifications as an error
+	strict bool
+	// problems aggregates the warnings logged while converting the specifications
+	problems error
+}
+
+// Parser returns the options.Option to run the parser targeting Pyrra as a specification.
+// The window is the time window of the SLOs, i.e: 4w, DefaultWindow is used if empty.
+func Parser(window string) options.Option {
+	return func(opts *options.Options) {
+		slothparser.Parser(false)(opts)
+		if window == "" {
+			window = DefaultWindow
+		}
+		opts.TargetSpecification = &parser{slothParser: opts.TargetSpecification, window: window, logger: opts.Logger, strict: opts.Strict}
+	}
+}
+
+// Parse returns the Pyrra ServiceLevelObjectives, as generate.Documents, of each service found by the sloth parser.
+// The SLOs that can't be converted are skipped.
+func (p *parser) Parse(ctx context.Context) (map[string]any, error) {
+	specs, err := p.slothParser.Parse(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(map[string]any, len(specs))
+	for name, spec := range specs {
+		s, ok := spec.(*sloth.Spec)
+		if !ok {
+			continue
+		}
+		var docs generate.Documents
+		for _, slo := range s.SLOs {
+			objective, err := Convert(s, slo, p.window)
+			if err != nil {
+				p.warn(err, "service", s.Service, "slo", slo.Name)
+				continue
+			}
+			docs = append(docs, objective)
+		}
+		results[name] = docs
+	}
+	if p.strict {
+		if err := diagnostics.Strict(p.problems); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// Convert returns the Pyrra ServiceLevelObjective of the sloth SLO.
+// Only events SLIs can be converted, their queries are replaced by the metric selectors of the range vectors using the {{.window}} template.
+func Convert(spec *sloth.Spec, slo sloth.SLO, window string) (*ServiceLevelObjective, error) {
+	if slo.SLI.Events == nil {
+		return nil, errors.Errorf("SLO %q can't be converted to a pyrra ServiceLevelObjective, only error_query and total_query SLIs are supported", slo.Name)
+	}
+	errorsMetric, err := metricSelector(slo.SLI.Events.ErrorQuery)
+	if err != nil {
+		return nil, errors.Annotatef(err, "SLO %q error_query can't be converted", slo.Name)
+	}
+	totalMetric, err := metricSelector(slo.SLI.Events.TotalQuery)
+	if err != nil {
+		return nil, errors.Annotatef(err, "SLO %q total_query can't be converted", slo.Name)
+	}
+
+	labels := make(map[string]string, len(spec.Labels)+len(slo.Labels))
+	for key, value := range spec.Labels {
+		labels[key] = value
+	}
+	for key, value := range slo.Labels {
+		labels[key] = value
+	}
+
+	return &ServiceLevelObjective{
+		TypeMeta: v1.TypeMeta{
+			Kind:       Kind,
+			APIVersion: APIVersion,
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name:   fmt.Sprintf("%s-%s", spec.Service, slo.Name),
+			Labels: labels,
+		},
+		Spec: ServiceLevelObjectiveSpec{
+			Description: slo.Description,
+			Target:      strconv.FormatFloat(slo.Objective, 'f', -1, 64),
+			Window:      window,
+			Indicator: Indicator{
+				Ratio: &RatioIndicator{
+					Errors:   Query{Metric: errorsMetric},
+					Total:    Query{Metric: totalMetric},
+					Grouping: queryGrouping(slo.SLI.Events.ErrorQuery),
+				},
+			},
+			Alerting: alerting(slo.Alerting),
+		},
+	}, nil
+}
+
+// metricSelector returns the metric selector of the range vector using the {{.window}} template in the sloth query,
+// i.e: http_requests_total{code="500"} for sum(rate(http_requests_total{code="500"}[{{.window}}])).
+func metricSelector(query string) (string, error) {
+	matches := rangeSelector.FindAllStringSubmatch(query, -1)
+	if len(matches) != 1 {
+		return "", errors.Errorf("expected a single range vector using the {{.window}} template, found %d in %q", len(matches), query)
+	}
+	return strings.TrimSpace(matches[0][1]), nil
+}
+
+// queryGrouping returns the labels the query is aggregated by, i.e: handler for sum by (handler)
+func queryGrouping(query string) []string {
+	match := grouping.FindStringSubmatch(query)
+	if match == nil {
+		return nil
+	}
+	var labels []string
+	for _, label := range strings.Split(match[1], ",") {
+		if label = strings.TrimSpace(label); label != "" {
+			labels = append(labels, label)
+		}
+	}
+	return labels
+}
+
+// alerting returns the Pyrra alerting of the sloth alerting, the alerts are disabled if both the page and ticket alerts are disabled
+func alerting(a sloth.Alerting) *Alerting {
+	disabled := a.PageAlert.Disable && a.TicketAlert.Disable
+	if a.Name == "" && !disabled {
+		return nil
+	}
+	result := &Alerting{Name: a.Name}
+	if disabled {
+		result.Disabled = &disabled
+	}
+	return result
+}
+
+func (p *parser) warn(err error, keyValues ...interface{}) {
+	p.problems = multierr.Append(p.problems, err)
+	if p.logger != nil {
+		p.logger.Warn(err, keyValues...)
+	}
+}
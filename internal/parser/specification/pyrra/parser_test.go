@@ -0,0 +1,167 @@
+package pyrra
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	sloth "github.com/slok/sloth/pkg/prometheus/api/v1"
+	"github.com/slosive/sloscribe/internal/diagnostics"
+	"github.com/slosive/sloscribe/internal/generate"
+	"github.com/slosive/sloscribe/internal/logging"
+	sloscribe "github.com/slosive/sloscribe/internal/parser"
+	"github.com/slosive/sloscribe/internal/parser/lang"
+	"github.com/slosive/sloscribe/internal/parser/options"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvert(t *testing.T) {
+	t.Parallel()
+
+	spec := &sloth.Spec{
+		Version: sloth.Version,
+		Service: "chatgpt",
+		Labels:  map[string]string{"team": "platform"},
+	}
+
+	t.Run("Successfully convert the events SLI SLO to a pyrra ServiceLevelObjective", func(t *testing.T) {
+		objective, err := Convert(spec, sloth.SLO{
+			Name:        "availability",
+			Description: "95% of logins should be successful.",
+			Objective:   99.5,
+			Labels:      map[string]string{"tier": "1"},
+			SLI: sloth.SLI{Events: &sloth.SLIEvents{
+				ErrorQuery: `sum by (handler) (rate(tenant_failed_login_operations_total{client="chat-gpt"}[{{.window}}])) OR on() vector(0)`,
+				TotalQuery: `sum by (handler) (rate(tenant_login_operations_total{client="chat-gpt"}[{{ .window }}]))`,
+			}},
+			Alerting: sloth.Alerting{Name: "ChatGPTAvailability"},
+		}, "4w")
+		require.NoError(t, err)
+
+		assert.Equal(t, APIVersion, objective.APIVersion)
+		assert.Equal(t, Kind, objective.Kind)
+		assert.Equal(t, "chatgpt-availability", objective.Name)
+		assert.Equal(t, map[string]string{"team": "platform", "tier": "1"}, objective.Labels)
+		assert.Equal(t, "99.5", objective.Spec.Target)
+		assert.Equal(t, "4w", objective.Spec.Window)
+		assert.Equal(t, "95% of logins should be successful.", objective.Spec.Description)
+		require.NotNil(t, objective.Spec.Indicator.Ratio)
+		assert.Equal(t, `tenant_failed_login_operations_total{client="chat-gpt"}`, objective.Spec.Indicator.Ratio.Errors.Metric)
+		assert.Equal(t, `tenant_login_operations_total{client="chat-gpt"}`, objective.Spec.Indicator.Ratio.Total.Metric)
+		assert.Equal(t, []string{"handler"}, objective.Spec.Indicator.Ratio.Grouping)
+		assert.Equal(t, &Alerting{Name: "ChatGPTAvailability"}, objective.Spec.Alerting)
+	})
+
+	t.Run("Successfully disable the alerts if the page and ticket alerts are disabled", func(t *testing.T) {
+		objective, err := Convert(spec, sloth.SLO{
+			Name: "availability",
+			SLI: sloth.SLI{Events: &sloth.SLIEvents{
+				ErrorQuery: `sum(rate(errors_total[{{.window}}]))`,
+				TotalQuery: `sum(rate(requests_total[{{.window}}]))`,
+			}},
+			Alerting: sloth.Alerting{PageAlert: sloth.Alert{Disable: true}, TicketAlert: sloth.Alert{Disable: true}},
+		}, DefaultWindow)
+		require.NoError(t, err)
+		require.NotNil(t, objective.Spec.Alerting)
+		require.NotNil(t, objective.Spec.Alerting.Disabled)
+		assert.True(t, *objective.Spec.Alerting.Disabled)
+		assert.Equal(t, "errors_total", objective.Spec.Indicator.Ratio.Errors.Metric)
+	})
+
+	t.Run("Fail to convert a raw SLI SLO", func(t *testing.T) {
+		_, err := Convert(spec, sloth.SLO{
+			Name: "latency",
+			SLI:  sloth.SLI{Raw: &sloth.SLIRaw{ErrorRatioQuery: `sum(rate(tenant_slow_requests_total[{{.window}}]))`}},
+		}, DefaultWindow)
+		require.Error(t, err)
+	})
+
+	t.Run("Fail to convert a query without a single windowed range vector", func(t *testing.T) {
+		_, err := Convert(spec, sloth.SLO{
+			Name: "availability",
+			SLI: sloth.SLI{Events: &sloth.SLIEvents{
+				ErrorQuery: `sum(rate(errors_total[5m]))`,
+				TotalQuery: `sum(rate(requests_total[{{.window}}]))`,
+			}},
+		}, DefaultWindow)
+		require.Error(t, err)
+	})
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Successfully parse the sloth annotations as pyrra ServiceLevelObjectives", func(t *testing.T) {
+		logger := logging.NewStandardLogger()
+		p, err := sloscribe.New(
+			options.Language(lang.Go),
+			Parser("2w"),
+			options.Logger(&logger),
+			options.SourceContent(io.NopCloser(strings.NewReader(`package main
+// @sloth service chatgpt
+// @sloth.slo name availability
+// @sloth.slo objective 95.0
+// @sloth.sli error_query sum(rate(tenant_failed_login_operations_total[{{.window}}]))
+// @sloth.sli total_query sum(rate(tenant_login_operations_total[{{.window}}]))
+func main() {}
+
+// @sloth.slo name latency
+// @sloth.sli error_ratio_query sum(rate(tenant_slow_requests_total[{{.window}}]))
+func slow() {}
+`))),
+		)
+		require.NoError(t, err)
+
+		specs, err := p.Parse(context.Background())
+		require.NoError(t, err)
+		docs, ok := specs["chatgpt"].(generate.Documents)
+		require.True(t, ok)
+		require.Len(t, docs, 1)
+
+		var w bytes.Buffer
+		require.NoError(t, generate.WriteK8Specifications(&w, nil, specs, false, "", "yaml"))
+		assert.Equal(t, `---
+
+apiVersion: pyrra.dev/v1alpha1
+kind: ServiceLevelObjective
+metadata:
+  creationTimestamp: null
+  name: chatgpt-availability
+spec:
+  indicator:
+    ratio:
+      errors:
+        metric: tenant_failed_login_operations_total
+      total:
+        metric: tenant_login_operations_total
+  target: "95"
+  window: 2w
+`, w.String())
+	})
+	t.Run("Fail to parse the SLOs which can't be converted in strict mode", func(t *testing.T) {
+		logger := logging.NewStandardLogger()
+		p, err := sloscribe.New(
+			options.Language(lang.Go),
+			Parser("2w"),
+			options.Logger(&logger),
+			options.Strict(true),
+			options.SourceContent(io.NopCloser(strings.NewReader(`package main
+// @sloth service chatgpt
+// @sloth.slo name latency
+// @sloth.sli error_ratio_query sum(rate(tenant_slow_requests_total[{{.window}}]))
+func slow() {}
+`))),
+		)
+		require.NoError(t, err)
+
+		specs, err := p.Parse(context.Background())
+		assert.Nil(t, specs)
+
+		var strictErr *diagnostics.StrictError
+		require.ErrorAs(t, err, &strictErr)
+		assert.Contains(t, strictErr.Problems.Error(), `SLO "latency" can't be converted`)
+	})
+}
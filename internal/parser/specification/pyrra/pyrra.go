@@ -0,0 +1,52 @@
+package pyrra
+
+import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The Pyrra v1alpha1 custom resources, see https://github.com/pyrra-dev/pyrra#kubernetes
+type (
+	// ServiceLevelObjective is the Pyrra custom resource of an SLO
+	ServiceLevelObjective struct {
+		v1.TypeMeta   `json:",inline"`
+		v1.ObjectMeta `json:"metadata,omitempty"`
+		Spec          ServiceLevelObjectiveSpec `json:"spec"`
+	}
+
+	ServiceLevelObjectiveSpec struct {
+		Description string `json:"description,omitempty"`
+		// Target is the percentage of good events, i.e: "99.5"
+		Target string `json:"target"`
+		// Window is the time window of the SLO, i.e: 4w
+		Window    string    `json:"window"`
+		Indicator Indicator `json:"indicator"`
+		Alerting  *Alerting `json:"alerting,omitempty"`
+	}
+
+	Indicator struct {
+		Ratio *RatioIndicator `json:"ratio,omitempty"`
+	}
+
+	// RatioIndicator is the ratio of the errors to the total requests, the metrics are selectors without range, i.e: http_requests_total{code=~"5.."}
+	RatioIndicator struct {
+		Errors   Query    `json:"errors"`
+		Total    Query    `json:"total"`
+		Grouping []string `json:"grouping,omitempty"`
+	}
+
+	Query struct {
+		Metric string `json:"metric"`
+	}
+
+	Alerting struct {
+		Name     string `json:"name,omitempty"`
+		Disabled *bool  `json:"disabled,omitempty"`
+	}
+)
+
+const (
+	// APIVersion is the Pyrra custom resources API version
+	APIVersion = "pyrra.dev/v1alpha1"
+	// Kind is the kind of the Pyrra SLO custom resource
+	Kind = "ServiceLevelObjective"
+)
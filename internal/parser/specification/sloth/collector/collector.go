@@ -0,0 +1,522 @@
+package collector
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+
+	multierr "github.com/hashicorp/go-multierror"
+	"github.com/juju/errors"
+	k8sloth "github.com/slok/sloth/pkg/kubernetes/api/sloth/v1"
+	sloth "github.com/slok/sloth/pkg/prometheus/api/v1"
+	"github.com/slosive/sloscribe/internal/diagnostics"
+	"github.com/slosive/sloscribe/internal/logging"
+	"github.com/slosive/sloscribe/internal/parser/specification/sloth/grammar"
+	"github.com/slosive/sloscribe/internal/promql"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Collector merges the sloth annotations parsed from the different comment groups into service specifications
+type Collector struct {
+	// specs contains references to all the service specifications that have been parsed
+	specs map[string]any
+	// current references the current service specification being parsed
+	current any
+	logger  *logging.Logger
+	// reporter receives the diagnostics of the annotations, these are logged as warnings if nil
+	reporter diagnostics.Reporter
+	// fset is the file set of the comment groups being collected, used to position the diagnostics in the source files
+	fset *token.FileSet
+	// problems aggregates the warnings and diagnostics reported by the collector
+	problems error
+	// evaluator evaluates the sloth annotations of the comment groups
+	evaluator grammar.Evaluator
+	// symbols are the symbols of the source code referenced by the comment groups being collected, nil if they can't be resolved
+	symbols Symbols
+	// catalogs are all the symbols set to the collector, the SLI queries are checked against their metrics
+	catalogs map[Symbols]struct{}
+	// queries are the SLI queries of the SLOs collected with symbols, positioned in the source files
+	queries []grammar.Query
+	// templates are the SLO templates declared in the comment groups, indexed by name
+	templates map[string]*template
+	// uses are the SLOs using a template, these are instantiated once all the templates are collected
+	uses []use
+	// variables are the variables declared in the comment groups, indexed by scope and name
+	variables map[scope]map[string]grammar.Variable
+	// declarations are the declarations scanned from the comment groups, see Declare
+	declarations map[*ast.CommentGroup]grammar.Declarations
+	// kubernetes tells the collector to output a kubernetes specification of the service
+	kubernetes bool
+	// scanned tells the collector the comments are positioned at their text, see WithScannedComments
+	scanned bool
+	// positions receives the positions of the annotations declaring the collected SLOs, see WithPositions
+	positions *diagnostics.Positions
+	// sloPositions are the positions of the annotations declaring the SLOs, indexed by service specification and SLO name
+	sloPositions map[any]map[string]diagnostics.SLOPosition
+}
+
+// New creates a new Collector, if the logger is nil the standard logger is used
+func New(logger *logging.Logger, kubernetes bool) *Collector {
+	if logger == nil {
+		l := logging.NewStandardLogger()
+		logger = &l
+	}
+	return &Collector{
+		specs:        map[string]any{},
+		templates:    map[string]*template{},
+		variables:    map[scope]map[string]grammar.Variable{},
+		declarations: map[*ast.CommentGroup]grammar.Declarations{},
+		catalogs:     map[Symbols]struct{}{},
+		sloPositions: map[any]map[string]diagnostics.SLOPosition{},
+		current:      nil,
+		logger:       logger,
+		kubernetes:   kubernetes,
+	}
+}
+
+// WithReporter sets the reporter receiving the diagnostics of the annotations
+func (c *Collector) WithReporter(reporter diagnostics.Reporter) *Collector {
+	c.reporter = reporter
+	return c
+}
+
+// WithQueryValidation tells the collector to validate the PromQL queries of the SLIs,
+// the invalid queries are reported as diagnostics at the position of their annotation
+func (c *Collector) WithQueryValidation(validate bool) *Collector {
+	c.evaluator.ValidateQuery = nil
+	if validate {
+		c.evaluator.ValidateQuery = promql.Validate
+	}
+	return c
+}
+
+// WithScannedComments tells the collector the comments are the ones returned by the comments scanner, whose markers
+// aren't the go ones: each comment is positioned at the start of its text, which is prefixed with the go line comment marker
+func (c *Collector) WithScannedComments(scanned bool) *Collector {
+	c.scanned = scanned
+	return c
+}
+
+// Symbols resolves the symbols of the annotated source code referenced by the annotations, i.e: the Go constants and the Prometheus metrics.
+// The comment groups referencing them are identified by their position in the source files.
+type Symbols interface {
+	// Constant returns the value of the constant referenced by the comment group, i.e: {{go "Ident"}}
+	Constant(pos token.Position, name string) (string, error)
+	// Metrics returns the metrics referenced by the SLI queries of the comment group, i.e: {{.metric}}
+	Metrics(pos token.Position) grammar.Metrics
+	// Catalog returns every metric declared by the source code, the SLI queries are checked against them
+	Catalog() []Metric
+}
+
+// WithSymbols sets the symbols referenced by the comment groups being collected,
+// the language parsers set them to the symbols of the package being collected
+func (c *Collector) WithSymbols(symbols Symbols) *Collector {
+	c.symbols = symbols
+	if symbols != nil {
+		c.catalogs[symbols] = struct{}{}
+	}
+	return c
+}
+
+// Collect parses the comment groups for sloth annotations and merges them into the collected service specifications.
+// The comment groups are positioned in fset, which can be nil if their positions are unknown.
+func (c *Collector) Collect(fset *token.FileSet, comments ...*ast.CommentGroup) error {
+	c.fset = fset
+	c.Declare(fset, comments...)
+	if c.kubernetes {
+		return c.parseK8SlothAnnotations(comments...)
+	}
+	return c.parseSlothAnnotations(comments...)
+}
+
+// Specs returns the service specifications collected so far, indexed by service name
+func (c *Collector) Specs() map[string]any {
+	return c.specs
+}
+
+func (c *Collector) parseK8SlothAnnotations(comments ...*ast.CommentGroup) error {
+	if c.current == nil {
+		c.current = &k8sloth.PrometheusServiceLevel{
+			TypeMeta: v1.TypeMeta{
+				Kind:       "PrometheusServiceLevel",
+				APIVersion: "sloth.slok.dev/v1",
+			},
+			ObjectMeta: v1.ObjectMeta{
+				Labels: map[string]string{},
+			},
+			Spec: k8sloth.PrometheusServiceLevelSpec{
+				Service: "",
+				Labels:  map[string]string{},
+				SLOs:    nil,
+			},
+		}
+	}
+
+	c.logger.Debug("Current service being parsed", "service", c.current.(*k8sloth.PrometheusServiceLevel).Spec.Service)
+	for _, comment := range comments {
+		// partialServiceSpec contains the partially parsed sloth Specification for a given comment group
+		// this means the parsed spec will only contain data for the fields that are present in the comments, making the spec only partially accurate
+		doc, ok := c.eval(comment)
+		if !ok {
+			continue
+		}
+		partialServiceSpec := doc.Spec
+
+		// if the comment group contains a reference to the service name
+		// check if service was parsed before else add it the collection of specs.
+		// Set the found service spec as the current service spec.
+		if partialServiceSpec.Service != "" {
+			if c.current != nil && (c.current.(*k8sloth.PrometheusServiceLevel).Name == partialServiceSpec.Service || c.current.(*k8sloth.PrometheusServiceLevel).Name == "") {
+				c.specs[partialServiceSpec.Service] = c.current
+			}
+			spec, ok := c.specs[partialServiceSpec.Service]
+			if !ok {
+				tmpSpec := &k8sloth.PrometheusServiceLevel{
+					TypeMeta: v1.TypeMeta{
+						Kind:       "PrometheusServiceLevel",
+						APIVersion: "sloth.slok.dev/v1",
+					},
+					ObjectMeta: v1.ObjectMeta{
+						Name:   partialServiceSpec.Service,
+						Labels: map[string]string{},
+					},
+					Spec: k8sloth.PrometheusServiceLevelSpec{
+						Service: partialServiceSpec.Service,
+						Labels:  partialServiceSpec.Labels,
+						// the SLOs of the comment group are merged below
+						SLOs: nil,
+					},
+				}
+				c.specs[partialServiceSpec.Service] = tmpSpec
+				c.current = tmpSpec
+			} else {
+				c.current = spec.(*k8sloth.PrometheusServiceLevel)
+			}
+		}
+
+		if c.current.(*k8sloth.PrometheusServiceLevel).Name == "" {
+			c.current.(*k8sloth.PrometheusServiceLevel).Name = partialServiceSpec.Service
+		}
+
+		for key, label := range partialServiceSpec.Labels {
+			c.current.(*k8sloth.PrometheusServiceLevel).Labels[key] = label
+		}
+
+		if c.current.(*k8sloth.PrometheusServiceLevel).Spec.Service == "" {
+			c.current.(*k8sloth.PrometheusServiceLevel).Spec.Service = partialServiceSpec.Service
+		}
+
+		for key, label := range partialServiceSpec.Labels {
+			c.current.(*k8sloth.PrometheusServiceLevel).Spec.Labels[key] = label
+		}
+
+		if len(partialServiceSpec.SLOs) > 0 {
+			slos := fromKubernetes(c.current.(*k8sloth.PrometheusServiceLevel).Spec.SLOs...)
+			c.current.(*k8sloth.PrometheusServiceLevel).Spec.SLOs = toKubernetes(c.mergeSLOs(slos, comment, partialServiceSpec.SLOs...)...)
+		}
+		c.addUses(c.current, doc.Uses...)
+		c.addPositions(c.current, doc.Positions)
+	}
+	return nil
+}
+
+func toKubernetes(slos ...sloth.SLO) []k8sloth.SLO {
+	var k8SLOs []k8sloth.SLO
+	for _, slo := range slos {
+		result := k8sloth.SLO{
+			Name:        slo.Name,
+			Description: slo.Description,
+			Objective:   slo.Objective,
+			Labels:      slo.Labels,
+			SLI: k8sloth.SLI{
+				Raw:    (*k8sloth.SLIRaw)(slo.SLI.Raw),
+				Events: (*k8sloth.SLIEvents)(slo.SLI.Events),
+				Plugin: (*k8sloth.SLIPlugin)(slo.SLI.Plugin),
+			},
+			Alerting: k8sloth.Alerting{
+				Name:        slo.Alerting.Name,
+				Labels:      slo.Alerting.Labels,
+				Annotations: slo.Alerting.Annotations,
+				PageAlert: k8sloth.Alert{
+					Disable:     slo.Alerting.PageAlert.Disable,
+					Labels:      slo.Alerting.PageAlert.Labels,
+					Annotations: slo.Alerting.PageAlert.Annotations,
+				},
+				TicketAlert: k8sloth.Alert{
+					Disable:     slo.Alerting.TicketAlert.Disable,
+					Labels:      slo.Alerting.TicketAlert.Labels,
+					Annotations: slo.Alerting.TicketAlert.Annotations,
+				},
+			},
+		}
+
+		k8SLOs = append(k8SLOs, result)
+	}
+
+	return k8SLOs
+}
+
+// parseSlothAnnotations parses the source code comments for sloth annotations using the sloth grammar.
+// The SLOs with the same name, in the same or different comment groups, are deep merged
+func (c *Collector) parseSlothAnnotations(comments ...*ast.CommentGroup) error {
+	if c.current == nil {
+		c.current = &sloth.Spec{
+			Version: "",
+			Service: "",
+			Labels:  make(map[string]string),
+			SLOs:    make([]sloth.SLO, 0),
+		}
+	}
+
+	c.logger.Debug("Current service being parsed", "service", c.current.(*sloth.Spec).Service)
+
+	for _, comment := range comments {
+		// partialServiceSpec contains the partially parsed sloth Specification for a given comment group
+		// this means the parsed spec will only contain data for the fields that are present in the comments, making the spec only partially accurate
+		doc, ok := c.eval(comment)
+		if !ok {
+			continue
+		}
+		partialServiceSpec := doc.Spec
+
+		// if the comment group contains a reference to the service name
+		// check if service was parsed before else add it the collection of specs.
+		// Set the found service spec as the current service spec.
+		if partialServiceSpec.Service != "" {
+			if c.current != nil && (c.current.(*sloth.Spec).Service == partialServiceSpec.Service || c.current.(*sloth.Spec).Service == "") {
+				c.specs[partialServiceSpec.Service] = c.current
+			}
+			spec, ok := c.specs[partialServiceSpec.Service]
+			if !ok {
+				// the SLOs of the comment group are merged below
+				newSpec := *partialServiceSpec
+				newSpec.SLOs = nil
+				c.specs[partialServiceSpec.Service] = &newSpec
+				c.current = &newSpec
+			} else {
+				c.current = spec.(*sloth.Spec)
+			}
+		}
+
+		if c.current.(*sloth.Spec).Service == "" {
+			c.current.(*sloth.Spec).Service = partialServiceSpec.Service
+		}
+		if c.current.(*sloth.Spec).Version == "" {
+			c.current.(*sloth.Spec).Version = partialServiceSpec.Version
+		}
+
+		for key, label := range partialServiceSpec.Labels {
+			c.current.(*sloth.Spec).Labels[key] = label
+		}
+
+		c.current.(*sloth.Spec).SLOs = c.mergeSLOs(c.current.(*sloth.Spec).SLOs, comment, partialServiceSpec.SLOs...)
+		c.addUses(c.current, doc.Uses...)
+		c.addPositions(c.current, doc.Positions)
+	}
+	return nil
+}
+
+// eval evaluates the sloth annotations in the comment group, the problems found are reported as diagnostics.
+// It returns false if the comment group doesn't contain sloth annotations or these can't be evaluated.
+func (c *Collector) eval(comment *ast.CommentGroup) (*grammar.Document, bool) {
+	text, lines := c.commentText(comment)
+	if !strings.HasPrefix(text, "@sloth") {
+		return nil, false
+	}
+	c.logger.Debug("Parsing", "comment", text)
+	evaluator := c.evaluator
+	evaluator.Variables = c.scopedVariables(comment)
+	if symbols := c.symbols; symbols != nil {
+		pos := c.groupPosition(comment)
+		evaluator.ResolveConstant = func(name string) (string, error) {
+			return symbols.Constant(pos, name)
+		}
+		evaluator.Metrics = symbols.Metrics(pos)
+	}
+	doc, err := evaluator.EvalDocument(text)
+	if err != nil {
+		c.report(err, c.fset, lines)
+	}
+	if doc == nil {
+		return nil, false
+	}
+	c.addTemplates(lines, doc.Templates...)
+	for i := range doc.Uses {
+		doc.Uses[i].Pos = position(c.fset, lines, doc.Uses[i].Pos)
+	}
+	for name, pos := range doc.Positions {
+		doc.Positions[name] = diagnostics.SLOPosition{SLO: position(c.fset, lines, pos.SLO), SLI: position(c.fset, lines, pos.SLI)}
+	}
+	if doc.ServicePos.Line > 0 {
+		doc.ServicePos = position(c.fset, lines, doc.ServicePos)
+		c.addService(doc.Spec.Service, doc.ServicePos)
+	}
+	// only the queries of the source code with symbols, i.e: go, are checked against the metrics it declares,
+	// the other languages don't declare their metrics in the catalogs
+	if c.symbols != nil {
+		for _, query := range doc.Queries {
+			query.Pos = position(c.fset, lines, query.Pos)
+			c.queries = append(c.queries, query)
+		}
+	}
+	return doc, true
+}
+
+// report reports the diagnostics of a comment group, positioning them in the source file.
+// lines are the source positions of the comment group text lines, in fset.
+func (c *Collector) report(err error, fset *token.FileSet, lines []token.Pos) {
+	var list diagnostics.List
+	if !errors.As(err, &list) {
+		c.warn(err)
+		return
+	}
+	for _, d := range list {
+		d.Pos = position(fset, lines, d.Pos)
+		c.reportDiagnostic(d)
+	}
+}
+
+// reportDiagnostic reports a diagnostic positioned in the source file
+func (c *Collector) reportDiagnostic(d diagnostics.Diagnostic) {
+	if c.reporter == nil {
+		c.warn(d)
+		return
+	}
+	c.problems = multierr.Append(c.problems, d)
+	c.reporter.Report(d)
+}
+
+// groupPosition returns the source position of the comment group, empty if the comment groups aren't positioned
+func (c *Collector) groupPosition(comment *ast.CommentGroup) token.Position {
+	if c.fset == nil {
+		return token.Position{}
+	}
+	return c.fset.Position(comment.Pos())
+}
+
+// mergeSLOs merges the SLOs parsed from the comment group into the collected SLOs, the SLOs with the same name are deep merged.
+// The SLOs conflicting with the collected ones are reported as diagnostics, at the comment group position, and skipped.
+func (c *Collector) mergeSLOs(slos []sloth.SLO, comment *ast.CommentGroup, parsed ...sloth.SLO) []sloth.SLO {
+	for _, slo := range parsed {
+		index := -1
+		for i, currSLO := range slos {
+			if currSLO.Name == slo.Name {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			slos = append(slos, slo)
+			continue
+		}
+
+		merged, conflicts := mergeSLO(slos[index], slo)
+		if len(conflicts) > 0 {
+			c.reportDiagnostic(diagnostics.New(c.groupPosition(comment), diagnostics.CodeConflictingSLO,
+				"SLO %q is already defined with different values: %s", slo.Name, strings.Join(conflicts, ", ")))
+			continue
+		}
+		slos[index] = merged
+	}
+	return slos
+}
+
+// position returns the source position of the line and column of the comment group text, lines are positioned in fset
+func position(fset *token.FileSet, lines []token.Pos, pos token.Position) token.Position {
+	if fset == nil || pos.Line < 1 || pos.Line > len(lines) {
+		return pos
+	}
+	column := pos.Column - 1
+	pos = fset.Position(lines[pos.Line-1])
+	pos.Column += column
+	pos.Offset += column
+	return pos
+}
+
+// commentText returns the text of the comment group and the source position of each of its lines.
+// Like ast.CommentGroup.Text, the comment markers and the first space of the line comments are removed,
+// the text starts at the first line which isn't empty.
+func (c *Collector) commentText(group *ast.CommentGroup) (string, []token.Pos) {
+	var lines []string
+	var starts []token.Pos
+	for _, comment := range group.List {
+		text, start := comment.Text, comment.Slash
+		switch {
+		case strings.HasPrefix(text, "//"):
+			text = text[2:]
+			if !c.scanned {
+				// the scanned comments are positioned at their text, the go line comment marker isn't in the source
+				start += 2
+			}
+			if strings.HasPrefix(text, " ") {
+				text, start = text[1:], start+1
+			}
+		case strings.HasPrefix(text, "/*"):
+			text, start = strings.TrimSuffix(text[2:], "*/"), start+2
+		}
+		for _, line := range strings.Split(text, "\n") {
+			lines = append(lines, strings.TrimRight(line, " \t\r"))
+			starts = append(starts, start)
+			start += token.Pos(len(line) + 1)
+		}
+	}
+
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines, starts = lines[1:], starts[1:]
+	}
+	if len(lines) > 0 {
+		trimmed := strings.TrimLeft(lines[0], " \t")
+		starts[0] += token.Pos(len(lines[0]) - len(trimmed))
+		lines[0] = trimmed
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), " \t\r\n"), starts
+}
+
+// Finish checks the specifications once all the comment groups are collected, the SLOs using a template are instantiated,
+// the SLI queries are checked against the metrics declared in the source code and the SLOs without a service are reported.
+// The positions of the collected SLOs are added to the positions set to the collector.
+// It returns every problem reported by the collector, aggregated in a multierror.
+func (c *Collector) Finish() error {
+	c.expand()
+	c.checkQueries()
+	c.finishPositions()
+
+	var service string
+	var slos []string
+	switch current := c.current.(type) {
+	case *sloth.Spec:
+		service = current.Service
+		for _, slo := range current.SLOs {
+			slos = append(slos, slo.Name)
+		}
+	case *k8sloth.PrometheusServiceLevel:
+		service = current.Spec.Service
+		for _, slo := range current.Spec.SLOs {
+			slos = append(slos, slo.Name)
+		}
+	}
+	if service == "" && len(slos) > 0 {
+		c.warn(errors.Errorf("the SLOs %q don't have a service, a @sloth service annotation is missing", slos))
+	}
+	return c.problems
+}
+
+func (c *Collector) warn(err error, keyValues ...interface{}) {
+	c.problems = multierr.Append(c.problems, err)
+	if c.logger != nil {
+		c.logger.Warn(err, keyValues...)
+	}
+}
+
+// Stats logs the number of SLOs found for each collected service, it is called once the collector is finished
+func (c *Collector) Stats() {
+	for _, spec := range c.specs {
+		if c.kubernetes {
+			s := spec.(*k8sloth.PrometheusServiceLevel)
+			c.logger.Info("Found", "service", s.Spec.Service, "SLOs", len(s.Spec.SLOs))
+		} else {
+			s := spec.(*sloth.Spec)
+			c.logger.Info("Found", "service", s.Service, "SLOs", len(s.SLOs))
+		}
+	}
+}
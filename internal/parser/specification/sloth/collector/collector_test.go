@@ -0,0 +1,1450 @@
+package collector
+
+import (
+	"errors"
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"testing"
+
+	k8sloth "github.com/slok/sloth/pkg/kubernetes/api/sloth/v1"
+	sloth "github.com/slok/sloth/pkg/prometheus/api/v1"
+	"github.com/slosive/sloscribe/internal/diagnostics"
+	"github.com/slosive/sloscribe/internal/parser/specification/sloth/grammar"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseAnnotations(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Successfully parse the sloth annotations per single commentGroup, should return 1 specification", func(t *testing.T) {
+		collector := New(nil, false)
+		require.NoError(t, collector.parseSlothAnnotations(&ast.CommentGroup{List: []*ast.Comment{{Text: `@sloth service foobar`}}},
+			&ast.CommentGroup{List: []*ast.Comment{
+				{
+					Text: `@sloth.slo name availability`,
+				},
+				{
+					Text: `@sloth.slo description availability SLO`,
+				},
+				{
+					Text: `@sloth.slo objective 95.0`,
+				},
+			}}))
+		assert.Equal(t, "foobar", (collector.specs["foobar"].(*sloth.Spec)).Service)
+		assert.Equal(t, sloth.SLO{
+			Name:        "availability",
+			Description: "availability SLO",
+			Objective:   95.0,
+			Labels:      make(map[string]string),
+			SLI:         sloth.SLI{},
+			Alerting:    sloth.Alerting{},
+		}, (collector.specs["foobar"].(*sloth.Spec)).SLOs[0])
+	})
+
+	t.Run("Successfully parse the sloth annotations per single commentGroup, should return 1 specification", func(t *testing.T) {
+		collector := New(nil, false)
+		require.NoError(t, collector.parseSlothAnnotations(&ast.CommentGroup{List: []*ast.Comment{
+			{
+				Text: `@sloth service foobar`,
+			},
+			{
+				Text: `@sloth.slo name availability`,
+			},
+			{
+				Text: `@sloth.slo description availability SLO`,
+			},
+			{
+				Text: `@sloth.slo objective 95.0`,
+			},
+		}}))
+		assert.Equal(t, "foobar", (collector.specs["foobar"].(*sloth.Spec)).Service)
+		assert.Equal(t, sloth.SLO{
+			Name:        "availability",
+			Description: "availability SLO",
+			Objective:   95.0,
+			Labels:      make(map[string]string),
+			SLI:         sloth.SLI{},
+			Alerting:    sloth.Alerting{},
+		}, (collector.specs["foobar"].(*sloth.Spec)).SLOs[0])
+	})
+
+	t.Run("Successfully parse sloth service if service name is defined after SLO definition", func(t *testing.T) {
+		collector := New(nil, false)
+		comments := []*ast.CommentGroup{
+			{List: []*ast.Comment{
+				{
+					Text: `@sloth.slo name availability`,
+				},
+				{
+					Text: `@sloth.slo description availability SLO for foobar service`,
+				},
+				{
+					Text: `@sloth.slo objective 95.0`,
+				},
+			}},
+			{List: []*ast.Comment{
+				{
+					Text: `@sloth service foobar`,
+				},
+			}},
+		}
+		require.NoError(t, collector.parseSlothAnnotations(comments...))
+		require.Len(t, collector.specs, 1)
+		resultSpec := collector.specs
+
+		expected := []*sloth.Spec{
+			{
+				Version: sloth.Version,
+				Service: "foobar",
+				Labels:  make(map[string]string),
+				SLOs: []sloth.SLO{
+					{
+						Name:        "availability",
+						Description: "availability SLO for foobar service",
+						Objective:   95.0,
+						Labels:      make(map[string]string),
+						SLI: sloth.SLI{
+							Raw:    nil,
+							Events: nil,
+							Plugin: nil,
+						},
+						Alerting: sloth.Alerting{
+							Name:        "",
+							Labels:      nil,
+							Annotations: nil,
+							PageAlert: sloth.Alert{
+								Disable:     false,
+								Labels:      nil,
+								Annotations: nil,
+							},
+							TicketAlert: sloth.Alert{
+								Disable:     false,
+								Labels:      nil,
+								Annotations: nil,
+							},
+						},
+					},
+				},
+			},
+		}
+
+		for _, exp := range expected {
+			actual, ok := resultSpec[exp.Service]
+			require.True(t, ok)
+			assert.Equal(t, exp, actual)
+		}
+	})
+
+	t.Run("Successfully parse multiple Sloth services with single SLO defined, should return 3 specifications", func(t *testing.T) {
+		collector := New(nil, false)
+		comments := []*ast.CommentGroup{
+			{List: []*ast.Comment{
+				{
+					Text: `@sloth service foobar`,
+				},
+				{
+					Text: `@sloth.slo name availability`,
+				},
+				{
+					Text: `@sloth.slo description availability SLO for foobar service`,
+				},
+				{
+					Text: `@sloth.slo objective 95.0`,
+				},
+			}},
+			{List: []*ast.Comment{
+				{
+					Text: `@sloth service foo`,
+				},
+				{
+					Text: `@sloth.slo name availability`,
+				},
+				{
+					Text: `@sloth.slo description availability SLO for foo service`,
+				},
+				{
+					Text: `@sloth.slo objective 95.0`,
+				},
+			}},
+			{List: []*ast.Comment{
+				{
+					Text: `@sloth service bar`,
+				},
+				{
+					Text: `@sloth.slo name availability`,
+				},
+				{
+					Text: `@sloth.slo description availability SLO for bar service`,
+				},
+				{
+					Text: `@sloth.slo objective 95.0`,
+				},
+			}},
+		}
+		require.NoError(t, collector.parseSlothAnnotations(comments...))
+		require.Len(t, collector.specs, 3)
+		resultSpec := collector.specs
+
+		expected := []*sloth.Spec{
+			{
+				Version: sloth.Version,
+				Service: "foo",
+				Labels:  make(map[string]string),
+				SLOs: []sloth.SLO{
+					{
+						Name:        "availability",
+						Description: "availability SLO for foo service",
+						Objective:   95.0,
+						Labels:      make(map[string]string),
+						SLI: sloth.SLI{
+							Raw:    nil,
+							Events: nil,
+							Plugin: nil,
+						},
+						Alerting: sloth.Alerting{
+							Name:        "",
+							Labels:      nil,
+							Annotations: nil,
+							PageAlert: sloth.Alert{
+								Disable:     false,
+								Labels:      nil,
+								Annotations: nil,
+							},
+							TicketAlert: sloth.Alert{
+								Disable:     false,
+								Labels:      nil,
+								Annotations: nil,
+							},
+						},
+					},
+				},
+			},
+			{
+				Version: sloth.Version,
+				Service: "bar",
+				Labels:  make(map[string]string),
+				SLOs: []sloth.SLO{
+					{
+						Name:        "availability",
+						Description: "availability SLO for bar service",
+						Objective:   95.0,
+						Labels:      make(map[string]string),
+						SLI: sloth.SLI{
+							Raw:    nil,
+							Events: nil,
+							Plugin: nil,
+						},
+						Alerting: sloth.Alerting{
+							Name:        "",
+							Labels:      nil,
+							Annotations: nil,
+							PageAlert: sloth.Alert{
+								Disable:     false,
+								Labels:      nil,
+								Annotations: nil,
+							},
+							TicketAlert: sloth.Alert{
+								Disable:     false,
+								Labels:      nil,
+								Annotations: nil,
+							},
+						},
+					},
+				},
+			},
+			{
+				Version: sloth.Version,
+				Service: "foobar",
+				Labels:  make(map[string]string),
+				SLOs: []sloth.SLO{
+					{
+						Name:        "availability",
+						Description: "availability SLO for foobar service",
+						Objective:   95.0,
+						Labels:      make(map[string]string),
+						SLI: sloth.SLI{
+							Raw:    nil,
+							Events: nil,
+							Plugin: nil,
+						},
+						Alerting: sloth.Alerting{
+							Name:        "",
+							Labels:      nil,
+							Annotations: nil,
+							PageAlert: sloth.Alert{
+								Disable:     false,
+								Labels:      nil,
+								Annotations: nil,
+							},
+							TicketAlert: sloth.Alert{
+								Disable:     false,
+								Labels:      nil,
+								Annotations: nil,
+							},
+						},
+					},
+				},
+			},
+		}
+
+		for _, exp := range expected {
+			actual, ok := resultSpec[exp.Service]
+			require.True(t, ok)
+			assert.Equal(t, exp, actual)
+		}
+	})
+
+	t.Run("Successfully parse multiple Sloth services with multiple SLOs defined, should return 3 specifications", func(t *testing.T) {
+		collector := New(nil, false)
+		comments := []*ast.CommentGroup{
+			{List: []*ast.Comment{
+				{
+					Text: `@sloth service foobar`,
+				},
+				{
+					Text: `@sloth.slo name availability`,
+				},
+				{
+					Text: `@sloth.slo description availability SLO for foobar service`,
+				},
+				{
+					Text: `@sloth.slo objective 95.0`,
+				},
+			}},
+			{List: []*ast.Comment{
+				{
+					Text: `@sloth.slo name correctness`,
+				},
+				{
+					Text: `@sloth.slo description correctness SLO for foobar service`,
+				},
+				{
+					Text: `@sloth.slo objective 55.0`,
+				},
+			}},
+			{List: []*ast.Comment{
+				{
+					Text: `@sloth service foo`,
+				},
+				{
+					Text: `@sloth.slo name availability`,
+				},
+				{
+					Text: `@sloth.slo description availability SLO for foo service`,
+				},
+				{
+					Text: `@sloth.slo objective 95.0`,
+				},
+			}},
+			{List: []*ast.Comment{
+				{
+					Text: `@sloth.slo name correctness`,
+				},
+				{
+					Text: `@sloth.slo description correctness SLO for foo service`,
+				},
+				{
+					Text: `@sloth.slo objective 85.0`,
+				},
+			}},
+			{List: []*ast.Comment{
+				{
+					Text: `@sloth.slo name freshness`,
+				},
+				{
+					Text: `@sloth.slo description freshness SLO for foo service`,
+				},
+				{
+					Text: `@sloth.slo objective 99.999`,
+				},
+			}},
+			{List: []*ast.Comment{
+				{
+					Text: `@sloth service bar`,
+				},
+				{
+					Text: `@sloth.slo name availability`,
+				},
+				{
+					Text: `@sloth.slo description availability SLO for bar service`,
+				},
+				{
+					Text: `@sloth.slo objective 95.0`,
+				},
+			}},
+		}
+		require.NoError(t, collector.parseSlothAnnotations(comments...))
+		require.Len(t, collector.specs, 3)
+		resultSpec := collector.specs
+
+		expected := []*sloth.Spec{
+			{
+				Version: sloth.Version,
+				Service: "foo",
+				Labels:  make(map[string]string),
+				SLOs: []sloth.SLO{
+					{
+						Name:        "availability",
+						Description: "availability SLO for foo service",
+						Objective:   95.0,
+						Labels:      make(map[string]string),
+						SLI: sloth.SLI{
+							Raw:    nil,
+							Events: nil,
+							Plugin: nil,
+						},
+						Alerting: sloth.Alerting{
+							Name:        "",
+							Labels:      nil,
+							Annotations: nil,
+							PageAlert: sloth.Alert{
+								Disable:     false,
+								Labels:      nil,
+								Annotations: nil,
+							},
+							TicketAlert: sloth.Alert{
+								Disable:     false,
+								Labels:      nil,
+								Annotations: nil,
+							},
+						},
+					},
+					{
+						Name:        "correctness",
+						Description: "correctness SLO for foo service",
+						Objective:   85.0,
+						Labels:      make(map[string]string),
+						SLI: sloth.SLI{
+							Raw:    nil,
+							Events: nil,
+							Plugin: nil,
+						},
+						Alerting: sloth.Alerting{
+							Name:        "",
+							Labels:      nil,
+							Annotations: nil,
+							PageAlert: sloth.Alert{
+								Disable:     false,
+								Labels:      nil,
+								Annotations: nil,
+							},
+							TicketAlert: sloth.Alert{
+								Disable:     false,
+								Labels:      nil,
+								Annotations: nil,
+							},
+						},
+					},
+					{
+						Name:        "freshness",
+						Description: "freshness SLO for foo service",
+						Objective:   99.999,
+						Labels:      make(map[string]string),
+						SLI: sloth.SLI{
+							Raw:    nil,
+							Events: nil,
+							Plugin: nil,
+						},
+						Alerting: sloth.Alerting{
+							Name:        "",
+							Labels:      nil,
+							Annotations: nil,
+							PageAlert: sloth.Alert{
+								Disable:     false,
+								Labels:      nil,
+								Annotations: nil,
+							},
+							TicketAlert: sloth.Alert{
+								Disable:     false,
+								Labels:      nil,
+								Annotations: nil,
+							},
+						},
+					},
+				},
+			},
+			{
+				Version: sloth.Version,
+				Service: "bar",
+				Labels:  make(map[string]string),
+				SLOs: []sloth.SLO{
+					{
+						Name:        "availability",
+						Description: "availability SLO for bar service",
+						Objective:   95.0,
+						Labels:      make(map[string]string),
+						SLI: sloth.SLI{
+							Raw:    nil,
+							Events: nil,
+							Plugin: nil,
+						},
+						Alerting: sloth.Alerting{
+							Name:        "",
+							Labels:      nil,
+							Annotations: nil,
+							PageAlert: sloth.Alert{
+								Disable:     false,
+								Labels:      nil,
+								Annotations: nil,
+							},
+							TicketAlert: sloth.Alert{
+								Disable:     false,
+								Labels:      nil,
+								Annotations: nil,
+							},
+						},
+					},
+				},
+			},
+			{
+				Version: sloth.Version,
+				Service: "foobar",
+				Labels:  make(map[string]string),
+				SLOs: []sloth.SLO{
+					{
+						Name:        "availability",
+						Description: "availability SLO for foobar service",
+						Objective:   95.0,
+						Labels:      make(map[string]string),
+						SLI: sloth.SLI{
+							Raw:    nil,
+							Events: nil,
+							Plugin: nil,
+						},
+						Alerting: sloth.Alerting{
+							Name:        "",
+							Labels:      nil,
+							Annotations: nil,
+							PageAlert: sloth.Alert{
+								Disable:     false,
+								Labels:      nil,
+								Annotations: nil,
+							},
+							TicketAlert: sloth.Alert{
+								Disable:     false,
+								Labels:      nil,
+								Annotations: nil,
+							},
+						},
+					},
+					{
+						Name:        "correctness",
+						Description: "correctness SLO for foobar service",
+						Objective:   55.0,
+						Labels:      make(map[string]string),
+						SLI: sloth.SLI{
+							Raw:    nil,
+							Events: nil,
+							Plugin: nil,
+						},
+						Alerting: sloth.Alerting{
+							Name:        "",
+							Labels:      nil,
+							Annotations: nil,
+							PageAlert: sloth.Alert{
+								Disable:     false,
+								Labels:      nil,
+								Annotations: nil,
+							},
+							TicketAlert: sloth.Alert{
+								Disable:     false,
+								Labels:      nil,
+								Annotations: nil,
+							},
+						},
+					},
+				},
+			},
+		}
+
+		for _, exp := range expected {
+			actual, ok := resultSpec[exp.Service]
+			require.True(t, ok)
+			assert.Equal(t, exp, actual)
+		}
+	})
+
+	t.Run("Fail to parse the sloth spec SLO item if sloth annotation name for a given SLO is missing", func(t *testing.T) {
+		collector := New(nil, false)
+		require.NoError(t, collector.parseSlothAnnotations(&ast.CommentGroup{List: []*ast.Comment{
+			{
+				Text: `@sloth service bar`,
+			},
+			{
+				Text: `@sloth.slo description availability SLO`,
+			},
+			{
+				Text: `@sloth.slo objective 95.0`,
+			},
+		}}))
+		assert.Len(t, (collector.specs["bar"].(*sloth.Spec)).SLOs, 0)
+	})
+
+	t.Run("Successfully parse and merge duplicate Sloth service", func(t *testing.T) {
+		collector := New(nil, false)
+		comments := []*ast.CommentGroup{
+			{List: []*ast.Comment{
+				{
+					Text: `@sloth service foobar`,
+				},
+				{
+					Text: `@sloth.slo name availability`,
+				},
+				{
+					Text: `@sloth.slo description availability SLO for foobar service`,
+				},
+				{
+					Text: `@sloth.slo objective 95.0`,
+				},
+			}},
+			{List: []*ast.Comment{
+				{
+					Text: `@sloth service foobar`,
+				},
+				{
+					Text: `@sloth.slo name foobar_availability`,
+				},
+				{
+					Text: `@sloth.slo description availability SLO for foobar service`,
+				},
+				{
+					Text: `@sloth.slo objective 99.0`,
+				},
+			}},
+		}
+		require.NoError(t, collector.parseSlothAnnotations(comments...))
+		require.Len(t, collector.specs, 1)
+		resultSpec := collector.specs
+
+		expected := []*sloth.Spec{
+			{
+				Version: sloth.Version,
+				Service: "foobar",
+				Labels:  make(map[string]string),
+				SLOs: []sloth.SLO{
+					{
+						Name:        "availability",
+						Description: "availability SLO for foobar service",
+						Objective:   95.0,
+						Labels:      make(map[string]string),
+						SLI: sloth.SLI{
+							Raw:    nil,
+							Events: nil,
+							Plugin: nil,
+						},
+						Alerting: sloth.Alerting{
+							Name:        "",
+							Labels:      nil,
+							Annotations: nil,
+							PageAlert: sloth.Alert{
+								Disable:     false,
+								Labels:      nil,
+								Annotations: nil,
+							},
+							TicketAlert: sloth.Alert{
+								Disable:     false,
+								Labels:      nil,
+								Annotations: nil,
+							},
+						},
+					},
+					{
+						Name:        "foobar_availability",
+						Description: "availability SLO for foobar service",
+						Objective:   99.0,
+						Labels:      make(map[string]string),
+						SLI: sloth.SLI{
+							Raw:    nil,
+							Events: nil,
+							Plugin: nil,
+						},
+						Alerting: sloth.Alerting{
+							Name:        "",
+							Labels:      nil,
+							Annotations: nil,
+							PageAlert: sloth.Alert{
+								Disable:     false,
+								Labels:      nil,
+								Annotations: nil,
+							},
+							TicketAlert: sloth.Alert{
+								Disable:     false,
+								Labels:      nil,
+								Annotations: nil,
+							},
+						},
+					},
+				},
+			},
+		}
+
+		for _, exp := range expected {
+			actual, ok := resultSpec[exp.Service]
+			require.True(t, ok)
+			assert.Equal(t, exp, actual)
+		}
+	})
+}
+
+func TestParseK8SAnnotations(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Successfully parse sloth service if service name is defined after slo definition", func(t *testing.T) {
+		collector := New(nil, false)
+		comments := []*ast.CommentGroup{
+			{List: []*ast.Comment{
+				{
+					Text: `@sloth.slo name availability`,
+				},
+				{
+					Text: `@sloth.slo description availability SLO for foobar service`,
+				},
+				{
+					Text: `@sloth.slo objective 95.0`,
+				},
+			}},
+			{List: []*ast.Comment{
+				{
+					Text: `@sloth service foobar`,
+				},
+			}},
+		}
+		require.NoError(t, collector.parseK8SlothAnnotations(comments...))
+		require.Len(t, collector.specs, 1)
+		resultSpec := collector.specs
+
+		expected := []*k8sloth.PrometheusServiceLevel{
+			{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "PrometheusServiceLevel",
+					APIVersion: "sloth.slok.dev/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:   "foobar",
+					Labels: make(map[string]string),
+				},
+				Spec: k8sloth.PrometheusServiceLevelSpec{
+					Service: "foobar",
+					Labels:  make(map[string]string),
+					SLOs: []k8sloth.SLO{
+						{
+							Name:        "availability",
+							Description: "availability SLO for foobar service",
+							Objective:   95.0,
+							Labels:      make(map[string]string),
+							SLI: k8sloth.SLI{
+								Raw:    nil,
+								Events: nil,
+								Plugin: nil,
+							},
+							Alerting: k8sloth.Alerting{
+								Name:        "",
+								Labels:      nil,
+								Annotations: nil,
+								PageAlert: k8sloth.Alert{
+									Disable:     false,
+									Labels:      nil,
+									Annotations: nil,
+								},
+								TicketAlert: k8sloth.Alert{
+									Disable:     false,
+									Labels:      nil,
+									Annotations: nil,
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		for _, exp := range expected {
+			actual, ok := resultSpec[exp.Name]
+			require.True(t, ok)
+			assert.Equal(t, exp, actual)
+		}
+	})
+
+	t.Run("Successfully parse multiple Sloth services, should return 3 specifications", func(t *testing.T) {
+		collector := New(nil, false)
+		comments := []*ast.CommentGroup{
+			{List: []*ast.Comment{
+				{
+					Text: `@sloth service foobar`,
+				},
+				{
+					Text: `@sloth.slo name availability`,
+				},
+				{
+					Text: `@sloth.slo description availability SLO for foobar service`,
+				},
+				{
+					Text: `@sloth.slo objective 95.0`,
+				},
+			}},
+			{List: []*ast.Comment{
+				{
+					Text: `@sloth service foo`,
+				},
+				{
+					Text: `@sloth.slo name availability`,
+				},
+				{
+					Text: `@sloth.slo description availability SLO for foo service`,
+				},
+				{
+					Text: `@sloth.slo objective 95.0`,
+				},
+			}},
+			{List: []*ast.Comment{
+				{
+					Text: `@sloth service bar`,
+				},
+				{
+					Text: `@sloth.slo name availability`,
+				},
+				{
+					Text: `@sloth.slo description availability SLO for bar service`,
+				},
+				{
+					Text: `@sloth.slo objective 95.0`,
+				},
+			}},
+		}
+		require.NoError(t, collector.parseK8SlothAnnotations(comments...))
+		require.Len(t, collector.specs, 3)
+		resultSpec := collector.specs
+
+		expected := []*k8sloth.PrometheusServiceLevel{
+			{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "PrometheusServiceLevel",
+					APIVersion: "sloth.slok.dev/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:   "bar",
+					Labels: make(map[string]string),
+				},
+				Spec: k8sloth.PrometheusServiceLevelSpec{
+					Service: "bar",
+					Labels:  make(map[string]string),
+					SLOs: []k8sloth.SLO{
+						{
+							Name:        "availability",
+							Description: "availability SLO for bar service",
+							Objective:   95.0,
+							Labels:      make(map[string]string),
+							SLI: k8sloth.SLI{
+								Raw:    nil,
+								Events: nil,
+								Plugin: nil,
+							},
+							Alerting: k8sloth.Alerting{
+								Name:        "",
+								Labels:      nil,
+								Annotations: nil,
+								PageAlert: k8sloth.Alert{
+									Disable:     false,
+									Labels:      nil,
+									Annotations: nil,
+								},
+								TicketAlert: k8sloth.Alert{
+									Disable:     false,
+									Labels:      nil,
+									Annotations: nil,
+								},
+							},
+						},
+					},
+				},
+			},
+			{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "PrometheusServiceLevel",
+					APIVersion: "sloth.slok.dev/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:   "foobar",
+					Labels: make(map[string]string),
+				},
+				Spec: k8sloth.PrometheusServiceLevelSpec{
+					Service: "foobar",
+					Labels:  make(map[string]string),
+					SLOs: []k8sloth.SLO{
+						{
+							Name:        "availability",
+							Description: "availability SLO for foobar service",
+							Objective:   95.0,
+							Labels:      make(map[string]string),
+							SLI: k8sloth.SLI{
+								Raw:    nil,
+								Events: nil,
+								Plugin: nil,
+							},
+							Alerting: k8sloth.Alerting{
+								Name:        "",
+								Labels:      nil,
+								Annotations: nil,
+								PageAlert: k8sloth.Alert{
+									Disable:     false,
+									Labels:      nil,
+									Annotations: nil,
+								},
+								TicketAlert: k8sloth.Alert{
+									Disable:     false,
+									Labels:      nil,
+									Annotations: nil,
+								},
+							},
+						},
+					},
+				},
+			},
+			{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "PrometheusServiceLevel",
+					APIVersion: "sloth.slok.dev/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:   "foo",
+					Labels: make(map[string]string),
+				},
+				Spec: k8sloth.PrometheusServiceLevelSpec{
+					Service: "foo",
+					Labels:  make(map[string]string),
+					SLOs: []k8sloth.SLO{
+						{
+							Name:        "availability",
+							Description: "availability SLO for foo service",
+							Objective:   95.0,
+							Labels:      make(map[string]string),
+							SLI: k8sloth.SLI{
+								Raw:    nil,
+								Events: nil,
+								Plugin: nil,
+							},
+							Alerting: k8sloth.Alerting{
+								Name:        "",
+								Labels:      nil,
+								Annotations: nil,
+								PageAlert: k8sloth.Alert{
+									Disable:     false,
+									Labels:      nil,
+									Annotations: nil,
+								},
+								TicketAlert: k8sloth.Alert{
+									Disable:     false,
+									Labels:      nil,
+									Annotations: nil,
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		for _, exp := range expected {
+			actual, ok := resultSpec[exp.Name]
+			require.True(t, ok)
+			assert.Equal(t, exp, actual)
+		}
+	})
+
+	t.Run("Successfully parse the sloth plugin SLI into the kubernetes specification", func(t *testing.T) {
+		collector := New(nil, true)
+		require.NoError(t, collector.Collect(nil, &ast.CommentGroup{List: []*ast.Comment{
+			{
+				Text: `@sloth service foobar`,
+			},
+			{
+				Text: `@sloth.slo name availability`,
+			},
+			{
+				Text: `@sloth.sli plugin sloth-common/kubernetes/apiserver/availability`,
+			},
+			{
+				Text: `@sloth.sli.plugin option filter job="apiserver"`,
+			},
+		}}))
+		spec, ok := collector.Specs()["foobar"].(*k8sloth.PrometheusServiceLevel)
+		require.True(t, ok)
+		require.Len(t, spec.Spec.SLOs, 1)
+		assert.Equal(t, k8sloth.SLI{
+			Plugin: &k8sloth.SLIPlugin{
+				ID:      "sloth-common/kubernetes/apiserver/availability",
+				Options: map[string]string{"filter": `job="apiserver"`},
+			},
+		}, spec.Spec.SLOs[0].SLI)
+	})
+}
+
+func TestDiagnostics(t *testing.T) {
+	t.Parallel()
+
+	src := `package main
+
+// @sloth service foobar
+
+// @sloth.slo name availability
+// @sloth.slo objective high
+func main() {}
+
+	// @sloth.slo name latency
+	// @sloth.slo objective 99
+	// @sloth.sli objective 99
+func foo() {}
+
+/*
+  @sloth.slo name broken
+  @sloth.slos name
+*/
+func bar() {}
+`
+
+	t.Run("Successfully report the diagnostics at the annotations position in the source file", func(t *testing.T) {
+		fset := token.NewFileSet()
+		file, err := goparser.ParseFile(fset, "main.go", src, goparser.ParseComments)
+		require.NoError(t, err)
+
+		var reported diagnostics.List
+		collector := New(nil, false).WithReporter(&reported)
+		require.NoError(t, collector.Collect(fset, file.Comments...))
+
+		require.Len(t, reported, 3)
+		assert.Equal(t, "main.go:6:4: error: invalid @sloth.slo objective value: \"high\" is not a number [invalid_annotation_value]", reported[0].Error())
+		assert.Equal(t, "main.go:11:5: warning: unknown attribute \"objective\" in @sloth.sli, the statement is ignored [unknown_annotation_attribute]", reported[1].Error())
+		assert.Equal(t, diagnostics.CodeSyntaxError, reported[2].Code)
+		assert.Equal(t, "main.go:16:9", reported[2].Pos.String())
+
+		// the comment groups with errors are skipped, the ones with warnings are collected
+		spec, ok := collector.Specs()["foobar"].(*sloth.Spec)
+		require.True(t, ok)
+		require.Len(t, spec.SLOs, 1)
+		assert.Equal(t, "latency", spec.SLOs[0].Name)
+	})
+
+	t.Run("Successfully report the diagnostics relative to the comment group without a file set", func(t *testing.T) {
+		var reported diagnostics.List
+		collector := New(nil, false).WithReporter(&reported)
+		require.NoError(t, collector.Collect(nil, &ast.CommentGroup{List: []*ast.Comment{
+			{Text: `// @sloth.slo name availability`},
+			{Text: `// @sloth.alerting.ticket disable maybe`},
+		}}))
+
+		require.Len(t, reported, 1)
+		assert.Equal(t, "2:1: error: invalid @sloth.alerting.ticket disable value: \"maybe\" is not a boolean [invalid_annotation_value]", reported[0].Error())
+	})
+	t.Run("Successfully return the positions of the annotations declaring the SLOs once finished", func(t *testing.T) {
+		src := `package main
+
+// @sloth.slo name availability
+// @sloth.slo objective 99.9
+//   @sloth.sli error_ratio_query sum(rate(errors_total[{{.window}}]))
+func foo() {}
+
+// @sloth service foobar
+// @sloth.slo name latency
+// @sloth.slo objective 99
+func bar() {}
+
+// @sloth.slo name availability
+// @sloth.slo description redeclared
+func baz() {}
+`
+		fset := token.NewFileSet()
+		file, err := goparser.ParseFile(fset, "main.go", src, goparser.ParseComments)
+		require.NoError(t, err)
+
+		positions := diagnostics.NewPositions()
+		collector := New(nil, false).WithPositions(positions)
+		require.NoError(t, collector.Collect(fset, file.Comments...))
+		assert.Empty(t, positions.SLO("foobar", "availability"))
+		// the service annotations are positioned before the service specifications are merged
+		services := positions.Services()
+		require.Len(t, services, 1)
+		assert.Equal(t, "foobar", services[0].Service)
+		assert.Equal(t, "main.go:8:4", services[0].Pos.String())
+		require.NoError(t, collector.Finish())
+
+		// the SLOs declared before the service annotation belong to the service
+		availability := positions.SLO("foobar", "availability")
+		assert.Equal(t, "main.go:3:4", availability.SLO.String())
+		assert.Equal(t, "main.go:5:6", availability.SLI.String())
+		// the SLI of an SLO which doesn't declare it is positioned at the SLO
+		latency := positions.SLO("foobar", "latency")
+		assert.Equal(t, "main.go:9:4", latency.SLO.String())
+		assert.Equal(t, "main.go:9:4", latency.SLI.String())
+	})
+}
+
+func TestQueryValidation(t *testing.T) {
+	t.Parallel()
+
+	src := `package main
+
+// @sloth service foobar
+
+// @sloth.slo name availability
+// @sloth.sli error_query sum(rate(http_requests_total{code=~"5.."[{{.window}}]))
+// @sloth.sli total_query sum(rate(http_requests_total[{{.window}}]))
+func main() {}
+`
+
+	t.Run("Successfully report the invalid PromQL queries at their annotation position", func(t *testing.T) {
+		fset := token.NewFileSet()
+		file, err := goparser.ParseFile(fset, "main.go", src, goparser.ParseComments)
+		require.NoError(t, err)
+
+		var reported diagnostics.List
+		collector := New(nil, false).WithReporter(&reported).WithQueryValidation(true)
+		require.NoError(t, collector.Collect(fset, file.Comments...))
+
+		require.Len(t, reported, 1)
+		assert.Equal(t, diagnostics.CodeInvalidQuery, reported[0].Code)
+		assert.Equal(t, "main.go:6:4", reported[0].Pos.String())
+	})
+
+	t.Run("Successfully collect the invalid PromQL queries if the validation is disabled", func(t *testing.T) {
+		fset := token.NewFileSet()
+		file, err := goparser.ParseFile(fset, "main.go", src, goparser.ParseComments)
+		require.NoError(t, err)
+
+		var reported diagnostics.List
+		collector := New(nil, false).WithReporter(&reported)
+		require.NoError(t, collector.Collect(fset, file.Comments...))
+
+		assert.Empty(t, reported)
+		spec, ok := collector.Specs()["foobar"].(*sloth.Spec)
+		require.True(t, ok)
+		assert.Len(t, spec.SLOs, 1)
+	})
+}
+
+func TestMergeSLOs(t *testing.T) {
+	t.Parallel()
+
+	files := map[string]string{
+		"metrics.go": `package main
+
+// @sloth service checkout
+// @sloth.slo name checkout-availability
+// @sloth.slo objective 99.9
+// @sloth.slo labels team=payments
+// @sloth.sli error_query sum(rate(checkout_requests_total{code=~"5.."}[{{.window}}]))
+// @sloth.sli total_query sum(rate(checkout_requests_total[{{.window}}]))
+var requests = 1
+`,
+		"alerts.go": `package main
+
+// @sloth service checkout
+// @sloth.slo name checkout-availability
+// @sloth.slo labels tier=1
+// @sloth.alerting name CheckoutAvailability
+// @sloth.alerting.ticket disable true
+var alerts = 1
+
+// @sloth.slo name checkout-availability
+// @sloth.slo objective 99
+// @sloth.alerting name CheckoutErrors
+var conflict = 1
+`,
+	}
+
+	collect := func(t *testing.T, kubernetes bool) (*Collector, diagnostics.List) {
+		var reported diagnostics.List
+		collector := New(nil, kubernetes).WithReporter(&reported)
+		fset := token.NewFileSet()
+		for _, filename := range []string{"metrics.go", "alerts.go"} {
+			file, err := goparser.ParseFile(fset, filename, files[filename], goparser.ParseComments)
+			require.NoError(t, err)
+			require.NoError(t, collector.Collect(fset, file.Comments...))
+		}
+		return collector, reported
+	}
+
+	t.Run("Successfully merge the SLOs with the same name defined in different files", func(t *testing.T) {
+		collector, reported := collect(t, false)
+
+		spec, ok := collector.Specs()["checkout"].(*sloth.Spec)
+		require.True(t, ok)
+		require.Len(t, spec.SLOs, 1)
+		slo := spec.SLOs[0]
+		assert.Equal(t, 99.9, slo.Objective)
+		assert.Equal(t, map[string]string{"team": "payments", "tier": "1"}, slo.Labels)
+		require.NotNil(t, slo.SLI.Events)
+		assert.Equal(t, "CheckoutAvailability", slo.Alerting.Name)
+		assert.True(t, slo.Alerting.TicketAlert.Disable)
+
+		require.Len(t, reported, 1)
+		assert.Equal(t, diagnostics.CodeConflictingSLO, reported[0].Code)
+		assert.Equal(t, `alerts.go:10:1: error: SLO "checkout-availability" is already defined with different values: objective 99.9 and 99, alerting name "CheckoutAvailability" and "CheckoutErrors" [conflicting_slo_definition]`, reported[0].Error())
+	})
+
+	t.Run("Successfully merge the kubernetes SLOs with the same name defined in different files", func(t *testing.T) {
+		collector, reported := collect(t, true)
+
+		spec, ok := collector.Specs()["checkout"].(*k8sloth.PrometheusServiceLevel)
+		require.True(t, ok)
+		require.Len(t, spec.Spec.SLOs, 1)
+		slo := spec.Spec.SLOs[0]
+		assert.Equal(t, 99.9, slo.Objective)
+		assert.Equal(t, "CheckoutAvailability", slo.Alerting.Name)
+		require.Len(t, reported, 1)
+		assert.Equal(t, diagnostics.CodeConflictingSLO, reported[0].Code)
+	})
+}
+
+func TestTemplates(t *testing.T) {
+	t.Parallel()
+
+	files := map[string]string{
+		"templates.go": `package main
+
+// @sloth.template http-availability
+// @sloth.slo objective 99.9
+// @sloth.sli error_query sum(rate(http_requests_total{handler="{{.handler}}",code=~"5.."}[{{.window}}]))
+// @sloth.sli total_query sum(rate(http_requests_total{handler="{{.handler}}"}[{{.window}}]))
+// @sloth.alerting name {{.alert}}
+var templates = 1
+`,
+		"handlers.go": `package main
+
+// @sloth service checkout
+// @sloth.slo name checkout-availability
+// @sloth.slo use http-availability handler=/checkout alert=CheckoutAvailability
+// @sloth.slo objective 99
+// @sloth.slo name cart-availability
+// @sloth.slo use http-availability handler=/cart
+// @sloth.slo name search-availability
+// @sloth.slo use search-availability
+var handlers = 1
+`,
+	}
+
+	collect := func(t *testing.T, kubernetes bool) (*Collector, diagnostics.List) {
+		var reported diagnostics.List
+		collector := New(nil, kubernetes).WithReporter(&reported)
+		fset := token.NewFileSet()
+		// the SLOs are collected before the templates they use
+		for _, filename := range []string{"handlers.go", "templates.go"} {
+			file, err := goparser.ParseFile(fset, filename, files[filename], goparser.ParseComments)
+			require.NoError(t, err)
+			require.NoError(t, collector.Collect(fset, file.Comments...))
+		}
+		_ = collector.Finish()
+		return collector, reported
+	}
+
+	t.Run("Successfully instantiate the templates used by the SLOs declared in other files", func(t *testing.T) {
+		collector, reported := collect(t, false)
+
+		spec, ok := collector.Specs()["checkout"].(*sloth.Spec)
+		require.True(t, ok)
+		require.Len(t, spec.SLOs, 3)
+
+		checkout := spec.SLOs[0]
+		assert.Equal(t, "checkout-availability", checkout.Name)
+		assert.Equal(t, 99.0, checkout.Objective)
+		require.NotNil(t, checkout.SLI.Events)
+		assert.Equal(t, `sum(rate(http_requests_total{handler="/checkout",code=~"5.."}[{{.window}}]))`, checkout.SLI.Events.ErrorQuery)
+		assert.Equal(t, "CheckoutAvailability", checkout.Alerting.Name)
+
+		require.Len(t, reported, 2)
+		assert.Equal(t, diagnostics.CodeInvalidTemplate, reported[0].Code)
+		assert.Equal(t, `templates.go:7:4: error: template "http-availability": missing template parameter(s) ["alert"] [invalid_slo_template]`, reported[0].Error())
+		assert.Equal(t, `handlers.go:10:4: error: template "search-availability" used by SLO "search-availability" is not declared [invalid_slo_template]`, reported[1].Error())
+	})
+
+	t.Run("Successfully instantiate the templates used by the kubernetes SLOs", func(t *testing.T) {
+		collector, _ := collect(t, true)
+
+		spec, ok := collector.Specs()["checkout"].(*k8sloth.PrometheusServiceLevel)
+		require.True(t, ok)
+		require.Len(t, spec.Spec.SLOs, 3)
+		assert.Equal(t, 99.0, spec.Spec.SLOs[0].Objective)
+		assert.Equal(t, "CheckoutAvailability", spec.Spec.SLOs[0].Alerting.Name)
+	})
+}
+
+func TestVariables(t *testing.T) {
+	t.Parallel()
+
+	files := map[string]string{
+		"pkg/slos.go": `package pkg
+
+// @sloth service chatgpt
+// @sloth.var objective 99.9
+var service = 1
+
+// @sloth.slo name availability
+// @sloth.slo objective ${objective}
+// @sloth.sli error_ratio_query sum(rate(${namespace}_errors_total[{{.window}}]))
+// @sloth.alerting name ${alert}
+var slos = 1
+`,
+		"pkg/vars.go": `package pkg
+
+// @sloth.var namespace chatgpt
+var vars = 1
+`,
+		"other/vars.go": `package other
+
+// @sloth.var alert HighErrorRate
+var other = 1
+`,
+	}
+
+	collect := func(t *testing.T, overrides map[string]string) (*Collector, diagnostics.List) {
+		var reported diagnostics.List
+		collector := New(nil, false).WithReporter(&reported).WithVariables(overrides)
+		fset := token.NewFileSet()
+		var parsed []*ast.File
+		for _, filename := range []string{"pkg/slos.go", "pkg/vars.go", "other/vars.go"} {
+			file, err := goparser.ParseFile(fset, filename, files[filename], goparser.ParseComments)
+			require.NoError(t, err)
+			collector.Declare(fset, file.Comments...)
+			parsed = append(parsed, file)
+		}
+		for _, file := range parsed {
+			require.NoError(t, collector.Collect(fset, file.Comments...))
+		}
+		return collector, reported
+	}
+
+	t.Run("Successfully replace the variables declared by the service and the package", func(t *testing.T) {
+		collector, reported := collect(t, map[string]string{"alert": "ChatGPTErrors"})
+
+		spec, ok := collector.Specs()["chatgpt"].(*sloth.Spec)
+		require.True(t, ok)
+		require.Len(t, spec.SLOs, 1)
+		assert.Equal(t, 99.9, spec.SLOs[0].Objective)
+		assert.Equal(t, "sum(rate(chatgpt_errors_total[{{.window}}]))", spec.SLOs[0].SLI.Raw.ErrorRatioQuery)
+		assert.Equal(t, "ChatGPTErrors", spec.SLOs[0].Alerting.Name)
+		assert.Empty(t, reported)
+	})
+
+	t.Run("Fail to reference a variable declared by another package", func(t *testing.T) {
+		collector, reported := collect(t, nil)
+
+		spec, ok := collector.Specs()["chatgpt"].(*sloth.Spec)
+		require.True(t, ok)
+		assert.Empty(t, spec.SLOs)
+		require.Len(t, reported, 1)
+		assert.Equal(t, `pkg/slos.go:10:4: error: undefined variable(s) ["alert"] in @sloth.alerting name [invalid_annotation_variable]`, reported[0].Error())
+	})
+}
+
+func TestFinish(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Successfully return the problems reported by the collector", func(t *testing.T) {
+		var reported diagnostics.List
+		collector := New(nil, false).WithReporter(&reported)
+		require.NoError(t, collector.Collect(nil, &ast.CommentGroup{List: []*ast.Comment{
+			{Text: `// @sloth service foobar`},
+			{Text: `// @sloth.sli objective 99`},
+		}}))
+
+		err := collector.Finish()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `unknown attribute "objective" in @sloth.sli`)
+	})
+
+	t.Run("Successfully report the SLOs without a service", func(t *testing.T) {
+		collector := New(nil, false)
+		require.NoError(t, collector.Collect(nil, &ast.CommentGroup{List: []*ast.Comment{
+			{Text: `// @sloth.slo name availability`},
+		}}))
+
+		err := collector.Finish()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `the SLOs ["availability"] don't have a service`)
+	})
+
+	t.Run("Successfully return nil if no problem was found", func(t *testing.T) {
+		collector := New(nil, false)
+		require.NoError(t, collector.Collect(nil, &ast.CommentGroup{List: []*ast.Comment{
+			{Text: `// @sloth service foobar`},
+			{Text: `// @sloth.slo name availability`},
+		}}))
+
+		assert.NoError(t, collector.Finish())
+	})
+}
+
+// catalog is a Symbols only declaring the catalog metrics
+type catalog struct {
+	metrics []Metric
+}
+
+func (c *catalog) Constant(token.Position, string) (string, error) {
+	return "", errors.New("no constants")
+}
+
+func (c *catalog) Metrics(token.Position) grammar.Metrics {
+	return grammar.Metrics{}
+}
+
+func (c *catalog) Catalog() []Metric {
+	return c.metrics
+}
+
+func TestCheckQueries(t *testing.T) {
+	t.Parallel()
+
+	comments := &ast.CommentGroup{List: []*ast.Comment{
+		{Text: `// @sloth service chatgpt`},
+		{Text: `// @sloth.slo name availability`},
+		{Text: `// @sloth.slo objective 99.9`},
+		{Text: `// @sloth.sli error_query sum(rate(http_requests_total{code=~"5..",method="GET",job="api"}[{{.window}}]))`},
+		{Text: `// @sloth.sli total_query sum(rate(http_request_total[{{.window}}]))`},
+		{Text: `// @sloth.slo name latency`},
+		{Text: `// @sloth.slo objective 99`},
+		{Text: `// @sloth.sli error_query sum(rate(http_request_duration_seconds_bucket{le="0.5",handler="/"}[{{.window}}]))`},
+		{Text: `// @sloth.sli total_query sum(rate(http_request_duration_seconds_count[{{.window}}]))`},
+	}}
+
+	collect := func(t *testing.T, metrics ...Metric) (diagnostics.List, error) {
+		var reported diagnostics.List
+		collector := New(nil, false).WithReporter(&reported).WithSymbols(&catalog{metrics: metrics})
+		require.NoError(t, collector.Collect(nil, comments))
+		return reported, collector.Finish()
+	}
+
+	t.Run("Successfully warn about the metrics and labels not declared in the source code", func(t *testing.T) {
+		reported, err := collect(t,
+			Metric{Name: "http_requests_total", Series: []string{"http_requests_total"}, Labels: []string{"code"}},
+			Metric{
+				Name:          "http_request_duration_seconds",
+				Series:        []string{"http_request_duration_seconds", "http_request_duration_seconds_bucket", "http_request_duration_seconds_count", "http_request_duration_seconds_sum"},
+				Labels:        []string{"le"},
+				UnknownLabels: true,
+			})
+
+		require.Error(t, err)
+		assert.False(t, reported.HasErrors())
+		require.Len(t, reported, 2)
+		assert.Equal(t, `4:1: warning: SLO "availability" error_query references the label "method", which isn't declared by the metric "http_requests_total" [undeclared_metric]`, reported[0].Error())
+		assert.Equal(t, `5:1: warning: SLO "availability" total_query references the metric "http_request_total", which isn't declared in the source code [undeclared_metric]`, reported[1].Error())
+	})
+
+	t.Run("Successfully skip the queries collected without symbols", func(t *testing.T) {
+		var reported diagnostics.List
+		collector := New(nil, false).WithReporter(&reported).WithSymbols(&catalog{metrics: []Metric{{Name: "up", Series: []string{"up"}}}})
+		// the symbols are set per source file, the files without symbols don't declare their metrics in the catalogs
+		collector.WithSymbols(nil)
+		require.NoError(t, collector.Collect(nil, comments))
+		assert.NoError(t, collector.Finish())
+		assert.Empty(t, reported)
+	})
+
+	t.Run("Successfully skip the queries if the source code doesn't declare any metric", func(t *testing.T) {
+		reported, err := collect(t)
+		assert.NoError(t, err)
+		assert.Empty(t, reported)
+	})
+}
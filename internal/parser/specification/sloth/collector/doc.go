@@ -0,0 +1,2 @@
+// Package collector collects the sloth annotations found in the source code comment groups into service specifications.
+package collector
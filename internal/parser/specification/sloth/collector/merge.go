@@ -0,0 +1,158 @@
+package collector
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	k8sloth "github.com/slok/sloth/pkg/kubernetes/api/sloth/v1"
+	sloth "github.com/slok/sloth/pkg/prometheus/api/v1"
+)
+
+// merger deep merges SLOs, collecting the fields set in both SLOs with different values
+type merger struct {
+	conflicts []string
+	// override tells the merger to replace the fields set in both SLOs instead of reporting them as conflicts
+	override bool
+}
+
+// mergeSLO returns the deep merge of the SLOs src into dst.
+// The fields set in both SLOs with different values are returned as conflicts, in this case dst is returned unchanged.
+func mergeSLO(dst, src sloth.SLO) (sloth.SLO, []string) {
+	var merged sloth.SLO
+	// merging dst into an empty SLO deep copies it, it can't conflict
+	(&merger{}).slo(&merged, dst)
+
+	m := &merger{}
+	m.slo(&merged, src)
+	if len(m.conflicts) > 0 {
+		return dst, m.conflicts
+	}
+	return merged, nil
+}
+
+// overrideSLO returns the deep merge of the SLOs, the fields set in both SLOs take the value of override
+func overrideSLO(base, override sloth.SLO) sloth.SLO {
+	var merged sloth.SLO
+	m := &merger{override: true}
+	m.slo(&merged, base)
+	m.slo(&merged, override)
+	return merged
+}
+
+func (m *merger) slo(dst *sloth.SLO, src sloth.SLO) {
+	m.string("name", &dst.Name, src.Name)
+	m.string("description", &dst.Description, src.Description)
+	m.float("objective", &dst.Objective, src.Objective)
+	dst.Labels = m.labels("labels", dst.Labels, src.Labels)
+
+	if src.SLI.Events != nil {
+		if dst.SLI.Events == nil {
+			dst.SLI.Events = &sloth.SLIEvents{}
+		}
+		m.string("error_query", &dst.SLI.Events.ErrorQuery, src.SLI.Events.ErrorQuery)
+		m.string("total_query", &dst.SLI.Events.TotalQuery, src.SLI.Events.TotalQuery)
+	}
+	if src.SLI.Raw != nil {
+		if dst.SLI.Raw == nil {
+			dst.SLI.Raw = &sloth.SLIRaw{}
+		}
+		m.string("error_ratio_query", &dst.SLI.Raw.ErrorRatioQuery, src.SLI.Raw.ErrorRatioQuery)
+	}
+	if src.SLI.Plugin != nil {
+		if dst.SLI.Plugin == nil {
+			dst.SLI.Plugin = &sloth.SLIPlugin{}
+		}
+		m.string("plugin", &dst.SLI.Plugin.ID, src.SLI.Plugin.ID)
+		dst.SLI.Plugin.Options = m.labels("plugin options", dst.SLI.Plugin.Options, src.SLI.Plugin.Options)
+	}
+
+	m.string("alerting name", &dst.Alerting.Name, src.Alerting.Name)
+	dst.Alerting.Labels = m.labels("alerting labels", dst.Alerting.Labels, src.Alerting.Labels)
+	dst.Alerting.Annotations = m.labels("alerting annotations", dst.Alerting.Annotations, src.Alerting.Annotations)
+	m.alert("page", &dst.Alerting.PageAlert, src.Alerting.PageAlert)
+	m.alert("ticket", &dst.Alerting.TicketAlert, src.Alerting.TicketAlert)
+}
+
+func (m *merger) alert(field string, dst *sloth.Alert, src sloth.Alert) {
+	// an alert can only be disabled, an unset disable can't be told apart from false
+	dst.Disable = dst.Disable || src.Disable
+	dst.Labels = m.labels(field+" alert labels", dst.Labels, src.Labels)
+	dst.Annotations = m.labels(field+" alert annotations", dst.Annotations, src.Annotations)
+}
+
+func (m *merger) string(field string, dst *string, src string) {
+	switch {
+	case src == "" || src == *dst:
+	case *dst == "" || m.override:
+		*dst = src
+	default:
+		m.conflicts = append(m.conflicts, fmt.Sprintf("%s %q and %q", field, *dst, src))
+	}
+}
+
+func (m *merger) float(field string, dst *float64, src float64) {
+	switch {
+	case src == 0 || src == *dst:
+	case *dst == 0 || m.override:
+		*dst = src
+	default:
+		m.conflicts = append(m.conflicts, fmt.Sprintf("%s %s and %s", field, strconv.FormatFloat(*dst, 'f', -1, 64), strconv.FormatFloat(src, 'f', -1, 64)))
+	}
+}
+
+// labels returns the union of the labels, the keys are merged in order so that the conflicts are reported deterministically
+func (m *merger) labels(field string, dst, src map[string]string) map[string]string {
+	if dst == nil && src == nil {
+		return nil
+	}
+	result := make(map[string]string, len(dst)+len(src))
+	for key, value := range dst {
+		result[key] = value
+	}
+	keys := make([]string, 0, len(src))
+	for key := range src {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := result[key]
+		m.string(fmt.Sprintf("%s %s", field, key), &value, src[key])
+		result[key] = value
+	}
+	return result
+}
+
+// fromKubernetes returns the sloth SLOs of the kubernetes SLOs, it is the inverse of toKubernetes
+func fromKubernetes(slos ...k8sloth.SLO) []sloth.SLO {
+	var result []sloth.SLO
+	for _, slo := range slos {
+		result = append(result, sloth.SLO{
+			Name:        slo.Name,
+			Description: slo.Description,
+			Objective:   slo.Objective,
+			Labels:      slo.Labels,
+			SLI: sloth.SLI{
+				Raw:    (*sloth.SLIRaw)(slo.SLI.Raw),
+				Events: (*sloth.SLIEvents)(slo.SLI.Events),
+				Plugin: (*sloth.SLIPlugin)(slo.SLI.Plugin),
+			},
+			Alerting: sloth.Alerting{
+				Name:        slo.Alerting.Name,
+				Labels:      slo.Alerting.Labels,
+				Annotations: slo.Alerting.Annotations,
+				PageAlert: sloth.Alert{
+					Disable:     slo.Alerting.PageAlert.Disable,
+					Labels:      slo.Alerting.PageAlert.Labels,
+					Annotations: slo.Alerting.PageAlert.Annotations,
+				},
+				TicketAlert: sloth.Alert{
+					Disable:     slo.Alerting.TicketAlert.Disable,
+					Labels:      slo.Alerting.TicketAlert.Labels,
+					Annotations: slo.Alerting.TicketAlert.Annotations,
+				},
+			},
+		})
+	}
+	return result
+}
@@ -0,0 +1,88 @@
+package collector
+
+import (
+	"github.com/slosive/sloscribe/internal/diagnostics"
+	"github.com/slosive/sloscribe/internal/promql"
+)
+
+// Metric is a Prometheus metric declared by the annotated source code
+type Metric struct {
+	// Name is the fully qualified name of the metric
+	Name string
+	// Series are the names of the series exposed by the metric, i.e: the _bucket, _count and _sum series of a histogram
+	Series []string
+	// Labels are the label names of the metric series, including the const labels and the le label of the histogram buckets
+	Labels []string
+	// UnknownLabels is true if the label names can't be resolved, i.e: the label names of a *Vec metric are a variable
+	UnknownLabels bool
+}
+
+// scrapeLabels are the labels prometheus adds to the scraped series, these aren't declared by the source code
+var scrapeLabels = map[string]bool{"job": true, "instance": true}
+
+// hasLabel returns true if the metric series can have the label
+func (m Metric) hasLabel(label string) bool {
+	if m.UnknownLabels {
+		return true
+	}
+	for _, l := range m.Labels {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
+
+// checkQueries warns about the metrics and labels of the SLI queries which aren't declared in the source code.
+// The queries aren't checked if the source code doesn't declare any metric, i.e: the metrics are exposed by an exporter,
+// or if they are collected without symbols, i.e: the annotations of the languages whose metrics aren't in the catalogs.
+func (c *Collector) checkQueries() {
+	series := map[string][]Metric{}
+	for symbols := range c.catalogs {
+		for _, metric := range symbols.Catalog() {
+			for _, name := range metric.Series {
+				series[name] = append(series[name], metric)
+			}
+		}
+	}
+	if len(series) == 0 {
+		return
+	}
+
+	for _, query := range c.queries {
+		selectors, err := promql.Selectors(query.Query)
+		if err != nil {
+			// the invalid queries are reported by the query validation
+			continue
+		}
+		for _, selector := range selectors {
+			if selector.Metric == "" {
+				continue
+			}
+			metrics, ok := series[selector.Metric]
+			if !ok {
+				c.reportDiagnostic(diagnostics.Warning(query.Pos, diagnostics.CodeUndeclaredMetric,
+					"SLO %q %s references the metric %q, which isn't declared in the source code", query.SLO, query.Attribute, selector.Metric))
+				continue
+			}
+			for _, label := range selector.Labels {
+				if scrapeLabels[label] || hasLabel(metrics, label) {
+					continue
+				}
+				c.reportDiagnostic(diagnostics.Warning(query.Pos, diagnostics.CodeUndeclaredMetric,
+					"SLO %q %s references the label %q, which isn't declared by the metric %q", query.SLO, query.Attribute, label, selector.Metric))
+			}
+		}
+	}
+	c.queries = nil
+}
+
+// hasLabel returns true if any of the metrics exposing the same series can have the label
+func hasLabel(metrics []Metric, label string) bool {
+	for _, metric := range metrics {
+		if metric.hasLabel(label) {
+			return true
+		}
+	}
+	return false
+}
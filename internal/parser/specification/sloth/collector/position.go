@@ -0,0 +1,45 @@
+package collector
+
+import (
+	"go/token"
+
+	"github.com/slosive/sloscribe/internal/diagnostics"
+)
+
+// WithPositions sets the positions receiving the positions of the annotations declaring the collected SLOs,
+// these are added once the collector is finished
+func (c *Collector) WithPositions(positions *diagnostics.Positions) *Collector {
+	c.positions = positions
+	return c
+}
+
+// addPositions records the positions of the SLOs declared in a comment group, merged into the service specification
+func (c *Collector) addPositions(spec any, positions map[string]diagnostics.SLOPosition) {
+	if c.sloPositions[spec] == nil {
+		c.sloPositions[spec] = map[string]diagnostics.SLOPosition{}
+	}
+	for name, pos := range positions {
+		if _, ok := c.sloPositions[spec][name]; !ok {
+			c.sloPositions[spec][name] = pos
+		}
+	}
+}
+
+// addService records the position of a @sloth service annotation, before the service specifications are merged
+func (c *Collector) addService(service string, pos token.Position) {
+	if c.positions != nil {
+		c.positions.AddService(service, pos)
+	}
+}
+
+// finishPositions adds the positions of the SLOs of the collected service specifications to the positions set to the collector
+func (c *Collector) finishPositions() {
+	if c.positions == nil {
+		return
+	}
+	for service, spec := range c.specs {
+		for name, pos := range c.sloPositions[spec] {
+			c.positions.Add(service, name, pos)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package collector
+
+import (
+	"go/token"
+
+	k8sloth "github.com/slok/sloth/pkg/kubernetes/api/sloth/v1"
+	sloth "github.com/slok/sloth/pkg/prometheus/api/v1"
+	"github.com/slosive/sloscribe/internal/diagnostics"
+	"github.com/slosive/sloscribe/internal/parser/specification/sloth/grammar"
+)
+
+type (
+	// template is an SLO template together with the positions of its comment group, used to position its diagnostics
+	template struct {
+		*grammar.Template
+		fset  *token.FileSet
+		lines []token.Pos
+	}
+	// use is an SLO using a template, spec is the service specification of the SLO
+	use struct {
+		grammar.Use
+		spec any
+	}
+)
+
+// addTemplates collects the templates declared in a comment group, lines are the positions of the comment group text lines.
+// The templates with the same name as a collected one are reported and skipped.
+func (c *Collector) addTemplates(lines []token.Pos, templates ...*grammar.Template) {
+	for _, t := range templates {
+		t.Pos = position(c.fset, lines, t.Pos)
+		if existing, ok := c.templates[t.Name]; ok {
+			c.reportDiagnostic(diagnostics.New(t.Pos, diagnostics.CodeInvalidTemplate, "template %q is already declared at %s", t.Name, existing.Pos))
+			continue
+		}
+		c.templates[t.Name] = &template{Template: t, fset: c.fset, lines: lines}
+	}
+}
+
+// addUses collects the SLOs of the service specification using a template
+func (c *Collector) addUses(spec any, uses ...grammar.Use) {
+	for _, u := range uses {
+		c.uses = append(c.uses, use{Use: u, spec: spec})
+	}
+}
+
+// expand instantiates the templates used by the collected SLOs, the templates can be declared in any of the collected comment groups.
+// The values set by the SLO annotations take precedence over the template ones.
+func (c *Collector) expand() {
+	for _, u := range c.uses {
+		t, ok := c.templates[u.Template]
+		if !ok {
+			c.reportDiagnostic(diagnostics.New(u.Pos, diagnostics.CodeInvalidTemplate, "template %q used by SLO %q is not declared", u.Template, u.SLO))
+			continue
+		}
+		// the template metric references are replaced by the metrics of the SLO using it
+		evaluator := c.evaluator
+		evaluator.Metrics = u.Metrics
+		instance, err := evaluator.Instantiate(t.Template, u.Params)
+		if err != nil {
+			c.report(err, t.fset, t.lines)
+		}
+		if instance == nil {
+			continue
+		}
+
+		switch spec := u.spec.(type) {
+		case *sloth.Spec:
+			for i, slo := range spec.SLOs {
+				if slo.Name == u.SLO {
+					spec.SLOs[i] = overrideSLO(*instance, slo)
+				}
+			}
+		case *k8sloth.PrometheusServiceLevel:
+			slos := fromKubernetes(spec.Spec.SLOs...)
+			for i, slo := range slos {
+				if slo.Name == u.SLO {
+					slos[i] = overrideSLO(*instance, slo)
+				}
+			}
+			spec.Spec.SLOs = toKubernetes(slos...)
+		}
+	}
+	c.uses = nil
+}
@@ -0,0 +1,108 @@
+package collector
+
+import (
+	"go/ast"
+	"go/token"
+	"path/filepath"
+	"strings"
+
+	k8sloth "github.com/slok/sloth/pkg/kubernetes/api/sloth/v1"
+	sloth "github.com/slok/sloth/pkg/prometheus/api/v1"
+	"github.com/slosive/sloscribe/internal/diagnostics"
+	"github.com/slosive/sloscribe/internal/parser/specification/sloth/grammar"
+)
+
+// scope is the scope of the variables declared in a comment group: the service declared by the comment group,
+// or the package of the comment group if it doesn't declare a service. The zero scope is the package of the unpositioned comment groups
+type scope struct {
+	service string
+	pkg     string
+}
+
+// WithVariables sets the variables overriding the ones declared with @sloth.var, i.e: set from the command line
+func (c *Collector) WithVariables(overrides map[string]string) *Collector {
+	c.evaluator.Overrides = overrides
+	return c
+}
+
+// Declare scans the comment groups for the variables they declare, before these are collected.
+// Declaring the comment groups of all the source files first lets the annotations reference the variables declared in any of them,
+// Collect only declares the comment groups it is passed.
+func (c *Collector) Declare(fset *token.FileSet, comments ...*ast.CommentGroup) {
+	for _, comment := range comments {
+		if _, ok := c.declarations[comment]; ok {
+			continue
+		}
+		text, lines := c.commentText(comment)
+		if !strings.HasPrefix(text, "@sloth") {
+			continue
+		}
+		decls := grammar.Declare(text)
+		c.declarations[comment] = decls
+
+		s := scope{service: decls.Service}
+		if s.service == "" {
+			s.pkg = pkg(fset, comment)
+		}
+		if c.variables[s] == nil {
+			c.variables[s] = map[string]grammar.Variable{}
+		}
+		// the variables declared twice in the same comment group are reported when it is evaluated
+		declared := map[string]bool{}
+		for _, v := range decls.Variables {
+			if declared[v.Name] {
+				continue
+			}
+			declared[v.Name] = true
+			v.Pos = position(fset, lines, v.Pos)
+			prev, ok := c.variables[s][v.Name]
+			switch {
+			case !ok:
+				c.variables[s][v.Name] = v
+			case prev.Value != v.Value:
+				c.reportDiagnostic(diagnostics.New(v.Pos, diagnostics.CodeInvalidVariable,
+					"variable %q is already declared at %s with a different value", v.Name, prev.Pos))
+			}
+		}
+	}
+}
+
+// scopedVariables returns the variables in the scope of the comment group, the variables of its service take precedence
+// over the variables of its package. The comment groups without a service belong to the current service.
+func (c *Collector) scopedVariables(comment *ast.CommentGroup) map[string]string {
+	service := c.declarations[comment].Service
+	if service == "" {
+		service = currentService(c.current)
+	}
+
+	vars := map[string]string{}
+	for _, s := range []scope{{pkg: pkg(c.fset, comment)}, {service: service}} {
+		for name, v := range c.variables[s] {
+			vars[name] = v.Value
+		}
+	}
+	return vars
+}
+
+// currentService returns the service name of the specification
+func currentService(spec any) string {
+	switch s := spec.(type) {
+	case *sloth.Spec:
+		return s.Service
+	case *k8sloth.PrometheusServiceLevel:
+		return s.Spec.Service
+	}
+	return ""
+}
+
+// pkg returns the package of the comment group, the directory of its source file. It is empty if the comment groups aren't positioned
+func pkg(fset *token.FileSet, comment *ast.CommentGroup) string {
+	if fset == nil {
+		return ""
+	}
+	filename := fset.Position(comment.Pos()).Filename
+	if filename == "" {
+		return ""
+	}
+	return filepath.Dir(filename)
+}
@@ -0,0 +1,39 @@
+package grammar
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// constantReference matches the references to the constants of the annotated source code, i.e: {{go "AvailabilityTarget"}}
+var constantReference = regexp.MustCompile(`{{\s*go\s+"([^"]*)"\s*}}`)
+
+// resolveConstants returns the statement with the constant references of its value replaced by their value.
+// It returns an error if a constant can't be resolved.
+func (s *Statement) resolveConstants(resolve ConstantResolver) (*Statement, error) {
+	if !strings.Contains(string(s.Value), "{{") {
+		return s, nil
+	}
+	var problems []string
+	value := constantReference.ReplaceAllStringFunc(string(s.Value), func(match string) string {
+		name := constantReference.FindStringSubmatch(match)[1]
+		if resolve == nil {
+			problems = append(problems, errors.Errorf("Go constant %q can't be resolved outside of a Go source file", name).Error())
+			return match
+		}
+		value, err := resolve(name)
+		if err != nil {
+			problems = append(problems, err.Error())
+			return match
+		}
+		return value
+	})
+	if len(problems) > 0 {
+		return nil, errors.New(strings.Join(problems, ", "))
+	}
+	resolved := *s
+	resolved.Value = Text(value)
+	return &resolved, nil
+}
@@ -1,31 +1,62 @@
 package grammar
 
 import (
+	"go/token"
 	"reflect"
 	"strconv"
 	"strings"
 
 	"github.com/alecthomas/participle/v2"
+	"github.com/alecthomas/participle/v2/lexer"
 	"github.com/juju/errors"
 	sloth "github.com/slok/sloth/pkg/prometheus/api/v1"
+	"github.com/slosive/sloscribe/internal/diagnostics"
 )
 
 type (
 	// Grammar is the participle grammar use to parse the Sloth comment groups in source files
 	Grammar struct {
-		// Stmts is a list of Sloth grammar Statements
-		Stmts []*Statement `@@*`
+		// Stmts is a list of Sloth grammar Statements, the comment lines between them are skipped
+		Stmts []*Statement `parser:"(@@ | String | Whitespace | Continuation | Indent | EOL)*"`
 	}
 	// Statement is any comment starting with @sloth keyword
 	Statement struct {
-		Scope Scope  `@@`
-		Value string `Whitespace* @(String (Whitespace|EOL)*)+`
+		// Pos is the position of the statement in the parsed source
+		Pos   lexer.Position
+		Scope Scope `parser:"@@"`
+		// Value is the verbatim text following the statement attribute, up to the end of the line or the next statement
+		Value Text `parser:"(Whitespace|Continuation|Indent)* @String @(String|Whitespace|Continuation|Indent)*"`
 	}
+	// Text is a statement value spanning one or more lines.
+	// Lines ending with a backslash and indented lines continue the previous line,
+	// they are joined into a single line keeping their whitespaces.
+	Text string
 	// Scope defines the statement scope, similar to a code function
 	Scope struct {
 		// Type is the specification struct a statement refers to
-		Type  string `(Sloth @((".alerting"(".page"|".ticket")?|".sli"|".slo"))?)`
-		Value string `Whitespace* @("service"|"version"|"error_query"|"total_query"|"error_ratio_query"|"name"|"description"|"objective"|"labels"|"annotations"|"disable")`
+		Type string `parser:"Sloth (@Template|@(\".alerting.page\"|\".alerting.ticket\"|\".alerting\"|\".sli.plugin\"|\".sli\"|\".slo\"|\".var\"))?"`
+		// Value is the statement attribute, the attributes available in each scope are listed in attributes.
+		// The template scope has no attribute, the var scope attribute is the variable name.
+		Value string `parser:"Whitespace* @Attribute?"`
+	}
+	// QueryValidator validates the SLI queries of the statements, i.e: promql.Validate
+	QueryValidator func(query string) error
+	// ConstantResolver returns the value of a constant declared in the annotated source code, i.e: a Go const
+	ConstantResolver func(name string) (string, error)
+	// Evaluator evaluates the source input against the grammar.
+	// If ValidateQuery is not nil, the error_query, total_query and error_ratio_query values are validated with it.
+	// The ${name} references of the statement values are replaced by the Overrides, the variables declared in the source
+	// with @sloth.var, or the Variables, in this order. The {{go "Ident"}} references are then replaced by ResolveConstant.
+	Evaluator struct {
+		ValidateQuery QueryValidator
+		// ResolveConstant resolves the {{go "Ident"}} references, they can't be resolved if nil
+		ResolveConstant ConstantResolver
+		// Metrics are the metrics of the annotated source code, they replace the {{.metric}} and {{.metrics.<var>}} references of the SLI queries
+		Metrics Metrics
+		// Variables are the variables in the scope of the source, declared outside of it
+		Variables map[string]string
+		// Overrides are the variables taking precedence over the declared ones, i.e: set from the command line
+		Overrides map[string]string
 	}
 )
 
@@ -33,13 +64,78 @@ const (
 	sliErrorQueryAttr      = "error_query"
 	sliTotalQueryAttr      = "total_query"
 	sliErrorRatioQueryAttr = "error_ratio_query"
+	sliPluginAttr          = "plugin"
+	sliPluginOptionAttr    = "option"
+	sloNameAttr            = "name"
+	sloUseAttr             = "use"
+	serviceAttr            = "service"
+	templateScope          = ".template"
+	varScope               = ".var"
 )
 
+// attributes are the attributes available in each statement scope
+var attributes = map[string][]string{
+	"":                 {serviceAttr, "version", "labels"},
+	".slo":             {sloNameAttr, "description", "objective", "labels", sloUseAttr},
+	".sli":             {sliErrorQueryAttr, sliTotalQueryAttr, sliErrorRatioQueryAttr, sliPluginAttr},
+	".sli.plugin":      {sliPluginOptionAttr},
+	".alerting":        {"name", "labels", "annotations"},
+	".alerting.page":   {"disable", "labels", "annotations"},
+	".alerting.ticket": {"disable", "labels", "annotations"},
+	// the template scope has no attribute, its value is the template name
+	templateScope: {""},
+}
+
 var (
 	ErrMissingRequiredField = errors.New("missing required application field(s)")
 	ErrParseSource          = errors.New("error parsing source material")
 )
 
+// Capture joins the captured tokens into the statement value, dropping the line breaks of the continued lines
+func (t *Text) Capture(values []string) error {
+	for _, value := range values {
+		switch {
+		case strings.HasPrefix(value, `\`) && strings.ContainsAny(value, "\r\n"):
+			// Continuation, the line ends with a backslash
+			continue
+		case strings.ContainsAny(value[:1], "\r\n"):
+			// Indent, the next line is indented
+			value = strings.TrimLeft(value, "\r\n")
+		}
+		*t += Text(value)
+	}
+	return nil
+}
+
+// position returns the position of the statement in the parsed source
+func (s Statement) position() token.Position {
+	return position(s.Pos)
+}
+
+// invalid returns the diagnostic of the statement whose value couldn't be parsed
+func (s Statement) invalid(err error) diagnostics.Diagnostic {
+	return diagnostics.New(s.position(), diagnostics.CodeInvalidValue, "invalid @sloth%s %s value: %s", s.Scope.Type, s.Scope.Value, err)
+}
+
+// isAvailable returns true if the statement attribute is available in the statement scope
+func (s Statement) isAvailable() bool {
+	for _, attr := range attributes[s.Scope.Type] {
+		if strings.ToLower(s.Scope.Value) == attr {
+			return true
+		}
+	}
+	return false
+}
+
+func position(pos lexer.Position) token.Position {
+	return token.Position{
+		Filename: pos.Filename,
+		Offset:   pos.Offset,
+		Line:     pos.Line,
+		Column:   pos.Column,
+	}
+}
+
 // GetType returns the type of the statement scope
 func (k Scope) GetType() string {
 	return k.Type
@@ -62,19 +158,27 @@ func parseAndAssignStructFields(attr string, value string, fields []reflect.Stru
 					case reflect.Bool:
 						b, err := strconv.ParseBool(value)
 						if err != nil {
-							return err
+							return errors.Errorf("%q is not a boolean", value)
 						}
 						v.SetBool(b)
 					case reflect.Float64:
 						f, err := strconv.ParseFloat(value, 64)
 						if err != nil {
-							return err
+							return errors.Errorf("%q is not a number", value)
 						}
 						v.SetFloat(f)
 					case reflect.Map:
 						// label or annotation
-						m := strings.Split(value, " ")
-						v.SetMapIndex(reflect.ValueOf(m[0]), reflect.ValueOf(m[1]))
+						pairs, err := parseKeyValues(value)
+						if err != nil {
+							return err
+						}
+						if v.IsNil() {
+							v.Set(reflect.MakeMap(v.Type()))
+						}
+						for key, val := range pairs {
+							v.SetMapIndex(reflect.ValueOf(key), reflect.ValueOf(val))
+						}
 					default:
 						v.Set(reflect.ValueOf(value))
 					}
@@ -85,100 +189,343 @@ func parseAndAssignStructFields(attr string, value string, fields []reflect.Stru
 	return nil
 }
 
-func (g Grammar) parse() (*sloth.Spec, error) {
-	var spec = &sloth.Spec{
-		Version: sloth.Version,
-		Service: "",
-		Labels:  map[string]string{},
+// parseKeyValues parses the key value pairs of a map statement, i.e. labels or annotations.
+// The value is either a key followed by its value, i.e: summary Error budget burning fast,
+// or a list of key=value or key: value pairs whose values can be double-quoted, i.e: team=sre summary: "Error budget burning fast".
+func parseKeyValues(value string) (map[string]string, error) {
+	value = strings.TrimSpace(value)
+	end := strings.IndexAny(value, " \t=:")
+	switch {
+	case value == "":
+		return nil, errors.New("missing key value pair")
+	case end < 0:
+		return nil, errors.Errorf("missing value for key %q", value)
+	case end == 0:
+		return nil, errors.Errorf("missing key in %q", value)
+	case value[end] == ' ' || value[end] == '\t':
+		return map[string]string{value[:end]: strings.TrimSpace(value[end:])}, nil
 	}
-	var slo = &sloth.SLO{
-		Name:        "",
-		Description: "",
-		Objective:   0,
-		Labels:      map[string]string{},
-		SLI: sloth.SLI{
-			Raw:    nil,
-			Events: nil,
-			Plugin: nil,
+
+	pairs := map[string]string{}
+	for rest := value; rest != ""; rest = strings.TrimLeft(rest, " \t,") {
+		end := strings.IndexAny(rest, " \t=:")
+		if end <= 0 || rest[end] == ' ' || rest[end] == '\t' {
+			return nil, errors.Errorf("invalid key value pair %q, expected key=value or key: value", rest)
+		}
+		key := rest[:end]
+		rest = strings.TrimLeft(rest[end+1:], " \t")
+
+		if strings.HasPrefix(rest, `"`) {
+			quoted, err := strconv.QuotedPrefix(rest)
+			if err != nil {
+				return nil, errors.Errorf("invalid quoted value for key %q", key)
+			}
+			pairs[key], _ = strconv.Unquote(quoted)
+			rest = rest[len(quoted):]
+			continue
+		}
+
+		end = strings.IndexAny(rest, " \t,")
+		if end < 0 {
+			end = len(rest)
+		}
+		if end == 0 {
+			return nil, errors.Errorf("missing value for key %q", key)
+		}
+		pairs[key] = rest[:end]
+		rest = rest[end:]
+	}
+	return pairs, nil
+}
+
+// block is an SLO being parsed, each @sloth.slo name statement starts a new block
+// and the following .slo, .sli and .alerting statements are attached to it
+type block struct {
+	slo      *sloth.SLO
+	alerting *sloth.Alerting
+	page     *sloth.Alert
+	ticket   *sloth.Alert
+	// paged and ticketed are true if the block has page or ticket alert statements
+	paged, ticketed bool
+	// template is the SLO template declared by the block, its statements are evaluated when the template is used
+	template *Template
+	// use is the template instantiated by the SLO of the block
+	use *Use
+	// queries are the SLI queries of the block, the SLO name is set once the block is parsed
+	queries []Query
+	// pos is the position of the statements declaring the SLO of the block
+	pos diagnostics.SLOPosition
+	// invalid is true if an error was found in the SLO statements of the block, the SLO is skipped
+	invalid bool
+}
+
+func newBlock() *block {
+	return &block{
+		slo: &sloth.SLO{
+			Name:        "",
+			Description: "",
+			Objective:   0,
+			Labels:      map[string]string{},
+			SLI: sloth.SLI{
+				Raw:    nil,
+				Events: nil,
+				Plugin: nil,
+			},
+		},
+		alerting: &sloth.Alerting{
+			Name:        "",
+			Labels:      map[string]string{},
+			Annotations: map[string]string{},
+			PageAlert:   sloth.Alert{},
+			TicketAlert: sloth.Alert{},
+		},
+		page: &sloth.Alert{
+			Disable:     false,
+			Labels:      map[string]string{},
+			Annotations: map[string]string{},
+		},
+		ticket: &sloth.Alert{
+			Disable:     false,
+			Labels:      map[string]string{},
+			Annotations: map[string]string{},
 		},
 	}
-	alerting := &sloth.Alerting{
-		Name:        "",
-		Labels:      map[string]string{},
-		Annotations: map[string]string{},
-		PageAlert:   sloth.Alert{},
-		TicketAlert: sloth.Alert{},
+}
+
+// build returns the SLO of the block, the alerting is only set if it has a name
+func (b *block) build() sloth.SLO {
+	slo := *b.slo
+	if b.alerting.Name != "" {
+		slo.Alerting = *b.alerting
+	}
+	if b.paged {
+		slo.Alerting.PageAlert = *b.page
 	}
-	page := &sloth.Alert{
-		Disable:     false,
-		Labels:      map[string]string{},
-		Annotations: map[string]string{},
+	if b.ticketed {
+		slo.Alerting.TicketAlert = *b.ticket
 	}
-	ticket := &sloth.Alert{
-		Disable:     false,
-		Labels:      map[string]string{},
-		Annotations: map[string]string{},
+	return slo
+}
+
+// position records the position of the SLO name and of the first SLI statement of the block
+func (b *block) position(s *Statement) {
+	switch {
+	case s.Scope.Type == ".slo" && strings.ToLower(s.Scope.Value) == sloNameAttr && b.pos.SLO.Line == 0:
+		b.pos.SLO = s.position()
+	case strings.HasPrefix(s.Scope.Type, ".sli") && b.pos.SLI.Line == 0:
+		b.pos.SLI = s.position()
 	}
+}
 
-	for _, attr := range g.Stmts {
+// startsBlock returns true if the statement starts a new SLO or template block,
+// the statements before the first @sloth.slo name are attached to the first SLO
+func (s Statement) startsBlock(current *block) bool {
+	if s.Scope.Type == templateScope {
+		return true
+	}
+	return s.Scope.Type == ".slo" && strings.ToLower(s.Scope.Value) == sloNameAttr && (current.slo.Name != "" || current.template != nil)
+}
+
+func (g Grammar) parse(e Evaluator) (*Document, error) {
+	var spec = &sloth.Spec{
+		Version: sloth.Version,
+		Service: "",
+		Labels:  map[string]string{},
+	}
+	current := newBlock()
+	blocks := []*block{current}
+
+	// servicePos is the position of the last @sloth service statement, the one naming the spec
+	var servicePos token.Position
+	vars, diags := g.variables(e)
+	// checked is the number of diagnostics reported before the previous statement, which belongs to the current block
+	checked := len(diags)
+	var previous *Statement
+	invalidate := func() {
+		if previous != nil && previous.Scope.Type != "" && diags[checked:].HasErrors() {
+			current.invalid = true
+		}
+		checked = len(diags)
+	}
+	for _, stmt := range g.Stmts {
+		invalidate()
+		previous = stmt
+		if stmt.Scope.Type == varScope {
+			continue
+		}
+		attr, err := stmt.resolve(vars)
+		if err != nil {
+			diags = append(diags, diagnostics.New(stmt.position(), diagnostics.CodeInvalidVariable, "%s in @sloth%s %s", err, stmt.Scope.Type, stmt.Scope.Value))
+			continue
+		}
+		attr, err = attr.resolveConstants(e.ResolveConstant)
+		if err != nil {
+			diags = append(diags, diagnostics.New(stmt.position(), diagnostics.CodeUnresolvedConstant, "%s in @sloth%s %s", err, stmt.Scope.Type, stmt.Scope.Value))
+			continue
+		}
+		if !attr.isAvailable() {
+			diags = append(diags, diagnostics.Warning(attr.position(), diagnostics.CodeUnknownAttribute,
+				"unknown attribute %q in @sloth%s, the statement is ignored", attr.Scope.Value, attr.Scope.Type))
+			continue
+		}
+		if attr.startsBlock(current) {
+			current = newBlock()
+			blocks = append(blocks, current)
+		}
+		if attr.Scope.Type == templateScope {
+			current.template = &Template{Name: strings.TrimSpace(string(attr.Value)), Pos: attr.position(), pos: attr.Pos}
+			continue
+		}
+		// the template statements are evaluated once the template parameters are known, the service statements are not part of the template
+		if current.template != nil && attr.Scope.Type != "" {
+			current.template.statements = append(current.template.statements, attr)
+			continue
+		}
+		slo := current.slo
+		current.position(attr)
 		switch attr.Scope.GetType() {
 		case ".alerting.ticket":
-			fields := reflect.VisibleFields(reflect.TypeOf(*ticket))
-			pValue := reflect.ValueOf(ticket).Elem()
-			if err := parseAndAssignStructFields(strings.ToLower(attr.Scope.Value), strings.TrimSpace(attr.Value), fields, pValue); err == nil {
-				slo.Alerting.TicketAlert = *ticket
+			fields := reflect.VisibleFields(reflect.TypeOf(*current.ticket))
+			pValue := reflect.ValueOf(current.ticket).Elem()
+			if err := parseAndAssignStructFields(strings.ToLower(attr.Scope.Value), strings.TrimSpace(string(attr.Value)), fields, pValue); err != nil {
+				diags = append(diags, attr.invalid(err))
+				continue
 			}
+			current.ticketed = true
 		case ".alerting.page":
-			fields := reflect.VisibleFields(reflect.TypeOf(*page))
-			pValue := reflect.ValueOf(page).Elem()
-			if err := parseAndAssignStructFields(strings.ToLower(attr.Scope.Value), strings.TrimSpace(attr.Value), fields, pValue); err == nil {
-				slo.Alerting.PageAlert = *page
+			fields := reflect.VisibleFields(reflect.TypeOf(*current.page))
+			pValue := reflect.ValueOf(current.page).Elem()
+			if err := parseAndAssignStructFields(strings.ToLower(attr.Scope.Value), strings.TrimSpace(string(attr.Value)), fields, pValue); err != nil {
+				diags = append(diags, attr.invalid(err))
+				continue
 			}
+			current.paged = true
 		case ".alerting":
-			fields := reflect.VisibleFields(reflect.TypeOf(*alerting))
-			pValue := reflect.ValueOf(alerting).Elem()
-			if err := parseAndAssignStructFields(strings.ToLower(attr.Scope.Value), strings.TrimSpace(attr.Value), fields, pValue); err == nil && alerting.Name != "" {
-				slo.Alerting = *alerting
+			fields := reflect.VisibleFields(reflect.TypeOf(*current.alerting))
+			pValue := reflect.ValueOf(current.alerting).Elem()
+			if err := parseAndAssignStructFields(strings.ToLower(attr.Scope.Value), strings.TrimSpace(string(attr.Value)), fields, pValue); err != nil {
+				diags = append(diags, attr.invalid(err))
+				continue
 			}
 		case ".sli":
 			// SLI
+			if attr, err = attr.resolveMetrics(e.Metrics); err != nil {
+				diags = append(diags, diagnostics.New(stmt.position(), diagnostics.CodeUnresolvedMetric, "%s in @sloth.sli %s", err, stmt.Scope.Value))
+				continue
+			}
+			if e.ValidateQuery != nil && attr.Scope.Value != sliPluginAttr {
+				if err := e.ValidateQuery(strings.TrimSpace(string(attr.Value))); err != nil {
+					diags = append(diags, diagnostics.New(attr.position(), diagnostics.CodeInvalidQuery,
+						"invalid @sloth.sli %s PromQL query: %s", attr.Scope.Value, err))
+					continue
+				}
+			}
+			if attr.Scope.Value != sliPluginAttr {
+				current.queries = append(current.queries, Query{Attribute: attr.Scope.Value, Query: strings.TrimSpace(string(attr.Value)), Pos: attr.position()})
+			}
 			switch attr.Scope.Value {
 			case sliTotalQueryAttr:
 				if slo.SLI.Events == nil {
 					slo.SLI.Events = &sloth.SLIEvents{}
 				}
-				slo.SLI.Events.TotalQuery = strings.TrimSpace(attr.Value)
+				slo.SLI.Events.TotalQuery = strings.TrimSpace(string(attr.Value))
 			case sliErrorQueryAttr:
 				if slo.SLI.Events == nil {
 					slo.SLI.Events = &sloth.SLIEvents{}
 				}
-				slo.SLI.Events.ErrorQuery = strings.TrimSpace(attr.Value)
+				slo.SLI.Events.ErrorQuery = strings.TrimSpace(string(attr.Value))
 			case sliErrorRatioQueryAttr:
 				if slo.SLI.Raw == nil {
 					slo.SLI.Raw = &sloth.SLIRaw{}
 				}
-				slo.SLI.Raw.ErrorRatioQuery = strings.TrimSpace(attr.Value)
+				slo.SLI.Raw.ErrorRatioQuery = strings.TrimSpace(string(attr.Value))
+			case sliPluginAttr:
+				if slo.SLI.Plugin == nil {
+					slo.SLI.Plugin = &sloth.SLIPlugin{Options: map[string]string{}}
+				}
+				slo.SLI.Plugin.ID = strings.TrimSpace(string(attr.Value))
+			}
+		case ".sli.plugin":
+			options, err := parseKeyValues(string(attr.Value))
+			if err != nil {
+				diags = append(diags, attr.invalid(err))
+				continue
+			}
+			if slo.SLI.Plugin == nil {
+				slo.SLI.Plugin = &sloth.SLIPlugin{Options: map[string]string{}}
+			}
+			for key, value := range options {
+				slo.SLI.Plugin.Options[key] = value
 			}
 		case ".slo":
+			if strings.ToLower(attr.Scope.Value) == sloUseAttr {
+				use, err := parseUse(string(attr.Value))
+				if err != nil {
+					diags = append(diags, attr.invalid(err))
+					continue
+				}
+				use.Pos = attr.position()
+				current.use = use
+				continue
+			}
 			fields := reflect.VisibleFields(reflect.TypeOf(*slo))
 			pValue := reflect.ValueOf(slo).Elem()
-			if err := parseAndAssignStructFields(strings.ToLower(attr.Scope.Value), strings.TrimSpace(attr.Value), fields, pValue); err != nil {
+			if err := parseAndAssignStructFields(strings.ToLower(attr.Scope.Value), strings.TrimSpace(string(attr.Value)), fields, pValue); err != nil {
+				diags = append(diags, attr.invalid(err))
 				continue
 			}
 		default:
 			fields := reflect.VisibleFields(reflect.TypeOf(*spec))
 			pValue := reflect.ValueOf(spec).Elem()
-			if err := parseAndAssignStructFields(strings.ToLower(attr.Scope.Value), strings.TrimSpace(attr.Value), fields, pValue); err != nil {
+			if err := parseAndAssignStructFields(strings.ToLower(attr.Scope.Value), strings.TrimSpace(string(attr.Value)), fields, pValue); err != nil {
+				diags = append(diags, attr.invalid(err))
 				continue
 			}
+			if strings.ToLower(attr.Scope.Value) == serviceAttr {
+				servicePos = attr.position()
+			}
 		}
 	}
 
-	if slo.Name != "" {
-		spec.SLOs = []sloth.SLO{*slo}
+	invalidate()
+
+	doc := &Document{Spec: spec, Positions: map[string]diagnostics.SLOPosition{}, ServicePos: servicePos}
+	for _, b := range blocks {
+		if b.invalid {
+			// the errors are reported, the other SLOs of the comment group are kept
+			continue
+		}
+		if b.template != nil {
+			doc.Templates = append(doc.Templates, b.template)
+			continue
+		}
+		if b.use != nil {
+			if b.slo.Name == "" {
+				diags = append(diags, diagnostics.New(b.use.Pos, diagnostics.CodeInvalidTemplate, "template %q is used by an SLO without a name", b.use.Template))
+				continue
+			}
+			b.use.SLO = b.slo.Name
+			b.use.Metrics = e.Metrics
+			doc.Uses = append(doc.Uses, *b.use)
+		}
+		if b.slo.Name != "" {
+			if b.pos.SLI.Line == 0 {
+				b.pos.SLI = b.pos.SLO
+			}
+			doc.Positions[b.slo.Name] = b.pos
+			spec.SLOs = append(spec.SLOs, b.build())
+			for _, query := range b.queries {
+				query.SLO = b.slo.Name
+				doc.Queries = append(doc.Queries, query)
+			}
+		}
 	}
 
-	return spec, nil
+	// the statements with errors are skipped, the rest of the comment group is returned together with the diagnostics
+	if len(diags) > 0 {
+		return doc, diags
+	}
+	return doc, nil
 }
 
 func createGrammar(filename, source string, options ...participle.ParseOption) (*Grammar, error) {
@@ -192,16 +539,33 @@ func createGrammar(filename, source string, options ...participle.ParseOption) (
 	return ast.ParseString(filename, source, options...)
 }
 
-// Eval evaluates the source input against the grammar and returns an instance of *sloth.spec
+// Eval evaluates the source input against the grammar and returns an instance of *sloth.spec.
+// The problems found in the source are returned as a diagnostics.List, positioned in the source.
+// The statements with problems are skipped, the spec is returned together with the diagnostics unless the source has a syntax error.
 func Eval(source string, options ...participle.ParseOption) (*sloth.Spec, error) {
+	return Evaluator{}.Eval(source, options...)
+}
+
+// Eval evaluates the source input against the grammar, like Eval, validating the SLI queries of the statements
+func (e Evaluator) Eval(source string, options ...participle.ParseOption) (*sloth.Spec, error) {
+	doc, err := e.EvalDocument(source, options...)
+	if doc == nil {
+		return nil, err
+	}
+	return doc.Spec, err
+}
+
+// EvalDocument evaluates the source input against the grammar, like Eval,
+// returning the SLO templates declared in the source and their uses together with the spec
+func (e Evaluator) EvalDocument(source string, options ...participle.ParseOption) (*Document, error) {
 	grammar, err := createGrammar("", source, options...)
 	if err != nil {
+		var syntaxErr participle.Error
+		if errors.As(err, &syntaxErr) {
+			return nil, diagnostics.List{diagnostics.New(position(syntaxErr.Position()), diagnostics.CodeSyntaxError, "%s", syntaxErr.Message())}
+		}
 		return nil, err
 	}
 
-	// Spec
-	s, _ := grammar.parse()
-	newSpec := *s
-
-	return &newSpec, nil
+	return grammar.parse(e)
 }
@@ -1,9 +1,14 @@
 package grammar
 
 import (
+	"strings"
+	"testing"
+
+	"github.com/juju/errors"
+	sloth "github.com/slok/sloth/pkg/prometheus/api/v1"
+	"github.com/slosive/sloscribe/internal/diagnostics"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"testing"
 )
 
 func TestGrammar(t *testing.T) {
@@ -12,17 +17,14 @@ func TestGrammar(t *testing.T) {
 	t.Run("Successfully parse sloth definitions for slo description in multiline comment", func(t *testing.T) {
 		spec, err := Eval(`@sloth.slo name common
 @sloth.slo description Common SLO
-based on availability
-for Kubernetes apiserver
-HTTP request responses`)
+ based on availability
+ for Kubernetes apiserver
+ HTTP request responses`)
 		require.NoError(t, err)
 		require.NotEmpty(t, spec.SLOs)
 		require.Len(t, spec.SLOs, 1)
 
-		assert.EqualValues(t, `Common SLO
-based on availability
-for Kubernetes apiserver
-HTTP request responses`, spec.SLOs[0].Description)
+		assert.EqualValues(t, `Common SLO based on availability for Kubernetes apiserver HTTP request responses`, spec.SLOs[0].Description)
 	})
 
 	t.Run("Fail to parse sloth definitions for slo name with empty value", func(t *testing.T) {
@@ -137,4 +139,520 @@ HTTP request responses`, spec.SLOs[0].Description)
 		assert.EqualValues(t, map[string]string{"test": "value", "test1": "value"}, spec.SLOs[0].Alerting.TicketAlert.Labels)
 		assert.EqualValues(t, map[string]string{"test": "value", "test1": "value"}, spec.SLOs[0].Alerting.TicketAlert.Annotations)
 	})
+
+	t.Run("Successfully parse sloth definitions for an error query with the full promql charset", func(t *testing.T) {
+		spec, err := Eval(`@sloth.slo name requests_availability
+@sloth.sli error_query sum(rate(http_requests_total{code!="200",path!~"/health.*"}[{{.window}}])) * 100 / 5 > 0.5 ^ 2
+@sloth.sli total_query sum(rate(http_requests_total{route=~"/api/(v1|v2)/.+", tenant="ünïcode#1;?"}[{{.window}}]))
+`)
+		require.NoError(t, err)
+		require.Len(t, spec.SLOs, 1)
+		require.NotNil(t, spec.SLOs[0].SLI.Events)
+
+		assert.EqualValues(t, `sum(rate(http_requests_total{code!="200",path!~"/health.*"}[{{.window}}])) * 100 / 5 > 0.5 ^ 2`, spec.SLOs[0].SLI.Events.ErrorQuery)
+		assert.EqualValues(t, `sum(rate(http_requests_total{route=~"/api/(v1|v2)/.+", tenant="ünïcode#1;?"}[{{.window}}]))`, spec.SLOs[0].SLI.Events.TotalQuery)
+	})
+
+	t.Run("Successfully parse sloth definitions for an error ratio query on indented lines", func(t *testing.T) {
+		spec, err := Eval(`@sloth.slo name latency
+@sloth.sli error_ratio_query 1 -	(
+  sum(rate(http_request_duration_seconds_bucket{le="0.25", code!~"5.."}[{{.window}}]))
+  /
+  sum(rate(http_request_duration_seconds_count{code!~"5.."}[{{.window}}]))
+ )
+@sloth.slo objective 99`)
+		require.NoError(t, err)
+		require.Len(t, spec.SLOs, 1)
+		require.NotNil(t, spec.SLOs[0].SLI.Raw)
+
+		assert.EqualValues(t, `1 -	(  sum(rate(http_request_duration_seconds_bucket{le="0.25", code!~"5.."}[{{.window}}]))  /  sum(rate(http_request_duration_seconds_count{code!~"5.."}[{{.window}}])) )`, spec.SLOs[0].SLI.Raw.ErrorRatioQuery)
+		assert.EqualValues(t, 99, spec.SLOs[0].Objective)
+	})
+
+	t.Run("Successfully parse sloth definitions for queries continued by a trailing backslash", func(t *testing.T) {
+		spec, err := Eval(`@sloth.slo name availability
+@sloth.sli error_query sum(rate(http_requests_total{code=~"5..", path!~"\\/health"}[{{.window}}])) \
+OR on() vector(0)
+@sloth.sli total_query \
+sum(rate(http_requests_total[{{.window}}]))`)
+		require.NoError(t, err)
+		require.Len(t, spec.SLOs, 1)
+		require.NotNil(t, spec.SLOs[0].SLI.Events)
+
+		assert.EqualValues(t, `sum(rate(http_requests_total{code=~"5..", path!~"\\/health"}[{{.window}}])) OR on() vector(0)`, spec.SLOs[0].SLI.Events.ErrorQuery)
+		assert.EqualValues(t, `sum(rate(http_requests_total[{{.window}}]))`, spec.SLOs[0].SLI.Events.TotalQuery)
+	})
+
+	t.Run("Successfully parse sloth definitions for a query followed by a comment line", func(t *testing.T) {
+		spec, err := Eval(`@sloth.slo name availability
+@sloth.sli error_query sum(rate(x[{{.window}}]))
+@sloth.sli total_query sum(rate(y[{{.window}}]))
+This is a normal description line
+@sloth.slo objective 99`)
+		require.NoError(t, err)
+		require.Len(t, spec.SLOs, 1)
+		require.NotNil(t, spec.SLOs[0].SLI.Events)
+
+		assert.EqualValues(t, `sum(rate(x[{{.window}}]))`, spec.SLOs[0].SLI.Events.ErrorQuery)
+		assert.EqualValues(t, `sum(rate(y[{{.window}}]))`, spec.SLOs[0].SLI.Events.TotalQuery)
+		assert.EqualValues(t, 99, spec.SLOs[0].Objective)
+	})
+
+	t.Run("Successfully parse sloth definitions for a query starting on an indented line", func(t *testing.T) {
+		spec, err := Eval(`@sloth.slo name availability
+@sloth.sli error_ratio_query
+    sum(rate(http_requests_total{code=~"5.."}[{{.window}}]))
+    / sum(rate(http_requests_total[{{.window}}]))`)
+		require.NoError(t, err)
+		require.Len(t, spec.SLOs, 1)
+		require.NotNil(t, spec.SLOs[0].SLI.Raw)
+
+		assert.EqualValues(t, `sum(rate(http_requests_total{code=~"5.."}[{{.window}}]))    / sum(rate(http_requests_total[{{.window}}]))`, spec.SLOs[0].SLI.Raw.ErrorRatioQuery)
+	})
+
+	t.Run("Successfully parse sloth definitions for queries with modifiers and the at symbol", func(t *testing.T) {
+		spec, err := Eval(`@sloth.slo name availability
+@sloth.sli error_query sum(increase(grpc_server_handled_total{grpc_code!="OK"}[{{.window}}] offset 1m @ 1609746000)) OR on() vector(0)
+@sloth.sli total_query sum(increase(grpc_server_handled_total[{{.window}}] offset 1m @ 1609746000))
+@sloth.slo description Mail foo@example.com when 99.9% of requests < 300ms!`)
+		require.NoError(t, err)
+		require.Len(t, spec.SLOs, 1)
+
+		assert.EqualValues(t, `sum(increase(grpc_server_handled_total{grpc_code!="OK"}[{{.window}}] offset 1m @ 1609746000)) OR on() vector(0)`, spec.SLOs[0].SLI.Events.ErrorQuery)
+		assert.EqualValues(t, `sum(increase(grpc_server_handled_total[{{.window}}] offset 1m @ 1609746000))`, spec.SLOs[0].SLI.Events.TotalQuery)
+		assert.EqualValues(t, `Mail foo@example.com when 99.9% of requests < 300ms!`, spec.SLOs[0].Description)
+	})
+
+	t.Run("Successfully parse sloth definitions for a query followed by a statement on the same line", func(t *testing.T) {
+		spec, err := Eval(`@sloth.slo name availability @sloth.sli error_query sum(rate(http_requests_total{code=~"5.."}[{{.window}}])) @sloth.sli total_query sum(rate(http_requests_total[{{.window}}]))`)
+		require.NoError(t, err)
+		require.Len(t, spec.SLOs, 1)
+
+		assert.EqualValues(t, "availability", spec.SLOs[0].Name)
+		assert.EqualValues(t, `sum(rate(http_requests_total{code=~"5.."}[{{.window}}]))`, spec.SLOs[0].SLI.Events.ErrorQuery)
+		assert.EqualValues(t, `sum(rate(http_requests_total[{{.window}}]))`, spec.SLOs[0].SLI.Events.TotalQuery)
+	})
+
+	t.Run("Fail to parse sloth definitions for an error query with a blank value", func(t *testing.T) {
+		_, err := Eval("@sloth.sli error_query \t ")
+		require.Error(t, err)
+	})
+
+	t.Run("Fail to parse sloth definitions for an unknown scope", func(t *testing.T) {
+		_, err := Eval(`@sloth.slos name availability`)
+		require.Error(t, err)
+	})
+
+	t.Run("Successfully parse sloth definitions for a plugin SLI", func(t *testing.T) {
+		spec, err := Eval(`@sloth.slo name availability
+@sloth.sli plugin sloth-common/kubernetes/apiserver/availability
+@sloth.sli.plugin option filter verb!="WATCH", job="apiserver"
+@sloth.sli.plugin option window 5m
+`)
+		require.NoError(t, err)
+		require.Len(t, spec.SLOs, 1)
+		require.NotNil(t, spec.SLOs[0].SLI.Plugin)
+
+		assert.Nil(t, spec.SLOs[0].SLI.Events)
+		assert.Nil(t, spec.SLOs[0].SLI.Raw)
+		assert.EqualValues(t, &sloth.SLIPlugin{
+			ID: "sloth-common/kubernetes/apiserver/availability",
+			Options: map[string]string{
+				"filter": `verb!="WATCH", job="apiserver"`,
+				"window": "5m",
+			},
+		}, spec.SLOs[0].SLI.Plugin)
+	})
+
+	t.Run("Successfully parse sloth definitions for plugin options declared before the plugin id", func(t *testing.T) {
+		spec, err := Eval(`@sloth.slo name availability
+@sloth.sli.plugin option filter job="apiserver"
+@sloth.sli plugin sloth-common/kubernetes/apiserver/availability
+`)
+		require.NoError(t, err)
+		require.Len(t, spec.SLOs, 1)
+		require.NotNil(t, spec.SLOs[0].SLI.Plugin)
+
+		assert.EqualValues(t, "sloth-common/kubernetes/apiserver/availability", spec.SLOs[0].SLI.Plugin.ID)
+		assert.EqualValues(t, map[string]string{"filter": `job="apiserver"`}, spec.SLOs[0].SLI.Plugin.Options)
+	})
+
+	t.Run("Fail to parse sloth definitions for a plugin option in the sli scope", func(t *testing.T) {
+		_, err := Eval(`@sloth.sli.plugin filter job="apiserver"`)
+		require.Error(t, err)
+	})
+
+	t.Run("Successfully parse sloth definitions for labels and annotations with spaced and quoted values", func(t *testing.T) {
+		spec, err := Eval(`@sloth service test-service
+@sloth labels team=sre tier=1
+@sloth.slo name requests_availability
+@sloth.slo labels owner: "platform team", component=api
+@sloth.alerting name K8sApiserverAvailabilityAlert
+@sloth.alerting annotations summary Error budget burning fast
+@sloth.alerting labels app.kubernetes.io/name=apiserver
+@sloth.alerting.page annotations runbook: "https://runbooks.example.com/apiserver#availability" severity=critical
+@sloth.alerting.page labels routing_key on-call
+@sloth.alerting.ticket annotations description: "{{$labels.sloth_service}} is \"burning\" its budget"
+@sloth.alerting.ticket labels severity: warning
+`)
+		require.NoError(t, err)
+		require.Len(t, spec.SLOs, 1)
+
+		assert.EqualValues(t, map[string]string{"team": "sre", "tier": "1"}, spec.Labels)
+		assert.EqualValues(t, map[string]string{"owner": "platform team", "component": "api"}, spec.SLOs[0].Labels)
+		assert.EqualValues(t, map[string]string{"summary": "Error budget burning fast"}, spec.SLOs[0].Alerting.Annotations)
+		assert.EqualValues(t, map[string]string{"app.kubernetes.io/name": "apiserver"}, spec.SLOs[0].Alerting.Labels)
+		assert.EqualValues(t, map[string]string{
+			"runbook":  "https://runbooks.example.com/apiserver#availability",
+			"severity": "critical",
+		}, spec.SLOs[0].Alerting.PageAlert.Annotations)
+		assert.EqualValues(t, map[string]string{"routing_key": "on-call"}, spec.SLOs[0].Alerting.PageAlert.Labels)
+		assert.EqualValues(t, map[string]string{"description": `{{$labels.sloth_service}} is "burning" its budget`}, spec.SLOs[0].Alerting.TicketAlert.Annotations)
+		assert.EqualValues(t, map[string]string{"severity": "warning"}, spec.SLOs[0].Alerting.TicketAlert.Labels)
+	})
+
+	t.Run("Fail to parse sloth definitions for malformed labels and annotations", func(t *testing.T) {
+		for _, statement := range []string{
+			`@sloth labels team`,
+			`@sloth.slo labels =sre`,
+			`@sloth.slo labels team=`,
+			`@sloth.alerting labels team=sre tier`,
+			`@sloth.alerting annotations summary: "Error budget burning fast`,
+			`@sloth.alerting.page labels team=sre tier 1`,
+			`@sloth.alerting.ticket annotations summary:`,
+			`@sloth.sli.plugin option filter`,
+		} {
+			_, err := Eval("@sloth.slo name requests_availability\n" + statement)
+			assert.Error(t, err, statement)
+		}
+	})
+
+	t.Run("Fail to parse sloth definitions, returning the diagnostics at the statements position", func(t *testing.T) {
+		_, err := Eval(`@sloth.slo name availability
+@sloth.slo objective high
+  @sloth.alerting.page disable maybe`)
+		require.Error(t, err)
+
+		var diags diagnostics.List
+		require.ErrorAs(t, err, &diags)
+		require.Len(t, diags, 2)
+		assert.Equal(t, diagnostics.CodeInvalidValue, diags[0].Code)
+		assert.Equal(t, diagnostics.SeverityError, diags[0].Severity)
+		assert.Equal(t, "2:1", diags[0].Pos.String())
+		assert.Equal(t, diagnostics.CodeInvalidValue, diags[1].Code)
+		assert.Equal(t, "3:3", diags[1].Pos.String())
+	})
+
+	t.Run("Fail to parse sloth definitions with a syntax error, returning its diagnostic", func(t *testing.T) {
+		_, err := Eval(`@sloth.slo name availability
+@sloth.slo objective`)
+		require.Error(t, err)
+
+		var diags diagnostics.List
+		require.ErrorAs(t, err, &diags)
+		require.Len(t, diags, 1)
+		assert.Equal(t, diagnostics.CodeSyntaxError, diags[0].Code)
+		assert.Equal(t, 2, diags[0].Pos.Line)
+	})
+
+	t.Run("Successfully parse sloth definitions with an unknown attribute, returning the spec and a warning", func(t *testing.T) {
+		spec, err := Eval(`@sloth.slo name availability
+@sloth.sli objective 99.9`)
+		require.NotNil(t, spec)
+		require.Len(t, spec.SLOs, 1)
+		assert.Equal(t, "availability", spec.SLOs[0].Name)
+
+		var diags diagnostics.List
+		require.ErrorAs(t, err, &diags)
+		require.Len(t, diags, 1)
+		assert.Equal(t, diagnostics.CodeUnknownAttribute, diags[0].Code)
+		assert.Equal(t, diagnostics.SeverityWarning, diags[0].Severity)
+		assert.Equal(t, "2:1", diags[0].Pos.String())
+	})
+	t.Run("Fail to evaluate sloth definitions with an invalid query, returning the diagnostic at the statement position", func(t *testing.T) {
+		evaluator := Evaluator{ValidateQuery: func(query string) error {
+			if strings.Contains(query, "{{.window}}") {
+				return nil
+			}
+			return errors.New("missing window")
+		}}
+		spec, err := evaluator.Eval(`@sloth.slo name availability
+@sloth.sli error_query sum(rate(http_requests_total{code=~"5.."}[{{.window}}]))
+@sloth.sli total_query sum(rate(http_requests_total[5m]))
+@sloth.sli plugin sloth-common/kubernetes/apiserver/availability`)
+		require.NotNil(t, spec)
+		assert.Empty(t, spec.SLOs)
+
+		var diags diagnostics.List
+		require.ErrorAs(t, err, &diags)
+		require.Len(t, diags, 1)
+		assert.Equal(t, diagnostics.CodeInvalidQuery, diags[0].Code)
+		assert.Equal(t, "3:1", diags[0].Pos.String())
+		assert.Equal(t, "invalid @sloth.sli total_query PromQL query: missing window", diags[0].Message)
+	})
+	t.Run("Successfully parse sloth definitions for several SLO blocks in the same comment group", func(t *testing.T) {
+		spec, err := Eval(`@sloth.slo objective 99.9
+@sloth.slo name requests-availability
+@sloth.sli error_query sum(rate(http_requests_total{code=~"5.."}[{{.window}}]))
+@sloth.sli total_query sum(rate(http_requests_total[{{.window}}]))
+@sloth.alerting.page disable true
+@sloth.alerting name RequestsAvailability
+@sloth.slo name requests-latency
+@sloth.slo objective 99
+@sloth.sli error_ratio_query sum(rate(http_slow_requests_ratio[{{.window}}]))`)
+		require.NoError(t, err)
+		require.Len(t, spec.SLOs, 2)
+
+		availability := spec.SLOs[0]
+		assert.Equal(t, "requests-availability", availability.Name)
+		assert.Equal(t, 99.9, availability.Objective)
+		require.NotNil(t, availability.SLI.Events)
+		assert.Nil(t, availability.SLI.Raw)
+		assert.Equal(t, "RequestsAvailability", availability.Alerting.Name)
+		assert.True(t, availability.Alerting.PageAlert.Disable)
+
+		latency := spec.SLOs[1]
+		assert.Equal(t, "requests-latency", latency.Name)
+		assert.Equal(t, 99.0, latency.Objective)
+		assert.Nil(t, latency.SLI.Events)
+		require.NotNil(t, latency.SLI.Raw)
+		assert.Equal(t, sloth.Alerting{}, latency.Alerting)
+	})
+
+	t.Run("Successfully parse the other SLO blocks of a comment group with an invalid statement, returning the spec and the diagnostic", func(t *testing.T) {
+		spec, err := Eval(`@sloth service chatgpt
+@sloth.slo name requests-availability
+@sloth.slo objective ninety-nine
+@sloth.sli error_ratio_query sum(rate(http_errors_ratio[{{.window}}]))
+@sloth.slo name requests-latency
+@sloth.slo objective 99
+@sloth.sli error_ratio_query sum(rate(http_slow_requests_ratio[{{.window}}]))`)
+		require.NotNil(t, spec)
+		assert.Equal(t, "chatgpt", spec.Service)
+		require.Len(t, spec.SLOs, 1)
+		assert.Equal(t, "requests-latency", spec.SLOs[0].Name)
+		assert.Equal(t, 99.0, spec.SLOs[0].Objective)
+
+		var diags diagnostics.List
+		require.ErrorAs(t, err, &diags)
+		require.Len(t, diags, 1)
+		assert.Equal(t, diagnostics.CodeInvalidValue, diags[0].Code)
+		assert.Equal(t, "3:1", diags[0].Pos.String())
+	})
+}
+
+func TestTemplate(t *testing.T) {
+	t.Parallel()
+
+	const source = `@sloth.template http-availability
+@sloth.slo objective 99.9
+@sloth.slo description {{.handler}} availability
+@sloth.sli error_query sum(rate(http_requests_total{handler="{{.handler}}",code=~"5.."}[{{.window}}]))
+@sloth.sli total_query sum(rate(http_requests_total{handler="{{.handler}}"}[{{.window}}]))
+@sloth.alerting name {{.alert}}
+@sloth.slo name checkout-availability
+@sloth.slo use http-availability handler=/checkout alert=CheckoutAvailability
+@sloth.slo objective 99`
+
+	t.Run("Successfully parse the templates and the SLOs using them", func(t *testing.T) {
+		doc, err := Evaluator{}.EvalDocument(source)
+		require.NoError(t, err)
+
+		require.Len(t, doc.Templates, 1)
+		assert.Equal(t, "http-availability", doc.Templates[0].Name)
+		assert.Equal(t, "1:1", doc.Templates[0].Pos.String())
+
+		require.Len(t, doc.Spec.SLOs, 1)
+		assert.Equal(t, "checkout-availability", doc.Spec.SLOs[0].Name)
+		assert.Equal(t, 99.0, doc.Spec.SLOs[0].Objective)
+
+		require.Len(t, doc.Uses, 1)
+		assert.Equal(t, "checkout-availability", doc.Uses[0].SLO)
+		assert.Equal(t, "http-availability", doc.Uses[0].Template)
+		assert.Equal(t, map[string]string{"handler": "/checkout", "alert": "CheckoutAvailability"}, doc.Uses[0].Params)
+		assert.Equal(t, "8:1", doc.Uses[0].Pos.String())
+	})
+
+	t.Run("Successfully instantiate a template with the parameters, keeping the window template", func(t *testing.T) {
+		doc, err := Evaluator{}.EvalDocument(source)
+		require.NoError(t, err)
+
+		slo, err := Evaluator{}.Instantiate(doc.Templates[0], doc.Uses[0].Params)
+		require.NoError(t, err)
+		assert.Empty(t, slo.Name)
+		assert.Equal(t, 99.9, slo.Objective)
+		assert.Equal(t, "/checkout availability", slo.Description)
+		require.NotNil(t, slo.SLI.Events)
+		assert.Equal(t, `sum(rate(http_requests_total{handler="/checkout",code=~"5.."}[{{.window}}]))`, slo.SLI.Events.ErrorQuery)
+		assert.Equal(t, "CheckoutAvailability", slo.Alerting.Name)
+	})
+
+	t.Run("Fail to instantiate a template with a missing parameter, returning the diagnostic at the statement position", func(t *testing.T) {
+		doc, err := Evaluator{}.EvalDocument(source)
+		require.NoError(t, err)
+
+		slo, err := Evaluator{}.Instantiate(doc.Templates[0], map[string]string{"handler": "/checkout"})
+		assert.Nil(t, slo)
+
+		var diags diagnostics.List
+		require.ErrorAs(t, err, &diags)
+		require.Len(t, diags, 1)
+		assert.Equal(t, diagnostics.CodeInvalidTemplate, diags[0].Code)
+		assert.Equal(t, "6:1", diags[0].Pos.String())
+		assert.Equal(t, `template "http-availability": missing template parameter(s) ["alert"]`, diags[0].Message)
+	})
+
+	t.Run("Fail to parse a template use on an SLO without a name", func(t *testing.T) {
+		doc, err := Evaluator{}.EvalDocument(`@sloth.slo use http-availability handler=/checkout`)
+		require.NotNil(t, doc)
+		assert.Empty(t, doc.Uses)
+
+		var diags diagnostics.List
+		require.ErrorAs(t, err, &diags)
+		require.Len(t, diags, 1)
+		assert.Equal(t, diagnostics.CodeInvalidTemplate, diags[0].Code)
+	})
+}
+
+func TestVariables(t *testing.T) {
+	t.Parallel()
+
+	const source = `@sloth service chatgpt
+@sloth.var namespace chatgpt
+@sloth.var objective_tier1 99.9
+@sloth.slo name availability
+@sloth.slo objective ${objective_tier1}
+@sloth.slo labels namespace=${namespace} team=${team}
+@sloth.sli error_ratio_query sum(rate(${namespace}_errors_total[{{.window}}]))`
+
+	t.Run("Successfully replace the variable references before the values are assigned", func(t *testing.T) {
+		spec, err := Evaluator{Variables: map[string]string{"team": "sre"}}.Eval(source)
+		require.NoError(t, err)
+		require.Len(t, spec.SLOs, 1)
+		assert.Equal(t, 99.9, spec.SLOs[0].Objective)
+		assert.Equal(t, map[string]string{"namespace": "chatgpt", "team": "sre"}, spec.SLOs[0].Labels)
+		assert.Equal(t, "sum(rate(chatgpt_errors_total[{{.window}}]))", spec.SLOs[0].SLI.Raw.ErrorRatioQuery)
+	})
+
+	t.Run("Successfully override the declared variables", func(t *testing.T) {
+		spec, err := Evaluator{
+			Variables: map[string]string{"team": "sre", "namespace": "ignored"},
+			Overrides: map[string]string{"namespace": "openai"},
+		}.Eval(source)
+		require.NoError(t, err)
+		assert.Equal(t, "sum(rate(openai_errors_total[{{.window}}]))", spec.SLOs[0].SLI.Raw.ErrorRatioQuery)
+	})
+
+	t.Run("Fail to evaluate a statement referencing an undefined variable, returning the diagnostic at the statement position", func(t *testing.T) {
+		spec, err := Eval(source)
+		require.NotNil(t, spec)
+		assert.Empty(t, spec.SLOs)
+
+		var diags diagnostics.List
+		require.ErrorAs(t, err, &diags)
+		require.Len(t, diags, 1)
+		assert.Equal(t, diagnostics.CodeInvalidVariable, diags[0].Code)
+		assert.Equal(t, "6:1", diags[0].Pos.String())
+		assert.Equal(t, `undefined variable(s) ["team"] in @sloth.slo labels`, diags[0].Message)
+	})
+
+	t.Run("Fail to evaluate a variable declared twice with different values", func(t *testing.T) {
+		_, err := Eval(`@sloth.var namespace chatgpt
+@sloth.var namespace openai`)
+
+		var diags diagnostics.List
+		require.ErrorAs(t, err, &diags)
+		require.Len(t, diags, 1)
+		assert.Equal(t, diagnostics.CodeInvalidVariable, diags[0].Code)
+		assert.Equal(t, "2:1", diags[0].Pos.String())
+	})
+
+	t.Run("Successfully scan the declarations of the source", func(t *testing.T) {
+		decls := Declare(source)
+		assert.Equal(t, "chatgpt", decls.Service)
+		require.Len(t, decls.Variables, 2)
+		assert.Equal(t, "objective_tier1", decls.Variables[1].Name)
+		assert.Equal(t, "99.9", decls.Variables[1].Value)
+		assert.Equal(t, "3:1", decls.Variables[1].Pos.String())
+	})
+}
+
+func TestConstants(t *testing.T) {
+	t.Parallel()
+
+	const source = `@sloth.slo name availability
+@sloth.slo objective {{go "AvailabilityTarget"}}
+@sloth.sli error_ratio_query sum(rate({{ go "ErrorsMetric" }}[{{.window}}]))`
+
+	t.Run("Successfully replace the constant references by their resolved value", func(t *testing.T) {
+		constants := map[string]string{"AvailabilityTarget": "99.5", "ErrorsMetric": "errors_total"}
+		spec, err := Evaluator{ResolveConstant: func(name string) (string, error) {
+			return constants[name], nil
+		}}.Eval(source)
+		require.NoError(t, err)
+		require.Len(t, spec.SLOs, 1)
+		assert.Equal(t, 99.5, spec.SLOs[0].Objective)
+		assert.Equal(t, "sum(rate(errors_total[{{.window}}]))", spec.SLOs[0].SLI.Raw.ErrorRatioQuery)
+	})
+
+	t.Run("Fail to evaluate the constant references without a resolver, returning the diagnostics at the statements position", func(t *testing.T) {
+		spec, err := Eval(source)
+		require.NotNil(t, spec)
+		assert.Empty(t, spec.SLOs)
+
+		var diags diagnostics.List
+		require.ErrorAs(t, err, &diags)
+		require.Len(t, diags, 2)
+		assert.Equal(t, diagnostics.CodeUnresolvedConstant, diags[0].Code)
+		assert.Equal(t, "2:1", diags[0].Pos.String())
+		assert.Equal(t, `Go constant "AvailabilityTarget" can't be resolved outside of a Go source file in @sloth.slo objective`, diags[0].Message)
+	})
+}
+
+func TestMetrics(t *testing.T) {
+	t.Parallel()
+
+	metrics := Metrics{
+		Metric:   "chatgpt_auth0_tenant_login_operations_total",
+		Declared: map[string]string{"metricTenantFailedLogins": "chatgpt_auth0_tenant_failed_login_operations_total"},
+	}
+
+	t.Run("Successfully replace the metric references of the SLI queries", func(t *testing.T) {
+		spec, err := Evaluator{Metrics: metrics}.Eval(`@sloth.slo name availability
+@sloth.sli error_query sum(rate({{ .metrics.metricTenantFailedLogins }}[{{.window}}]))
+@sloth.sli total_query sum(rate({{.metric}}[{{.window}}]))`)
+		require.NoError(t, err)
+		require.Len(t, spec.SLOs, 1)
+		assert.Equal(t, "sum(rate(chatgpt_auth0_tenant_failed_login_operations_total[{{.window}}]))", spec.SLOs[0].SLI.Events.ErrorQuery)
+		assert.Equal(t, "sum(rate(chatgpt_auth0_tenant_login_operations_total[{{.window}}]))", spec.SLOs[0].SLI.Events.TotalQuery)
+	})
+
+	t.Run("Successfully replace the metric references of a template by the metrics of the SLO using it", func(t *testing.T) {
+		doc, err := Evaluator{Metrics: metrics}.EvalDocument(`@sloth.template counter-availability
+@sloth.sli error_ratio_query sum(rate({{.metric}}{code="{{.code}}"}[{{.window}}]))
+@sloth.slo name availability
+@sloth.slo use counter-availability code=500`)
+		require.NoError(t, err)
+		require.Len(t, doc.Uses, 1)
+
+		slo, err := Evaluator{Metrics: doc.Uses[0].Metrics}.Instantiate(doc.Templates[0], doc.Uses[0].Params)
+		require.NoError(t, err)
+		assert.Equal(t, `sum(rate(chatgpt_auth0_tenant_login_operations_total{code="500"}[{{.window}}]))`, slo.SLI.Raw.ErrorRatioQuery)
+	})
+
+	t.Run("Fail to evaluate the metric references which aren't declared, returning the diagnostics at the statements position", func(t *testing.T) {
+		spec, err := Eval(`@sloth.slo name availability
+@sloth.sli error_query sum(rate({{.metrics.missing}}[{{.window}}]))
+@sloth.sli total_query sum(rate({{.metric}}[{{.window}}]))`)
+		require.NotNil(t, spec)
+		assert.Empty(t, spec.SLOs)
+
+		var diags diagnostics.List
+		require.ErrorAs(t, err, &diags)
+		require.Len(t, diags, 2)
+		assert.Equal(t, diagnostics.CodeUnresolvedMetric, diags[0].Code)
+		assert.Equal(t, "2:1", diags[0].Pos.String())
+		assert.Equal(t, `the variable "missing" doesn't declare a Prometheus metric in @sloth.sli error_query`, diags[0].Message)
+		assert.Equal(t, `the annotations don't document a Prometheus metric declaration in @sloth.sli total_query`, diags[1].Message)
+	})
 }
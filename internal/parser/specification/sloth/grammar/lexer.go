@@ -2,9 +2,27 @@ package grammar
 
 import "github.com/alecthomas/participle/v2/lexer"
 
-var lexerDefinition = lexer.MustSimple([]lexer.SimpleRule{
-	{"EOL", `[\n\r]+`},
-	{"Sloth", `@sloth`},
-	{"String", `([a-zA-Z_0-9\.\/:,\-\'\(\)~\[\]\{\}=\"\|%])\w*`},
-	{"Whitespace", `[ \t]+`},
+// lexerDefinition tokenizes the sloth statements.
+// The Scope state lexes the statement scope and attribute following the @sloth keyword,
+// everything else up to the next @sloth keyword is lexed as String, EOL and Whitespace tokens
+// so that the attribute values, i.e. PromQL queries, are captured verbatim.
+// Continuation and Indent are the line breaks of the lines continued by a trailing backslash or an indented line,
+// any other line break is lexed as EOL and ends the statement value.
+// The Template scope has no attribute, the template name following it is lexed as its value.
+// The Var scope attribute is the variable name, which can contain digits.
+var lexerDefinition = lexer.MustStateful(lexer.Rules{
+	"Root": {
+		{Name: "Sloth", Pattern: `@sloth`, Action: lexer.Push("Scope")},
+		{Name: "Continuation", Pattern: `\\[ \t]*(\r\n|\n|\r)`},
+		{Name: "Indent", Pattern: `[\n\r]+[ \t]+`},
+		{Name: "EOL", Pattern: `[\n\r]+`},
+		{Name: "Whitespace", Pattern: `[ \t]+`},
+		{Name: "String", Pattern: `([^@\s\\]|\\[^\n\r])([^@\n\r\\]|\\[^\n\r])*|[@\\]`},
+	},
+	"Scope": {
+		{Name: "Template", Pattern: `\.template\b`, Action: lexer.Pop()},
+		{Name: "Type", Pattern: `(\.[a-zA-Z_]+)+`},
+		{Name: "Whitespace", Pattern: `[ \t]+`},
+		{Name: "Attribute", Pattern: `[a-zA-Z_][a-zA-Z0-9_]*`, Action: lexer.Pop()},
+	},
 })
@@ -0,0 +1,62 @@
+package grammar
+
+import (
+	"go/token"
+	"regexp"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// Metrics are the Prometheus metrics declared by the annotated source code
+type Metrics struct {
+	// Metric is the name of the metric declared by the source code the annotations document, referenced as {{.metric}}
+	Metric string
+	// Declared are the names of the metrics declared by the package level variables, indexed by variable name,
+	// referenced as {{.metrics.<var>}}
+	Declared map[string]string
+}
+
+// Query is an SLI query of an SLO, i.e: its error_query
+type Query struct {
+	SLO string
+	// Attribute is the SLI attribute of the query, i.e: error_query
+	Attribute string
+	Query     string
+	// Pos is the position of the query statement in the parsed source
+	Pos token.Position
+}
+
+// metricReference matches the metric references of the SLI queries, i.e: {{.metric}} or {{.metrics.requests}}
+var metricReference = regexp.MustCompile(`{{\s*\.metric(?:s\.([a-zA-Z_][a-zA-Z0-9_]*))?\s*}}`)
+
+// resolveMetrics returns the statement with the metric references of its value replaced by the metric names.
+// It returns an error if a referenced metric isn't declared.
+func (s *Statement) resolveMetrics(metrics Metrics) (*Statement, error) {
+	if !strings.Contains(string(s.Value), "{{") {
+		return s, nil
+	}
+	var problems []string
+	value := metricReference.ReplaceAllStringFunc(string(s.Value), func(match string) string {
+		variable := metricReference.FindStringSubmatch(match)[1]
+		if variable == "" {
+			if metrics.Metric == "" {
+				problems = append(problems, "the annotations don't document a Prometheus metric declaration")
+				return match
+			}
+			return metrics.Metric
+		}
+		name, ok := metrics.Declared[variable]
+		if !ok {
+			problems = append(problems, errors.Errorf("the variable %q doesn't declare a Prometheus metric", variable).Error())
+			return match
+		}
+		return name
+	})
+	if len(problems) > 0 {
+		return nil, errors.New(strings.Join(problems, ", "))
+	}
+	resolved := *s
+	resolved.Value = Text(value)
+	return &resolved, nil
+}
@@ -0,0 +1,127 @@
+package grammar
+
+import (
+	"go/token"
+	"regexp"
+	"strings"
+
+	"github.com/alecthomas/participle/v2/lexer"
+	"github.com/juju/errors"
+	sloth "github.com/slok/sloth/pkg/prometheus/api/v1"
+	"github.com/slosive/sloscribe/internal/diagnostics"
+)
+
+type (
+	// Document is the evaluation of a comment group: the partial service spec,
+	// the SLO templates declared by @sloth.template and the SLOs using them with @sloth.slo use
+	Document struct {
+		Spec      *sloth.Spec
+		Templates []*Template
+		Uses      []Use
+		// Queries are the SLI queries of the spec SLOs, with their position
+		Queries []Query
+		// Positions are the positions of the statements declaring the spec SLOs, indexed by SLO name
+		Positions map[string]diagnostics.SLOPosition
+		// ServicePos is the position of the @sloth service statement, empty if the service isn't declared
+		ServicePos token.Position
+	}
+	// Template is a parameterised SLO declared with @sloth.template <name>,
+	// its statements are evaluated when the template is used, once the placeholders, i.e: {{.handler}}, are replaced
+	Template struct {
+		Name string
+		// Pos is the position of the template statement in the parsed source
+		Pos        token.Position
+		pos        lexer.Position
+		statements []*Statement
+	}
+	// Use is an SLO instantiating a template with @sloth.slo use <template> key=value...
+	Use struct {
+		// SLO is the name of the SLO using the template
+		SLO      string
+		Template string
+		// Params are the values of the template placeholders
+		Params map[string]string
+		// Metrics are the metrics of the source code the SLO annotations document, the template metric references are replaced by them
+		Metrics Metrics
+		// Pos is the position of the use statement in the parsed source
+		Pos token.Position
+	}
+)
+
+// placeholder matches the template placeholders, i.e: {{.handler}}
+var placeholder = regexp.MustCompile(`{{\s*\.([a-zA-Z_][a-zA-Z0-9_]*)\s*}}`)
+
+const (
+	// windowPlaceholder is the sloth window template, it is left to sloth and never replaced
+	windowPlaceholder = "window"
+	// metricPlaceholder is the metric reference, it is replaced by the metric of the SLO using the template
+	metricPlaceholder = "metric"
+)
+
+// parseUse parses the value of a use statement: the template name followed by the template parameters
+func parseUse(value string) (*Use, error) {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return nil, errors.New("missing template name")
+	}
+	use := &Use{Template: fields[0], Params: map[string]string{}}
+	params := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(value), fields[0]))
+	if params == "" {
+		return use, nil
+	}
+	pairs, err := parseKeyValues(params)
+	if err != nil {
+		return nil, err
+	}
+	use.Params = pairs
+	return use, nil
+}
+
+// substitute replaces the placeholders of the value with the parameters, the {{.window}} sloth template and the {{.metric}} reference are kept.
+// It returns an error if a placeholder doesn't have a parameter.
+func substitute(value string, params map[string]string) (string, error) {
+	var missing []string
+	result := placeholder.ReplaceAllStringFunc(value, func(match string) string {
+		name := placeholder.FindStringSubmatch(match)[1]
+		if param, ok := params[name]; ok {
+			return param
+		}
+		if name != windowPlaceholder && name != metricPlaceholder {
+			missing = append(missing, name)
+		}
+		return match
+	})
+	if len(missing) > 0 {
+		return "", errors.Errorf("missing template parameter(s) %q", missing)
+	}
+	return result, nil
+}
+
+// Instantiate evaluates the statements of the template with its placeholders replaced by the parameters.
+// The problems found in the template are returned as a diagnostics.List, positioned in the template source.
+// The returned SLO doesn't have a name, it is nil if the template can't be evaluated.
+func (e Evaluator) Instantiate(t *Template, params map[string]string) (*sloth.SLO, error) {
+	var diags diagnostics.List
+	stmts := []*Statement{{Pos: t.pos, Scope: Scope{Type: ".slo", Value: sloNameAttr}, Value: Text(t.Name)}}
+	for _, stmt := range t.statements {
+		value, err := substitute(string(stmt.Value), params)
+		if err != nil {
+			diags = append(diags, diagnostics.New(stmt.position(), diagnostics.CodeInvalidTemplate, "template %q: %s", t.Name, err))
+			continue
+		}
+		instance := *stmt
+		instance.Value = Text(value)
+		stmts = append(stmts, &instance)
+	}
+	if len(diags) > 0 {
+		return nil, diags
+	}
+
+	doc, err := Grammar{Stmts: stmts}.parse(e)
+	if doc == nil || len(doc.Spec.SLOs) == 0 {
+		return nil, err
+	}
+	slo := doc.Spec.SLOs[0]
+	slo.Name = ""
+	return &slo, err
+}
@@ -0,0 +1,103 @@
+package grammar
+
+import (
+	"go/token"
+	"regexp"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/slosive/sloscribe/internal/diagnostics"
+)
+
+type (
+	// Variable is a value declared with @sloth.var <name> <value>, referenced as ${name} in the statement values
+	Variable struct {
+		Name  string
+		Value string
+		// Pos is the position of the var statement in the parsed source
+		Pos token.Position
+	}
+	// Declarations are the service and the variables declared in a source, they are scanned before the sources are evaluated
+	// so that the variables can be referenced by the sources of the same service or package
+	Declarations struct {
+		Service   string
+		Variables []Variable
+	}
+)
+
+// reference matches the variable references of the statement values, i.e: ${namespace}
+var reference = regexp.MustCompile(`\${([a-zA-Z_][a-zA-Z0-9_]*)}`)
+
+// Declare scans the source for the service and variables it declares.
+// The source problems are ignored, these are reported when the source is evaluated.
+func Declare(source string) Declarations {
+	var decls Declarations
+	grammar, err := createGrammar("", source)
+	if err != nil {
+		return decls
+	}
+	for _, stmt := range grammar.Stmts {
+		switch {
+		case stmt.Scope.Type == "" && strings.ToLower(stmt.Scope.Value) == "service":
+			decls.Service = strings.TrimSpace(string(stmt.Value))
+		case stmt.Scope.Type == varScope && stmt.Scope.Value != "":
+			decls.Variables = append(decls.Variables, Variable{Name: stmt.Scope.Value, Value: strings.TrimSpace(string(stmt.Value)), Pos: stmt.position()})
+		}
+	}
+	return decls
+}
+
+// variables returns the variables available to the statements, the overrides take precedence over the variables declared
+// in the grammar statements, which take precedence over the variables of the evaluator
+func (g Grammar) variables(e Evaluator) (map[string]string, diagnostics.List) {
+	var diags diagnostics.List
+	vars := map[string]string{}
+	for name, value := range e.Variables {
+		vars[name] = value
+	}
+	declared := map[string]*Statement{}
+	for _, stmt := range g.Stmts {
+		if stmt.Scope.Type != varScope {
+			continue
+		}
+		if stmt.Scope.Value == "" {
+			diags = append(diags, stmt.invalid(errors.New("missing variable name")))
+			continue
+		}
+		value := strings.TrimSpace(string(stmt.Value))
+		if prev, ok := declared[stmt.Scope.Value]; ok && strings.TrimSpace(string(prev.Value)) != value {
+			diags = append(diags, diagnostics.New(stmt.position(), diagnostics.CodeInvalidVariable,
+				"variable %q is already declared at %s with a different value", stmt.Scope.Value, prev.position()))
+			continue
+		}
+		declared[stmt.Scope.Value] = stmt
+		vars[stmt.Scope.Value] = value
+	}
+	for name, value := range e.Overrides {
+		vars[name] = value
+	}
+	return vars, diags
+}
+
+// resolve returns the statement with the variable references of its value replaced by the variables.
+// It returns an error if a referenced variable isn't declared.
+func (s *Statement) resolve(vars map[string]string) (*Statement, error) {
+	if !strings.Contains(string(s.Value), "${") {
+		return s, nil
+	}
+	var undefined []string
+	value := reference.ReplaceAllStringFunc(string(s.Value), func(match string) string {
+		name := reference.FindStringSubmatch(match)[1]
+		if value, ok := vars[name]; ok {
+			return value
+		}
+		undefined = append(undefined, name)
+		return match
+	})
+	if len(undefined) > 0 {
+		return nil, errors.Errorf("undefined variable(s) %q", undefined)
+	}
+	resolved := *s
+	resolved.Value = Text(value)
+	return &resolved, nil
+}
@@ -0,0 +1,2 @@
+// Package comments contains the comments scanner and parser shared by the languages parsed without a dedicated parser.
+package comments
@@ -0,0 +1,161 @@
+package comments
+
+import (
+	"bufio"
+	"bytes"
+	"go/ast"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/slosive/sloscribe/internal/parser/specification/sloth/collector"
+)
+
+// Language describes a source language parsed by the comments parser
+type Language struct {
+	// Name of the language, used in the logs
+	Name string
+	// Syntax is the comments syntax of the language
+	Syntax Syntax
+	// Extract, if set, is used instead of the Syntax to extract the comment groups from the source files, i.e: go/parser
+	Extract func(fset *token.FileSet, filename string, src []byte) ([]*ast.CommentGroup, error)
+	// Extensions are the file extensions of the source files written in the language, i.e: .rs
+	Extensions []string
+	// Interpreters are the interpreters, found in the shebang, of the extensionless scripts written in the language, i.e: python3
+	Interpreters []string
+	// Entrypoints are the files parsed before the other files in the same directory, i.e: main.go
+	Entrypoints []string
+	// ExcludedDirs are the directories skipped when walking the input directories, i.e: build output directories
+	ExcludedDirs []string
+	// Symbols, if set, returns the symbols of the source files referenced by the annotations, i.e: the {{go "Ident"}} constants.
+	// sources are the contents of the source files written in the language in the same directory, indexed by filename
+	Symbols func(sources map[string][]byte) collector.Symbols
+}
+
+// IsSourceFile returns true if the file has one of the language extensions
+func (l Language) IsSourceFile(name string) bool {
+	ext := filepath.Ext(name)
+	for _, e := range l.Extensions {
+		if strings.EqualFold(ext, e) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsScript returns true if the shebang interpreter is one of the language interpreters, i.e: #!/usr/bin/env python3.
+// The interpreter names are compared exactly, only a version suffix is allowed, i.e: python3.11 is python but nodemon isn't node.
+func (l Language) IsScript(shebang string) bool {
+	interpreter := shebangInterpreter(shebang)
+	if interpreter == "" {
+		return false
+	}
+	for _, i := range l.Interpreters {
+		if version, ok := strings.CutPrefix(interpreter, i); ok && (version == "" || interpreterVersion.MatchString(version)) {
+			return true
+		}
+	}
+	return false
+}
+
+// interpreterVersion matches the version suffix of the interpreter names, i.e: 3 or 3.11
+var interpreterVersion = regexp.MustCompile(`^[0-9]+(\.[0-9]+)*$`)
+
+// IsExcludedDir returns true if the directory should be skipped when looking for source files
+func (l Language) IsExcludedDir(name string) bool {
+	for _, dir := range l.ExcludedDirs {
+		if name == dir {
+			return true
+		}
+	}
+	return false
+}
+
+// IsEntrypoint returns true if the file should be parsed before the other files in the same directory
+func (l Language) IsEntrypoint(name string) bool {
+	for _, entrypoint := range l.Entrypoints {
+		if filepath.Base(name) == entrypoint {
+			return true
+		}
+	}
+	return false
+}
+
+// Comments returns the comment groups in the source, the comments are positioned in a new file added to the file set
+func (l Language) Comments(fset *token.FileSet, filename string, src []byte) ([]*ast.CommentGroup, error) {
+	if l.Extract != nil {
+		return l.Extract(fset, filename, src)
+	}
+	file := fset.AddFile(filename, -1, len(src))
+	file.SetLinesForContent(src)
+	return Scan(file, src, l.Syntax), nil
+}
+
+// Detect returns the language of the source file, given its extension or, for extensionless scripts, its shebang.
+// The source content is only used for the extensionless scripts, if nil the file is read to find the shebang.
+func Detect(filename string, src []byte, languages ...Language) (Language, bool) {
+	if filepath.Ext(filename) != "" {
+		for _, l := range languages {
+			if l.IsSourceFile(filename) {
+				return l, true
+			}
+		}
+		return Language{}, false
+	}
+
+	scripts := false
+	for _, l := range languages {
+		scripts = scripts || len(l.Interpreters) > 0
+	}
+	if !scripts {
+		return Language{}, false
+	}
+
+	shebang := readShebang(filename, src)
+	for _, l := range languages {
+		if l.IsScript(shebang) {
+			return l, true
+		}
+	}
+	return Language{}, false
+}
+
+// readShebang returns the first line of the source if it is a shebang, i.e: #!/usr/bin/env python3
+func readShebang(filename string, src []byte) string {
+	if src == nil {
+		f, err := os.Open(filename)
+		if err != nil {
+			return ""
+		}
+		defer f.Close()
+		src, _ = bufio.NewReader(f).Peek(256)
+	}
+	if !bytes.HasPrefix(src, []byte("#!")) {
+		return ""
+	}
+	if end := bytes.IndexByte(src, '\n'); end >= 0 {
+		src = src[:end]
+	}
+	return strings.TrimSpace(string(src[2:]))
+}
+
+// shebangInterpreter returns the interpreter name in the shebang, i.e: python3 for /usr/bin/env python3
+func shebangInterpreter(shebang string) string {
+	fields := strings.Fields(shebang)
+	if len(fields) == 0 {
+		return ""
+	}
+	interpreter := filepath.Base(fields[0])
+	if interpreter == "env" {
+		// skip env flags, i.e: /usr/bin/env -S deno run
+		for _, field := range fields[1:] {
+			if !strings.HasPrefix(field, "-") {
+				return filepath.Base(field)
+			}
+		}
+		return ""
+	}
+	return interpreter
+}
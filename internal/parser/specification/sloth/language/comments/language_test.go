@@ -0,0 +1,80 @@
+package comments
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	cLanguage = Language{
+		Name:         "c",
+		Syntax:       cSyntax,
+		Extensions:   []string{".c", ".h"},
+		Entrypoints:  []string{"main.c"},
+		ExcludedDirs: []string{"build"},
+	}
+	shellLanguage = Language{
+		Name:         "shell",
+		Syntax:       Syntax{LineComments: []string{"#"}},
+		Extensions:   []string{".sh"},
+		Interpreters: []string{"sh", "bash", "shell"},
+		ExcludedDirs: []string{"deps"},
+	}
+)
+
+func TestDetect(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Successfully detect the language from the file extension", func(t *testing.T) {
+		l, ok := Detect("lib/availability.C", nil, shellLanguage, cLanguage)
+		require.True(t, ok)
+		assert.Equal(t, "c", l.Name)
+	})
+
+	t.Run("Successfully detect the language of an extensionless script from the shebang", func(t *testing.T) {
+		l, ok := Detect("latency", []byte("#!/usr/bin/env bash\necho"), cLanguage, shellLanguage)
+		require.True(t, ok)
+		assert.Equal(t, "shell", l.Name)
+
+		l, ok = Detect("latency", []byte("#!/bin/sh"), cLanguage, shellLanguage)
+		require.True(t, ok)
+		assert.Equal(t, "shell", l.Name)
+	})
+
+	t.Run("Successfully detect the language of an extensionless script file from the shebang", func(t *testing.T) {
+		l, ok := Detect("./testdata/polyglot/lib/latency", nil, cLanguage, shellLanguage)
+		require.True(t, ok)
+		assert.Equal(t, "shell", l.Name)
+	})
+
+	t.Run("Fail to detect the language of a file with an unknown extension", func(t *testing.T) {
+		_, ok := Detect("main.rs", nil, cLanguage, shellLanguage)
+		assert.False(t, ok)
+	})
+
+	t.Run("Fail to detect the language of an extensionless file without shebang", func(t *testing.T) {
+		_, ok := Detect("./testdata/polyglot/lib/README", nil, cLanguage, shellLanguage)
+		assert.False(t, ok)
+	})
+
+	t.Run("Fail to detect the language of a script with an unknown interpreter", func(t *testing.T) {
+		_, ok := Detect("script", []byte("#!/usr/bin/env ruby"), cLanguage, shellLanguage)
+		assert.False(t, ok)
+	})
+
+	t.Run("Successfully detect the language of a script with a versioned interpreter", func(t *testing.T) {
+		l, ok := Detect("latency", []byte("#!/usr/local/bin/bash5.2"), cLanguage, shellLanguage)
+		require.True(t, ok)
+		assert.Equal(t, "shell", l.Name)
+	})
+
+	t.Run("Fail to detect the language of a script whose interpreter starts with a language interpreter", func(t *testing.T) {
+		_, ok := Detect("script", []byte("#!/usr/bin/env shellcheck"), cLanguage, shellLanguage)
+		assert.False(t, ok)
+
+		_, ok = Detect("script", []byte("#!/usr/bin/env bash-completion"), cLanguage, shellLanguage)
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,338 @@
+package comments
+
+import (
+	"context"
+	"go/ast"
+	"go/token"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	multierr "github.com/hashicorp/go-multierror"
+	"github.com/juju/errors"
+	"github.com/slosive/sloscribe/internal/diagnostics"
+	"github.com/slosive/sloscribe/internal/logging"
+	"github.com/slosive/sloscribe/internal/parser/specification/sloth/collector"
+)
+
+type (
+	// Options contains the configuration options available to the Parser
+	Options struct {
+		Logger *logging.Logger
+		// SourceFile is the path to the target file to be parsed, i.e: -f lib.rs
+		SourceFile string
+		// SourceContent is the reader to the content to be parsed
+		SourceContent    io.ReadCloser
+		InputDirectories []string
+		// Kubernetes tells the parser to parser the sloth annotations and output a kubernetes specification of the service
+		Kubernetes bool
+		// Reporter receives the diagnostics of the sloth annotations, these are logged as warnings if nil
+		Reporter diagnostics.Reporter
+		// Positions receives the positions of the annotations declaring the parsed SLOs, if set
+		Positions *diagnostics.Positions
+		// Strict tells the parser to return every warning as an error, aggregated in a diagnostics.StrictError
+		Strict bool
+		// ValidateQueries tells the parser to validate the PromQL queries of the SLIs
+		ValidateQueries bool
+		// Variables are the annotation variables overriding the ones declared with @sloth.var
+		Variables map[string]string
+	}
+
+	parser struct {
+		// languages are the languages of the parsed source files, each file is parsed using the language it is written in
+		languages []Language
+		// collector collects the sloth annotations found in the parsed comments into service specifications
+		collector *collector.Collector
+		// sourceFile is the path to the target file to be parsed, i.e: -f lib.rs
+		sourceFile string
+		// sourceContent is the reader to the content to be parsed
+		sourceContent io.ReadCloser
+		includedDirs  []string
+		logger        *logging.Logger
+		// strict tells the parser to return the problems found while parsing as an error
+		strict bool
+		// problems aggregates the warnings logged while parsing
+		problems error
+	}
+)
+
+func NewOptions() *Options {
+	l := logging.NewStandardLogger()
+	return &Options{
+		Logger:           &l,
+		SourceFile:       "",
+		SourceContent:    nil,
+		InputDirectories: nil,
+		Kubernetes:       false,
+	}
+}
+
+// NewParser returns a parser for the source files written in the given language,
+// which collects the sloth annotations from the comments extracted using the language Syntax.
+func NewParser(language Language, opts *Options) *parser {
+	return NewMultiLanguageParser([]Language{language}, opts)
+}
+
+// NewMultiLanguageParser returns a parser for the source files written in any of the given languages.
+// The language of each file is detected from its extension, or shebang, and the sloth annotations
+// found in all the files are merged in the same service specifications.
+// The first language is used for the source content and files whose language can't be detected.
+func NewMultiLanguageParser(languages []Language, opts *Options) *parser {
+	// create default options, these will be overridden
+	if opts == nil {
+		opts = NewOptions()
+	}
+
+	return &parser{
+		languages:     languages,
+		collector:     collector.New(opts.Logger, opts.Kubernetes).WithReporter(opts.Reporter).WithQueryValidation(opts.ValidateQueries).WithVariables(opts.Variables).WithPositions(opts.Positions).WithScannedComments(true),
+		sourceFile:    opts.SourceFile,
+		sourceContent: opts.SourceContent,
+		includedDirs:  opts.InputDirectories,
+		logger:        opts.Logger,
+		strict:        opts.Strict,
+	}
+}
+
+// isExcludedDir returns true if the directory is excluded by any of the languages
+func (p *parser) isExcludedDir(name string) bool {
+	for _, l := range p.languages {
+		if l.IsExcludedDir(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// getAllSourceFiles returns the path of all the source files written in the parser languages in the target directory and subdirectories.
+// The languages entrypoints are returned before the other files in the same directory.
+func (p *parser) getAllSourceFiles(dir string) ([]string, error) {
+	var files []string
+	var entrypoints = map[string]bool{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && p.isExcludedDir(d.Name()) {
+				return filepath.SkipDir
+			}
+			for _, l := range p.languages {
+				for _, entrypoint := range l.Entrypoints {
+					file := filepath.Join(path, entrypoint)
+					if info, err := os.Stat(file); err == nil && info.Mode().IsRegular() {
+						files = append(files, file)
+						entrypoints[file] = true
+					}
+				}
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || entrypoints[path] {
+			return nil
+		}
+		if _, ok := Detect(path, nil, p.languages...); ok {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		return nil, errors.Errorf("no %s source files were found in the target directory and subdirectories: %s", p.names(), dir)
+	}
+
+	return files, nil
+}
+
+// names returns the names of the parser languages, i.e: go, rust
+func (p *parser) names() string {
+	names := make([]string, 0, len(p.languages))
+	for _, l := range p.languages {
+		names = append(names, l.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// getFileContent returns the content of the file given filename or an io.Reader. If an io.Reader is passed it will take precedence
+// over the filename
+func getFileContent(name string, file io.ReadCloser) ([]byte, error) {
+	if file != nil {
+		defer file.Close()
+		return io.ReadAll(file)
+	}
+	return os.ReadFile(name)
+}
+
+// parsedFile is a parsed source file, its comment groups are collected once the annotation variables of all the files are declared
+type parsedFile struct {
+	filename string
+	language Language
+	src      []byte
+	comments []*ast.CommentGroup
+}
+
+// parseFile returns the comment groups of the source file, the file is parsed using the language it is written in.
+// If the language can't be detected, the first parser language is used.
+func (p *parser) parseFile(fset *token.FileSet, filename string, src []byte) (*parsedFile, error) {
+	language, ok := Detect(filename, src, p.languages...)
+	if !ok {
+		language = p.languages[0]
+	}
+
+	p.logger.Debug("Parsing source code", "file", filename, "language", language.Name)
+	comments, err := language.Comments(fset, filename, src)
+	if err != nil {
+		return nil, err
+	}
+	return &parsedFile{filename: filename, language: language, src: src, comments: comments}, nil
+}
+
+// collect collects the sloth annotations in the source file comments, the annotations can reference the symbols of the file directory
+func (p *parser) collect(fset *token.FileSet, file *parsedFile, symbols *dirSymbols) error {
+	p.collector.WithSymbols(symbols.of(file))
+	if err := p.collector.Collect(fset, file.comments...); err != nil {
+		return err
+	}
+	p.logger.Debug("Parsed source code", "file", file.filename, "language", file.language.Name)
+	return nil
+}
+
+// dirSymbols are the symbols of the parsed source files, resolved per directory and language
+type dirSymbols struct {
+	files   []*parsedFile
+	symbols map[string]collector.Symbols
+}
+
+func newDirSymbols(files ...*parsedFile) *dirSymbols {
+	return &dirSymbols{files: files, symbols: map[string]collector.Symbols{}}
+}
+
+// of returns the symbols of the source file directory, nil if the file language has no symbols.
+// The symbols of a directory are resolved once, from the source files written in the same language
+func (d *dirSymbols) of(file *parsedFile) collector.Symbols {
+	if file.language.Symbols == nil {
+		return nil
+	}
+	dir := filepath.Dir(file.filename)
+	key := file.language.Name + ":" + dir
+	if symbols, ok := d.symbols[key]; ok {
+		return symbols
+	}
+	sources := map[string][]byte{}
+	for _, f := range d.files {
+		if f.language.Name == file.language.Name && filepath.Dir(f.filename) == dir {
+			sources[f.filename] = f.src
+		}
+	}
+	d.symbols[key] = file.language.Symbols(sources)
+	return d.symbols[key]
+}
+
+// Parse will parse the source code comments for sloth annotations.
+// In case of error during parsing, Parse returns an empty specification
+func (p *parser) Parse(ctx context.Context) (map[string]any, error) {
+	if len(p.languages) == 0 {
+		return nil, errors.New("no source language was selected")
+	}
+
+	fset := token.NewFileSet()
+
+	// collect all sloth annotations from the file and add them to the spec struct
+	if p.sourceFile != "" || p.sourceContent != nil {
+		src, err := getFileContent(p.sourceFile, p.sourceContent)
+		if err != nil {
+			// error hard as we can't extract more data for the spec
+			return nil, err
+		}
+		file, err := p.parseFile(fset, p.sourceFile, src)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.collect(fset, file, newDirSymbols(file)); err != nil {
+			return nil, err
+		}
+		return p.result()
+	}
+
+	var files []*parsedFile
+
+	for _, dir := range p.includedDirs {
+		// handle signals with context
+		select {
+		case <-ctx.Done():
+			return nil, errors.New("termination signal was received, terminating process...")
+		default:
+		}
+		if _, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
+			// skip if dir doesn't exists
+			p.warn(err)
+			continue
+		}
+
+		filenames, err := p.getAllSourceFiles(dir)
+		if err != nil {
+			p.warn(err)
+			continue
+		}
+
+		for _, filename := range filenames {
+			// handle signals with context
+			select {
+			case <-ctx.Done():
+				return nil, errors.New("termination signal was received, terminating process...")
+			default:
+			}
+
+			src, err := getFileContent(filename, nil)
+			if err != nil {
+				p.warn(err)
+				continue
+			}
+			file, err := p.parseFile(fset, filename, src)
+			if err != nil {
+				p.warn(err)
+				continue
+			}
+			files = append(files, file)
+		}
+	}
+
+	// declare the annotation variables of all the files first, these can be referenced from any file
+	for _, file := range files {
+		p.collector.Declare(fset, file.comments...)
+	}
+	symbols := newDirSymbols(files...)
+	for _, file := range files {
+		if err := p.collect(fset, file, symbols); err != nil {
+			p.warn(err)
+		}
+	}
+
+	return p.result()
+}
+
+// result returns the collected specifications.
+// In strict mode, the problems found by the parser and the collector are returned in a diagnostics.StrictError.
+func (p *parser) result() (map[string]any, error) {
+	problems := multierr.Append(p.problems, p.collector.Finish())
+	// print statistics, once the SLOs using a template are instantiated
+	p.collector.Stats()
+	if p.strict {
+		if err := diagnostics.Strict(problems.ErrorOrNil()); err != nil {
+			return nil, err
+		}
+	}
+	return p.collector.Specs(), nil
+}
+
+func (p *parser) warn(err error, keyValues ...interface{}) {
+	p.problems = multierr.Append(p.problems, err)
+	if p.logger != nil {
+		p.logger.Warn(err, keyValues...)
+	}
+}
@@ -0,0 +1,91 @@
+package comments
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	sloth "github.com/slok/sloth/pkg/prometheus/api/v1"
+	"github.com/slosive/sloscribe/internal/diagnostics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Successfully merge the sloth annotations in source files written in different languages", func(t *testing.T) {
+		opts := NewOptions()
+		opts.InputDirectories = []string{"./testdata/polyglot"}
+		specs, err := NewMultiLanguageParser([]Language{cLanguage, shellLanguage}, opts).Parse(context.Background())
+		require.NoError(t, err)
+		require.Len(t, specs, 1)
+
+		spec, ok := specs["chatgpt"].(*sloth.Spec)
+		require.True(t, ok)
+		require.Len(t, spec.SLOs, 2)
+		assert.Equal(t, "chat-gpt-availability", spec.SLOs[0].Name)
+		assert.Equal(t, "chat-gpt-latency", spec.SLOs[1].Name)
+	})
+
+	t.Run("Successfully parse only the source files written in the parser language", func(t *testing.T) {
+		opts := NewOptions()
+		opts.InputDirectories = []string{"./testdata/polyglot"}
+		specs, err := NewParser(cLanguage, opts).Parse(context.Background())
+		require.NoError(t, err)
+
+		spec, ok := specs["chatgpt"].(*sloth.Spec)
+		require.True(t, ok)
+		require.Len(t, spec.SLOs, 1)
+		assert.Equal(t, "chat-gpt-availability", spec.SLOs[0].Name)
+	})
+
+	t.Run("Successfully parse the content reader using the script shebang", func(t *testing.T) {
+		opts := NewOptions()
+		opts.SourceContent = io.NopCloser(strings.NewReader(`#!/bin/bash
+# @sloth service foobar
+`))
+		specs, err := NewMultiLanguageParser([]Language{cLanguage, shellLanguage}, opts).Parse(context.Background())
+		require.NoError(t, err)
+		_, ok := specs["foobar"]
+		assert.True(t, ok)
+	})
+
+	t.Run("Successfully parse the content reader using the first language if it can't be detected", func(t *testing.T) {
+		opts := NewOptions()
+		opts.SourceContent = io.NopCloser(strings.NewReader(`// @sloth service foobar`))
+		specs, err := NewMultiLanguageParser([]Language{cLanguage, shellLanguage}, opts).Parse(context.Background())
+		require.NoError(t, err)
+		_, ok := specs["foobar"]
+		assert.True(t, ok)
+	})
+
+	t.Run("Successfully report the diagnostics at the column of the annotations, whatever the comment markers", func(t *testing.T) {
+		sources := map[string]string{
+			"slo.sh": "# @sloth service foo\n#   @sloth.slo objective high\n",
+			"slo.c":  "/// @sloth service foo\n///   @sloth.slo objective high\n",
+		}
+		positions := map[string]string{
+			"slo.sh": "slo.sh:2:5",
+			"slo.c":  "slo.c:2:7",
+		}
+		for filename, src := range sources {
+			var reported diagnostics.List
+			opts := NewOptions()
+			opts.SourceFile = filename
+			opts.SourceContent = io.NopCloser(strings.NewReader(src))
+			opts.Reporter = &reported
+			_, err := NewMultiLanguageParser([]Language{cLanguage, shellLanguage}, opts).Parse(context.Background())
+			require.NoError(t, err)
+			require.Len(t, reported, 1, filename)
+			assert.Equal(t, diagnostics.CodeInvalidValue, reported[0].Code, filename)
+			assert.Equal(t, positions[filename], reported[0].Pos.String(), filename)
+		}
+	})
+
+	t.Run("Fail to parse if no language was selected", func(t *testing.T) {
+		_, err := NewMultiLanguageParser(nil, NewOptions()).Parse(context.Background())
+		require.Error(t, err)
+	})
+}
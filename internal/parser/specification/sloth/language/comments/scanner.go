@@ -0,0 +1,242 @@
+package comments
+
+import (
+	"bytes"
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+type (
+	// Delimiters are the start and end markers of a block comment or string literal, i.e: /* */
+	Delimiters struct {
+		Start string
+		End   string
+	}
+
+	// Syntax describes how comments and string literals are written in a source language.
+	// String literals are skipped by the scanner, so that comment markers inside them are not picked up.
+	Syntax struct {
+		// LineComments are the markers starting a comment running until the end of the line, i.e: //
+		LineComments []string
+		// BlockComments are the delimiters of the comments spanning multiple lines, i.e: /* */
+		BlockComments []Delimiters
+		// NestedBlockComments tells the scanner block comments can contain other block comments, i.e: rust
+		NestedBlockComments bool
+		// LeadingAsterisks tells the scanner to remove the asterisk decorating the start of the block comment lines, i.e: JSDoc
+		LeadingAsterisks bool
+		// DocMarkers are the characters right after a comment marker turning it into a doc comment, i.e: /// or //!.
+		// These are removed from the comment text.
+		DocMarkers string
+		// DocStrings are the delimiters of the string literals used as comments, i.e: python docstrings.
+		// These are returned as block comments if they start their line, otherwise they are string literals, i.e: x = """..."""
+		DocStrings []Delimiters
+		// Strings are the delimiters of the string literals, a backslash escapes the following character
+		Strings []Delimiters
+		// Literal returns the end offset of any language specific literal starting at the offset,
+		// it returns the offset itself if there isn't one. i.e: rust raw strings
+		Literal func(src []byte, offset int) int
+	}
+)
+
+// Scan returns the comment groups found in the source, the same way go/parser groups comments in a go file:
+// adjacent comments, with no code or empty line in between, belong to the same group.
+// Each line of a comment is returned as a separate ast.Comment, positioned in the token.File.
+func Scan(file *token.File, src []byte, syntax Syntax) []*ast.CommentGroup {
+	var groups []*ast.CommentGroup
+	var list []*ast.Comment
+	// lastLine is the line where the last comment in list ends
+	lastLine := 0
+
+	flush := func() {
+		if len(list) > 0 {
+			groups = append(groups, &ast.CommentGroup{List: list})
+			list = nil
+		}
+	}
+	// add starts a new group if the comment starting at offset isn't adjacent to the previous one.
+	// The text of the first line of the comment starts at offset, the other lines start at the start of their line,
+	// after the leading asterisk if these are removed.
+	add := func(offset int, text string, asterisks bool) {
+		line := file.Line(file.Pos(offset))
+		if line > lastLine+1 {
+			flush()
+		}
+		for i, l := range strings.Split(text, "\n") {
+			if i > 0 {
+				offset = file.Offset(file.LineStart(line + i))
+				if asterisks {
+					var n int
+					l, n = trimLeadingAsterisk(l)
+					offset += n
+				}
+			}
+			list = append(list, NewComment(file, offset, strings.TrimRight(l, "\r")))
+			lastLine = line + i
+		}
+	}
+
+	i := 0
+	if bytes.HasPrefix(src, []byte("#!")) {
+		// the shebang line isn't a comment, i.e: #!/usr/bin/env python3
+		if i = bytes.IndexByte(src, '\n'); i < 0 {
+			i = len(src)
+		}
+	}
+
+	for i < len(src) {
+		if syntax.Literal != nil {
+			if end := syntax.Literal(src, i); end > i {
+				flush()
+				i = end
+				continue
+			}
+		}
+
+		if marker, ok := matchAny(src[i:], syntax.LineComments...); ok {
+			start := i + len(marker)
+			end := bytes.IndexByte(src[start:], '\n')
+			if end < 0 {
+				end = len(src)
+			} else {
+				end += start
+			}
+			text, offset := syntax.trimDocMarker(string(src[start:end]), start)
+			add(offset, text, false)
+			i = end
+			continue
+		}
+
+		if block, ok := matchDelimiters(src[i:], syntax.BlockComments...); ok {
+			start := i + len(block.Start)
+			end := syntax.blockEnd(src, start, block)
+			text, offset := syntax.trimDocMarker(string(bytes.TrimSuffix(src[start:end], []byte(block.End))), start)
+			add(offset, text, syntax.LeadingAsterisks)
+			i = end
+			continue
+		}
+
+		if doc, ok := matchDelimiters(src[i:], syntax.DocStrings...); ok && startsLine(src, i) {
+			start := i + len(doc.Start)
+			end := stringEnd(src, start, doc)
+			add(start, string(bytes.TrimSuffix(src[start:end], []byte(doc.End))), false)
+			i = end
+			continue
+		}
+
+		if str, ok := matchDelimiters(src[i:], syntax.Strings...); ok {
+			flush()
+			i = stringEnd(src, i+len(str.Start), str)
+			continue
+		}
+
+		switch src[i] {
+		case ' ', '\t', '\n', '\r':
+		default:
+			// any other character is code, which separates comment groups
+			flush()
+		}
+		i++
+	}
+	flush()
+
+	return groups
+}
+
+// NewComment returns the ast.Comment for a comment line whose text, without the comment markers, starts at offset.
+// The comment is positioned at its text, which is prefixed with the go line comment marker so that ast.CommentGroup.Text
+// can be used to get the text of the comments: the collector of these comments is created WithScannedComments.
+// Like for go comments, the first space of the line is removed by ast.CommentGroup.Text.
+func NewComment(file *token.File, offset int, text string) *ast.Comment {
+	if offset > file.Size() {
+		offset = file.Size()
+	}
+	return &ast.Comment{
+		Slash: file.Pos(offset),
+		Text:  "//" + text,
+	}
+}
+
+// trimDocMarker removes the doc marker from the start of the comment text, at offset.
+// It returns the text and the offset where it starts.
+func (s Syntax) trimDocMarker(text string, offset int) (string, int) {
+	if text != "" && strings.ContainsRune(s.DocMarkers, rune(text[0])) {
+		return text[1:], offset + 1
+	}
+	return text, offset
+}
+
+// trimLeadingAsterisk removes the asterisk, and the indentation before it, from the start of a block comment line
+// after the first one, i.e:
+//
+//	/**
+//	 * @sloth service foo
+//	 */
+//
+// It returns the line and the number of characters removed.
+func trimLeadingAsterisk(line string) (string, int) {
+	if trimmed := strings.TrimLeft(line, " \t"); strings.HasPrefix(trimmed, "*") {
+		return trimmed[1:], len(line) - len(trimmed) + 1
+	}
+	return line, 0
+}
+
+// startsLine returns true if only indentation precedes the offset on its line
+func startsLine(src []byte, offset int) bool {
+	lineStart := bytes.LastIndexByte(src[:offset], '\n') + 1
+	return len(bytes.TrimLeft(src[lineStart:offset], " \t")) == 0
+}
+
+func matchDelimiters(src []byte, delimiters ...Delimiters) (Delimiters, bool) {
+	for _, d := range delimiters {
+		if bytes.HasPrefix(src, []byte(d.Start)) {
+			return d, true
+		}
+	}
+	return Delimiters{}, false
+}
+
+// blockEnd returns the offset right after the end delimiter of the block comment starting at offset,
+// or the end of the source if the block comment isn't terminated
+func (s Syntax) blockEnd(src []byte, offset int, block Delimiters) int {
+	depth := 1
+	for i := offset; i < len(src); {
+		switch {
+		case bytes.HasPrefix(src[i:], []byte(block.End)):
+			depth--
+			i += len(block.End)
+			if depth == 0 || !s.NestedBlockComments {
+				return i
+			}
+		case s.NestedBlockComments && bytes.HasPrefix(src[i:], []byte(block.Start)):
+			depth++
+			i += len(block.Start)
+		default:
+			i++
+		}
+	}
+	return len(src)
+}
+
+// stringEnd returns the offset right after the end delimiter of the string literal starting at offset,
+// or the end of the source if the string isn't terminated
+func stringEnd(src []byte, offset int, str Delimiters) int {
+	for i := offset; i < len(src); i++ {
+		switch {
+		case src[i] == '\\':
+			i++
+		case bytes.HasPrefix(src[i:], []byte(str.End)):
+			return i + len(str.End)
+		}
+	}
+	return len(src)
+}
+
+func matchAny(src []byte, prefixes ...string) (string, bool) {
+	for _, prefix := range prefixes {
+		if bytes.HasPrefix(src, []byte(prefix)) {
+			return prefix, true
+		}
+	}
+	return "", false
+}
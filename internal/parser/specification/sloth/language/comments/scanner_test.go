@@ -0,0 +1,121 @@
+package comments
+
+import (
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var cSyntax = Syntax{
+	LineComments:  []string{"//"},
+	BlockComments: []Delimiters{{Start: "/*", End: "*/"}},
+	DocMarkers:    "/*",
+	Strings:       []Delimiters{{Start: `"`, End: `"`}},
+}
+
+func scan(src string, syntax Syntax) (*token.FileSet, []string) {
+	fset := token.NewFileSet()
+	l := Language{Syntax: syntax}
+	var texts []string
+	groups, _ := l.Comments(fset, "test.src", []byte(src))
+	for _, group := range groups {
+		texts = append(texts, group.Text())
+	}
+	return fset, texts
+}
+
+func TestScan(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Successfully group adjacent line comments", func(t *testing.T) {
+		_, groups := scan(`
+// @sloth service foo
+// @sloth.slo name bar
+
+// @sloth.slo name baz
+code()
+// @sloth.slo name qux
+`, cSyntax)
+		assert.Equal(t, []string{
+			"@sloth service foo\n@sloth.slo name bar\n",
+			"@sloth.slo name baz\n",
+			"@sloth.slo name qux\n",
+		}, groups)
+	})
+
+	t.Run("Successfully split block comments in lines", func(t *testing.T) {
+		_, groups := scan(`/**
+@sloth service foo
+@sloth.slo name bar
+*/
+fn()`, cSyntax)
+		assert.Equal(t, []string{"@sloth service foo\n@sloth.slo name bar\n"}, groups)
+	})
+
+	t.Run("Successfully remove the leading asterisks from the block comment lines", func(t *testing.T) {
+		syntax := cSyntax
+		syntax.LeadingAsterisks = true
+		_, groups := scan(`/**
+ * @sloth service foo
+ *   @sloth.slo name bar
+ */`, syntax)
+		assert.Equal(t, []string{"@sloth service foo\n  @sloth.slo name bar\n"}, groups)
+	})
+
+	t.Run("Successfully remove the doc comment markers", func(t *testing.T) {
+		_, groups := scan(`/// @sloth service foo`, cSyntax)
+		assert.Equal(t, []string{"@sloth service foo\n"}, groups)
+	})
+
+	t.Run("Successfully ignore comment markers in strings", func(t *testing.T) {
+		_, groups := scan(`url := "http://localhost/* \" // */"
+// @sloth service foo`, cSyntax)
+		assert.Equal(t, []string{"@sloth service foo\n"}, groups)
+	})
+
+	t.Run("Successfully return the docstrings as block comments", func(t *testing.T) {
+		_, groups := scan(`x = "not a docstring"
+"""@sloth service foo
+@sloth.slo name bar
+"""`, Syntax{
+			LineComments: []string{"#"},
+			DocStrings:   []Delimiters{{Start: `"""`, End: `"""`}},
+			Strings:      []Delimiters{{Start: `"`, End: `"`}},
+		})
+		assert.Equal(t, []string{"@sloth service foo\n@sloth.slo name bar\n"}, groups)
+	})
+
+	t.Run("Successfully ignore the docstring delimiters which don't start their line", func(t *testing.T) {
+		_, groups := scan(`query = """@sloth service foo"""
+	"""@sloth service bar"""`, Syntax{
+			DocStrings: []Delimiters{{Start: `"""`, End: `"""`}},
+			Strings:    []Delimiters{{Start: `"""`, End: `"""`}, {Start: `"`, End: `"`}},
+		})
+		assert.Equal(t, []string{"@sloth service bar\n"}, groups)
+	})
+
+	t.Run("Successfully ignore the shebang line", func(t *testing.T) {
+		_, groups := scan(`#!/usr/bin/env python3
+# @sloth service foo`, Syntax{LineComments: []string{"#"}})
+		assert.Equal(t, []string{"@sloth service foo\n"}, groups)
+	})
+
+	t.Run("Successfully position the comments at their text", func(t *testing.T) {
+		fset := token.NewFileSet()
+		groups, err := Language{Syntax: cSyntax}.Comments(fset, "test.src", []byte(`fn()
+/*
+  @sloth service foo */`))
+		require.NoError(t, err)
+		require.Len(t, groups, 1)
+		require.Len(t, groups[0].List, 2)
+		assert.Equal(t, "test.src:2:3", fset.Position(groups[0].List[0].Pos()).String())
+		assert.Equal(t, "test.src:3:1", fset.Position(groups[0].List[1].Pos()).String())
+	})
+
+	t.Run("Successfully return no comments for an empty source", func(t *testing.T) {
+		_, groups := scan(``, cSyntax)
+		assert.Empty(t, groups)
+	})
+}
@@ -0,0 +1,3 @@
+// @sloth.slo name chat-gpt-availability
+// @sloth.slo objective 95.0
+int logins;
@@ -0,0 +1,2 @@
+/* @sloth service chatgpt */
+int main() { return 0; }
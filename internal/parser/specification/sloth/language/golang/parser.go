@@ -11,27 +11,27 @@ import (
 	"path/filepath"
 	"strings"
 
+	multierr "github.com/hashicorp/go-multierror"
 	"github.com/juju/errors"
-	k8sloth "github.com/slok/sloth/pkg/kubernetes/api/sloth/v1"
-	sloth "github.com/slok/sloth/pkg/prometheus/api/v1"
+	"github.com/slosive/sloscribe/internal/diagnostics"
 	"github.com/slosive/sloscribe/internal/logging"
-	"github.com/slosive/sloscribe/internal/parser/specification/sloth/grammar"
-	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/slosive/sloscribe/internal/parser/specification/sloth/collector"
+	"github.com/slosive/sloscribe/internal/parser/specification/sloth/language/comments"
 )
 
 type parser struct {
-	// specs contains references to all the service specifications that have been parsed
-	specs map[string]any
-	// current references the current service specification being parsed
-	current any
+	// collector collects the sloth annotations found in the parsed comments into service specifications
+	collector *collector.Collector
 	// sourceFile is the path to the target file to be parsed, i.e: -f file.go
 	sourceFile string
 	// sourceContent is the reader to the content to be parsed
 	sourceContent io.ReadCloser
 	includedDirs  []string
 	logger        *logging.Logger
-	// kubernetes tells the parser to parser the sloth annotations and output a kubernetes specification of the service
-	kubernetes bool
+	// strict tells the parser to return the problems found while parsing as an error
+	strict bool
+	// problems aggregates the warnings logged while parsing
+	problems error
 }
 
 // Options contains the configuration options available to the Parser
@@ -44,6 +44,16 @@ type Options struct {
 	InputDirectories []string
 	// Kubernetes tells the parser to parser the sloth annotations and output a kubernetes specification of the service
 	Kubernetes bool
+	// Reporter receives the diagnostics of the sloth annotations, these are logged as warnings if nil
+	Reporter diagnostics.Reporter
+	// Positions receives the positions of the annotations declaring the parsed SLOs, if set
+	Positions *diagnostics.Positions
+	// Strict tells the parser to return every warning as an error, aggregated in a diagnostics.StrictError
+	Strict bool
+	// ValidateQueries tells the parser to validate the PromQL queries of the SLIs
+	ValidateQueries bool
+	// Variables are the annotation variables overriding the ones declared with @sloth.var
+	Variables map[string]string
 }
 
 func NewOptions() *Options {
@@ -70,19 +80,56 @@ func NewParser(opts *Options) *parser {
 	sourceContent := opts.SourceContent
 
 	return &parser{
-		specs:         map[string]any{},
-		current:       nil,
+		collector:     collector.New(logger, opts.Kubernetes).WithReporter(opts.Reporter).WithQueryValidation(opts.ValidateQueries).WithVariables(opts.Variables).WithPositions(opts.Positions),
 		sourceFile:    sourceFile,
 		sourceContent: sourceContent,
 		includedDirs:  dirs,
 		logger:        logger,
-		kubernetes:    opts.Kubernetes,
+		strict:        opts.Strict,
 	}
 }
 
-// getAllGoPackages fetches all the available golang packages in the target directory and subdirectories
-func getAllGoPackages(dir string) (map[string]*ast.Package, error) {
-	fset := token.NewFileSet()
+// Language is the go language as parsed by the comments parser, used when parsing source files in multiple languages.
+// The comments are extracted by go/parser.
+var Language = comments.Language{
+	Name:         "go",
+	Extract:      extractComments,
+	Extensions:   []string{".go"},
+	Entrypoints:  []string{"main.go"},
+	ExcludedDirs: []string{"vendor", ".git"},
+	Symbols:      sourceSymbols,
+}
+
+// extractComments returns the comment groups in the go source file, positioned like the comments scanned in the other languages:
+// each line of a comment is positioned at its text, see comments.NewComment
+func extractComments(fset *token.FileSet, filename string, src []byte) ([]*ast.CommentGroup, error) {
+	file, err := goparser.ParseFile(fset, filename, src, goparser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	tokenFile := fset.File(file.Pos())
+	groups := make([]*ast.CommentGroup, 0, len(file.Comments))
+	for _, group := range file.Comments {
+		var list []*ast.Comment
+		for _, comment := range group.List {
+			text := comment.Text[2:]
+			if strings.HasPrefix(comment.Text, "/*") {
+				text = strings.TrimSuffix(text, "*/")
+			}
+			offset := tokenFile.Offset(comment.Slash) + 2
+			for _, line := range strings.Split(text, "\n") {
+				list = append(list, comments.NewComment(tokenFile, offset, line))
+				offset += len(line) + 1
+			}
+		}
+		groups = append(groups, &ast.CommentGroup{List: list})
+	}
+	return groups, nil
+}
+
+// getAllGoPackages fetches all the available golang packages in the target directory and subdirectories,
+// the packages files are positioned in fset
+func getAllGoPackages(fset *token.FileSet, dir string) (map[string]*ast.Package, error) {
 	pkgs, err := goparser.ParseDir(fset, dir, nil, goparser.ParseComments)
 	if err != nil {
 		return map[string]*ast.Package{}, err
@@ -115,239 +162,33 @@ func getAllGoPackages(dir string) (map[string]*ast.Package, error) {
 }
 
 // getFile returns the ast go file struct given filename or an io.Reader. If an io.Reader is passed it will take precedence
-// over the filename
-func getFile(name string, file io.ReadCloser) (*ast.File, error) {
-	fset := token.NewFileSet()
+// over the filename. The file is positioned in fset
+func getFile(fset *token.FileSet, name string, file io.ReadCloser) (*ast.File, error) {
 	if file != nil {
 		defer file.Close()
 	}
 	return goparser.ParseFile(fset, name, file, goparser.ParseComments)
 }
 
-func (p *parser) parseK8SlothAnnotations(comments ...*ast.CommentGroup) error {
-	if p.current == nil {
-		p.current = &k8sloth.PrometheusServiceLevel{
-			TypeMeta: v1.TypeMeta{
-				Kind:       "PrometheusServiceLevel",
-				APIVersion: "sloth.slok.dev/v1",
-			},
-			ObjectMeta: v1.ObjectMeta{
-				Labels: map[string]string{},
-			},
-			Spec: k8sloth.PrometheusServiceLevelSpec{
-				Service: "",
-				Labels:  map[string]string{},
-				SLOs:    nil,
-			},
-		}
-	}
-
-	p.logger.Debug("Current service being parsed", "service", p.current.(*k8sloth.PrometheusServiceLevel).Spec.Service)
-	for _, comment := range comments {
-		if !strings.HasPrefix(strings.TrimSpace(comment.Text()), "@sloth") {
-			continue
-		}
-		p.logger.Debug("Parsing", "comment", strings.TrimSpace(comment.Text()))
-		// partialServiceSpec contains the partially parsed sloth Specification for a given comment group
-		// this means the parsed spec will only contain data for the fields that are present in the comments, making the spec only partially accurate
-		partialServiceSpec, err := grammar.Eval(strings.TrimSpace(comment.Text()))
-		if err != nil {
-			p.warn(err)
-			continue
-		}
-
-		// if the comment group contains a reference to the service name
-		// check if service was parsed before else add it the collection of specs.
-		// Set the found service spec as the current service spec.
-		if partialServiceSpec.Service != "" {
-			if p.current != nil && (p.current.(*k8sloth.PrometheusServiceLevel).Name == partialServiceSpec.Service || p.current.(*k8sloth.PrometheusServiceLevel).Name == "") {
-				p.specs[partialServiceSpec.Service] = p.current
-			}
-			spec, ok := p.specs[partialServiceSpec.Service]
-			if !ok {
-				tmpSpec := &k8sloth.PrometheusServiceLevel{
-					TypeMeta: v1.TypeMeta{
-						Kind:       "PrometheusServiceLevel",
-						APIVersion: "sloth.slok.dev/v1",
-					},
-					ObjectMeta: v1.ObjectMeta{
-						Name:   partialServiceSpec.Service,
-						Labels: map[string]string{},
-					},
-					Spec: k8sloth.PrometheusServiceLevelSpec{
-						Service: partialServiceSpec.Service,
-						Labels:  partialServiceSpec.Labels,
-						SLOs:    toKubernetes(partialServiceSpec.SLOs...),
-					},
-				}
-				p.specs[partialServiceSpec.Service] = tmpSpec
-				p.current = tmpSpec
-			} else {
-				p.current = spec.(*k8sloth.PrometheusServiceLevel)
-			}
-		}
-
-		if p.current.(*k8sloth.PrometheusServiceLevel).Name == "" {
-			p.current.(*k8sloth.PrometheusServiceLevel).Name = partialServiceSpec.Service
-		}
-
-		for key, label := range partialServiceSpec.Labels {
-			p.current.(*k8sloth.PrometheusServiceLevel).Labels[key] = label
-		}
-
-		if p.current.(*k8sloth.PrometheusServiceLevel).Spec.Service == "" {
-			p.current.(*k8sloth.PrometheusServiceLevel).Spec.Service = partialServiceSpec.Service
-		}
-
-		for key, label := range partialServiceSpec.Labels {
-			p.current.(*k8sloth.PrometheusServiceLevel).Spec.Labels[key] = label
-		}
-
-		for _, slo := range toKubernetes(partialServiceSpec.SLOs...) {
-			exist := false
-			for _, currSLO := range p.current.(*k8sloth.PrometheusServiceLevel).Spec.SLOs {
-				if currSLO.Name == slo.Name {
-					exist = true
-					break
-				}
-			}
-
-			if !exist {
-				p.current.(*k8sloth.PrometheusServiceLevel).Spec.SLOs = append(p.current.(*k8sloth.PrometheusServiceLevel).Spec.SLOs, slo)
-			}
-		}
-	}
-	return nil
-}
-
-func toKubernetes(slos ...sloth.SLO) []k8sloth.SLO {
-	var k8SLOs []k8sloth.SLO
-	for _, slo := range slos {
-		result := k8sloth.SLO{
-			Name:        slo.Name,
-			Description: slo.Description,
-			Objective:   slo.Objective,
-			Labels:      slo.Labels,
-			SLI: k8sloth.SLI{
-				Raw:    (*k8sloth.SLIRaw)(slo.SLI.Raw),
-				Events: (*k8sloth.SLIEvents)(slo.SLI.Events),
-			},
-			Alerting: k8sloth.Alerting{
-				Name:        slo.Alerting.Name,
-				Labels:      slo.Alerting.Labels,
-				Annotations: slo.Alerting.Annotations,
-				PageAlert: k8sloth.Alert{
-					Disable:     slo.Alerting.PageAlert.Disable,
-					Labels:      slo.Alerting.PageAlert.Labels,
-					Annotations: slo.Alerting.PageAlert.Annotations,
-				},
-				TicketAlert: k8sloth.Alert{
-					Disable:     slo.Alerting.TicketAlert.Disable,
-					Labels:      slo.Alerting.TicketAlert.Labels,
-					Annotations: slo.Alerting.TicketAlert.Annotations,
-				},
-			},
-		}
-
-		k8SLOs = append(k8SLOs, result)
-	}
-
-	return k8SLOs
-}
-
-// parseSlothAnnotations parses the source code comments for sloth annotations using the sloth grammar.
-// It expects only SLO definition per comment group
-func (p *parser) parseSlothAnnotations(comments ...*ast.CommentGroup) error {
-	if p.current == nil {
-		p.current = &sloth.Spec{
-			Version: "",
-			Service: "",
-			Labels:  make(map[string]string),
-			SLOs:    make([]sloth.SLO, 0),
-		}
-	}
-
-	p.logger.Debug("Current service being parsed", "service", p.current.(*sloth.Spec).Service)
-
-	for _, comment := range comments {
-		if !strings.HasPrefix(strings.TrimSpace(comment.Text()), "@sloth") {
-			continue
-		}
-		p.logger.Debug("Parsing", "comment", strings.TrimSpace(comment.Text()))
-		// partialServiceSpec contains the partially parsed sloth Specification for a given comment group
-		// this means the parsed spec will only contain data for the fields that are present in the comments, making the spec only partially accurate
-		partialServiceSpec, err := grammar.Eval(strings.TrimSpace(comment.Text()))
-		if err != nil {
-			p.warn(err)
-			continue
-		}
-
-		// if the comment group contains a reference to the service name
-		// check if service was parsed before else add it the collection of specs.
-		// Set the found service spec as the current service spec.
-		if partialServiceSpec.Service != "" {
-			if p.current != nil && (p.current.(*sloth.Spec).Service == partialServiceSpec.Service || p.current.(*sloth.Spec).Service == "") {
-				p.specs[partialServiceSpec.Service] = p.current
-			}
-			spec, ok := p.specs[partialServiceSpec.Service]
-			if !ok {
-				p.specs[partialServiceSpec.Service] = partialServiceSpec
-				p.current = partialServiceSpec
-			} else {
-				p.current = spec.(*sloth.Spec)
-			}
-		}
-
-		if p.current.(*sloth.Spec).Service == "" {
-			p.current.(*sloth.Spec).Service = partialServiceSpec.Service
-		}
-		if p.current.(*sloth.Spec).Version == "" {
-			p.current.(*sloth.Spec).Version = partialServiceSpec.Version
-		}
-
-		for key, label := range partialServiceSpec.Labels {
-			p.current.(*sloth.Spec).Labels[key] = label
-		}
-
-		for _, slo := range partialServiceSpec.SLOs {
-			exist := false
-			for _, currSLO := range p.current.(*sloth.Spec).SLOs {
-				if currSLO.Name == slo.Name {
-					exist = true
-					break
-				}
-			}
-
-			if !exist {
-				p.current.(*sloth.Spec).SLOs = append(p.current.(*sloth.Spec).SLOs, slo)
-			}
-		}
-	}
-	return nil
-}
-
 // Parse will parse the source code for sloth annotations.
 // In case of error during parsing, Parse returns an empty sloth.Spec
 func (p *parser) Parse(ctx context.Context) (map[string]any, error) {
+	fset := token.NewFileSet()
+
 	// collect all sloth annotations from the file and add them to the spec struct
 	if p.sourceFile != "" || p.sourceContent != nil {
-		file, err := getFile(p.sourceFile, p.sourceContent)
+		file, err := getFile(fset, p.sourceFile, p.sourceContent)
 		if err != nil {
 			// error hard as we can't extract more data for the spec
 			return nil, err
 		}
 		p.logger.Debug("Parsing source code", "file", file.Name)
-		if p.kubernetes {
-			if err := p.parseK8SlothAnnotations(file.Comments...); err != nil {
-				return nil, err
-			}
-		} else {
-			if err := p.parseSlothAnnotations(file.Comments...); err != nil {
-				return nil, err
-			}
+		p.collector.WithSymbols(newSymbols(fset, map[string]*ast.File{p.sourceFile: file}))
+		if err := p.collector.Collect(fset, file.Comments...); err != nil {
+			return nil, err
 		}
 		p.logger.Debug("Parsed source code", "file", file.Name)
-		return p.specs, nil
+		return p.result()
 	}
 
 	applicationPackages := map[string]*ast.Package{}
@@ -364,7 +205,7 @@ func (p *parser) Parse(ctx context.Context) (map[string]any, error) {
 			continue
 		}
 
-		foundPkgs, err := getAllGoPackages(dir)
+		foundPkgs, err := getAllGoPackages(fset, dir)
 		if err != nil {
 			p.warn(err)
 			continue
@@ -377,22 +218,24 @@ func (p *parser) Parse(ctx context.Context) (map[string]any, error) {
 		}
 	}
 
+	// declare the annotation variables of all the packages first, these can be referenced from any file
+	for _, pkg := range applicationPackages {
+		for _, file := range pkg.Files {
+			p.collector.Declare(fset, file.Comments...)
+		}
+	}
+
 	// collect all sloth annotations from packages and add them to the spec struct
 	for _, pkg := range applicationPackages {
+		// the annotations can reference the constants and metrics of their package
+		p.collector.WithSymbols(newSymbols(fset, pkg.Files))
 		// Prioritise parsing the main.go if present in the package
 		for filename, file := range pkg.Files {
 			if strings.Contains(filename, "main.go") {
 				p.logger.Debug("Parsing source code", "package", pkg.Name, "file", filename)
-				if p.kubernetes {
-					if err := p.parseK8SlothAnnotations(file.Comments...); err != nil {
-						p.warn(err)
-						break
-					}
-				} else {
-					if err := p.parseSlothAnnotations(file.Comments...); err != nil {
-						p.warn(err)
-						break
-					}
+				if err := p.collector.Collect(fset, file.Comments...); err != nil {
+					p.warn(err)
+					break
 				}
 				p.logger.Debug("Parsed source code", "package", pkg.Name, "file", filename)
 				break
@@ -412,41 +255,34 @@ func (p *parser) Parse(ctx context.Context) (map[string]any, error) {
 			default:
 			}
 
-			if p.kubernetes {
-				if err := p.parseK8SlothAnnotations(file.Comments...); err != nil {
-					p.warn(err)
-					continue
-				}
-			} else {
-				if err := p.parseSlothAnnotations(file.Comments...); err != nil {
-					p.warn(err)
-					continue
-				}
+			if err := p.collector.Collect(fset, file.Comments...); err != nil {
+				p.warn(err)
+				continue
 			}
 			p.logger.Debug("Parsed source code", "package", pkg.Name, "file", filename)
 		}
 	}
 
-	// print statistics
-	p.stats()
+	return p.result()
+}
 
-	return p.specs, nil
+// result returns the collected specifications.
+// In strict mode, the problems found by the parser and the collector are returned in a diagnostics.StrictError.
+func (p *parser) result() (map[string]any, error) {
+	problems := multierr.Append(p.problems, p.collector.Finish())
+	// print statistics, once the SLOs using a template are instantiated
+	p.collector.Stats()
+	if p.strict {
+		if err := diagnostics.Strict(problems.ErrorOrNil()); err != nil {
+			return nil, err
+		}
+	}
+	return p.collector.Specs(), nil
 }
 
 func (p *parser) warn(err error, keyValues ...interface{}) {
+	p.problems = multierr.Append(p.problems, err)
 	if p.logger != nil {
 		p.logger.Warn(err, keyValues...)
 	}
 }
-
-func (p *parser) stats() {
-	for _, spec := range p.specs {
-		if p.kubernetes {
-			s := spec.(*k8sloth.PrometheusServiceLevel)
-			p.logger.Info("Found", "service", s.Spec.Service, "SLOs", len(s.Spec.SLOs))
-		} else {
-			s := spec.(*sloth.Spec)
-			p.logger.Info("Found", "service", s.Service, "SLOs", len(s.SLOs))
-		}
-	}
-}
@@ -1,23 +1,28 @@
 package golang
 
 import (
-	"go/ast"
+	"context"
+	"go/token"
 	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
-	k8sloth "github.com/slok/sloth/pkg/kubernetes/api/sloth/v1"
 	sloth "github.com/slok/sloth/pkg/prometheus/api/v1"
+	"github.com/slosive/sloscribe/internal/diagnostics"
+	"github.com/slosive/sloscribe/internal/parser/specification/sloth/collector"
+	"github.com/slosive/sloscribe/internal/parser/specification/sloth/language/comments"
+	"github.com/slosive/sloscribe/internal/parser/specification/sloth/language/python"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestGetPackages(t *testing.T) {
 	t.Parallel()
 	t.Run("Successfully return all the go packages in the current and subdirectories", func(t *testing.T) {
 		exp := []string{"golang", "testdata", "fixtures"}
-		packages, err := getAllGoPackages("./.")
+		packages, err := getAllGoPackages(token.NewFileSet(), "./.")
 		require.NoError(t, err)
 		require.Len(t, packages, len(exp))
 
@@ -31,7 +36,7 @@ func TestGetPackages(t *testing.T) {
 
 	t.Run("Successfully return the go packages in testdata/fixtures", func(t *testing.T) {
 		exp := []string{"fixtures"}
-		packages, err := getAllGoPackages("./testdata/fixtures")
+		packages, err := getAllGoPackages(token.NewFileSet(), "./testdata/fixtures")
 		require.NoError(t, err)
 		require.Len(t, packages, len(exp))
 
@@ -40,12 +45,12 @@ func TestGetPackages(t *testing.T) {
 	})
 
 	t.Run("Fails to return the go package in a non go package", func(t *testing.T) {
-		_, err := getAllGoPackages("./testdata/gofake")
+		_, err := getAllGoPackages(token.NewFileSet(), "./testdata/gofake")
 		require.Error(t, err)
 	})
 
 	t.Run("Fails to return the go package in a non-existing directory", func(t *testing.T) {
-		_, err := getAllGoPackages("./testdata/non-existing")
+		_, err := getAllGoPackages(token.NewFileSet(), "./testdata/non-existing")
 		require.Error(t, err)
 	})
 }
@@ -53,16 +58,16 @@ func TestGetPackages(t *testing.T) {
 func TestGetFile(t *testing.T) {
 	t.Parallel()
 	t.Run("Successfully get comments from testdata/fixtures/fixture.go", func(t *testing.T) {
-		f, err := getFile("./testdata/fixtures/fixture.go", nil)
+		f, err := getFile(token.NewFileSet(), "./testdata/fixtures/fixture.go", nil)
 		require.NoError(t, err)
 		assert.Equal(t, "Package fixtures contains testdata\n", f.Comments[0].Text())
 	})
 	t.Run("Fail to get comments from non existing file testdata/fixtures/fake.go", func(t *testing.T) {
-		_, err := getFile("./testdata/fixtures/fake.go", nil)
+		_, err := getFile(token.NewFileSet(), "./testdata/fixtures/fake.go", nil)
 		require.Error(t, err)
 	})
 	t.Run("Successfully get comments from string reader", func(t *testing.T) {
-		f, err := getFile("", io.NopCloser(strings.NewReader(`
+		f, err := getFile(token.NewFileSet(), "", io.NopCloser(strings.NewReader(`
 // Package fixtures contains testdata
 package fixtures
 `)))
@@ -70,7 +75,7 @@ package fixtures
 		assert.Equal(t, "Package fixtures contains testdata\n", f.Comments[0].Text())
 	})
 	t.Run("Successfully get comments from string reader if filename is passed", func(t *testing.T) {
-		f, err := getFile("./testdata/fixtures/fake.go", io.NopCloser(strings.NewReader(`
+		f, err := getFile(token.NewFileSet(), "./testdata/fixtures/fake.go", io.NopCloser(strings.NewReader(`
 // Package fixtures contains testdata
 package fixtures
 `)))
@@ -78,946 +83,317 @@ package fixtures
 		assert.Equal(t, "Package fixtures contains testdata\n", f.Comments[0].Text())
 	})
 	t.Run("Fails to get comments from empty string reader", func(t *testing.T) {
-		_, err := getFile("", io.NopCloser(strings.NewReader(``)))
+		_, err := getFile(token.NewFileSet(), "", io.NopCloser(strings.NewReader(``)))
 		require.Error(t, err)
 	})
 }
 
-func TestParseAnnotations(t *testing.T) {
+func TestLanguage(t *testing.T) {
 	t.Parallel()
+	t.Run("Successfully extract the comments from a go source file", func(t *testing.T) {
+		src := []byte(`
+// Package fixtures contains testdata
+package fixtures
+`)
+		comments, err := Language.Comments(token.NewFileSet(), "fixture.go", src)
+		require.NoError(t, err)
+		require.Len(t, comments, 1)
+		assert.Equal(t, "Package fixtures contains testdata\n", comments[0].Text())
+	})
+	t.Run("Fails to extract the comments from an invalid go source file", func(t *testing.T) {
+		_, err := Language.Comments(token.NewFileSet(), "fixture.go", []byte(`not a go file`))
+		require.Error(t, err)
+	})
+}
 
-	t.Run("Successfully parse the sloth annotations per single commentGroup, should return 1 specification", func(t *testing.T) {
-		parser := NewParser(nil)
-		require.NoError(t, parser.parseSlothAnnotations(&ast.CommentGroup{List: []*ast.Comment{{Text: `@sloth service foobar`}}},
-			&ast.CommentGroup{List: []*ast.Comment{
-				{
-					Text: `@sloth.slo name availability`,
-				},
-				{
-					Text: `@sloth.slo description availability SLO`,
-				},
-				{
-					Text: `@sloth.slo objective 95.0`,
-				},
-			}}))
-		assert.Equal(t, "foobar", (parser.specs["foobar"].(*sloth.Spec)).Service)
-		assert.Equal(t, sloth.SLO{
-			Name:        "availability",
-			Description: "availability SLO",
-			Objective:   95.0,
-			Labels:      make(map[string]string),
-			SLI:         sloth.SLI{},
-			Alerting:    sloth.Alerting{},
-		}, (parser.specs["foobar"].(*sloth.Spec)).SLOs[0])
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	src := `package main
+// @sloth service chatgpt
+// @sloth.slo name availability
+// @sloth.slo bogus true
+func main() {}
+`
+
+	t.Run("Successfully parse the annotations with warnings", func(t *testing.T) {
+		opts := NewOptions()
+		opts.SourceContent = io.NopCloser(strings.NewReader(src))
+		specs, err := NewParser(opts).Parse(context.Background())
+		require.NoError(t, err)
+		assert.Contains(t, specs, "chatgpt")
 	})
 
-	t.Run("Successfully parse the sloth annotations per single commentGroup, should return 1 specification", func(t *testing.T) {
-		parser := NewParser(nil)
-		require.NoError(t, parser.parseSlothAnnotations(&ast.CommentGroup{List: []*ast.Comment{
-			{
-				Text: `@sloth service foobar`,
-			},
-			{
-				Text: `@sloth.slo name availability`,
-			},
-			{
-				Text: `@sloth.slo description availability SLO`,
-			},
-			{
-				Text: `@sloth.slo objective 95.0`,
-			},
-		}}))
-		assert.Equal(t, "foobar", (parser.specs["foobar"].(*sloth.Spec)).Service)
-		assert.Equal(t, sloth.SLO{
-			Name:        "availability",
-			Description: "availability SLO",
-			Objective:   95.0,
-			Labels:      make(map[string]string),
-			SLI:         sloth.SLI{},
-			Alerting:    sloth.Alerting{},
-		}, (parser.specs["foobar"].(*sloth.Spec)).SLOs[0])
+	t.Run("Fail to parse the annotations with warnings in strict mode", func(t *testing.T) {
+		opts := NewOptions()
+		opts.SourceContent = io.NopCloser(strings.NewReader(src))
+		opts.Strict = true
+		specs, err := NewParser(opts).Parse(context.Background())
+		assert.Nil(t, specs)
+
+		var strictErr *diagnostics.StrictError
+		require.ErrorAs(t, err, &strictErr)
+		assert.Contains(t, strictErr.Problems.Error(), `unknown attribute "bogus" in @sloth.slo`)
 	})
+}
 
-	t.Run("Successfully parse sloth service if service name is defined after SLO definition", func(t *testing.T) {
-		parser := NewParser(nil)
-		comments := []*ast.CommentGroup{
-			{List: []*ast.Comment{
-				{
-					Text: `@sloth.slo name availability`,
-				},
-				{
-					Text: `@sloth.slo description availability SLO for foobar service`,
-				},
-				{
-					Text: `@sloth.slo objective 95.0`,
-				},
-			}},
-			{List: []*ast.Comment{
-				{
-					Text: `@sloth service foobar`,
-				},
-			}},
-		}
-		require.NoError(t, parser.parseSlothAnnotations(comments...))
-		require.Len(t, parser.specs, 1)
-		resultSpec := parser.specs
+func TestConstants(t *testing.T) {
+	t.Parallel()
 
-		expected := []*sloth.Spec{
-			{
-				Version: sloth.Version,
-				Service: "foobar",
-				Labels:  make(map[string]string),
-				SLOs: []sloth.SLO{
-					{
-						Name:        "availability",
-						Description: "availability SLO for foobar service",
-						Objective:   95.0,
-						Labels:      make(map[string]string),
-						SLI: sloth.SLI{
-							Raw:    nil,
-							Events: nil,
-							Plugin: nil,
-						},
-						Alerting: sloth.Alerting{
-							Name:        "",
-							Labels:      nil,
-							Annotations: nil,
-							PageAlert: sloth.Alert{
-								Disable:     false,
-								Labels:      nil,
-								Annotations: nil,
-							},
-							TicketAlert: sloth.Alert{
-								Disable:     false,
-								Labels:      nil,
-								Annotations: nil,
-							},
-						},
-					},
-				},
-			},
-		}
+	sources := map[string][]byte{
+		"slo.go": []byte(`package metrics
 
-		for _, exp := range expected {
-			actual, ok := resultSpec[exp.Service]
-			require.True(t, ok)
-			assert.Equal(t, exp, actual)
-		}
-	})
+// @sloth service chatgpt
+// @sloth.slo name availability
+// @sloth.slo objective {{go "AvailabilityTarget"}}
+// @sloth.sli error_ratio_query sum(rate({{go "ErrorsMetric"}}[{{.window}}]))
+var requests = 1
+`),
+		"consts.go": []byte(`package metrics
 
-	t.Run("Successfully parse multiple Sloth services with single SLO defined, should return 3 specifications", func(t *testing.T) {
-		parser := NewParser(nil)
-		comments := []*ast.CommentGroup{
-			{List: []*ast.Comment{
-				{
-					Text: `@sloth service foobar`,
-				},
-				{
-					Text: `@sloth.slo name availability`,
-				},
-				{
-					Text: `@sloth.slo description availability SLO for foobar service`,
-				},
-				{
-					Text: `@sloth.slo objective 95.0`,
-				},
-			}},
-			{List: []*ast.Comment{
-				{
-					Text: `@sloth service foo`,
-				},
-				{
-					Text: `@sloth.slo name availability`,
-				},
-				{
-					Text: `@sloth.slo description availability SLO for foo service`,
-				},
-				{
-					Text: `@sloth.slo objective 95.0`,
-				},
-			}},
-			{List: []*ast.Comment{
-				{
-					Text: `@sloth service bar`,
-				},
-				{
-					Text: `@sloth.slo name availability`,
-				},
-				{
-					Text: `@sloth.slo description availability SLO for bar service`,
-				},
-				{
-					Text: `@sloth.slo objective 95.0`,
-				},
-			}},
-		}
-		require.NoError(t, parser.parseSlothAnnotations(comments...))
-		require.Len(t, parser.specs, 3)
-		resultSpec := parser.specs
+import "time"
 
-		expected := []*sloth.Spec{
-			{
-				Version: sloth.Version,
-				Service: "foo",
-				Labels:  make(map[string]string),
-				SLOs: []sloth.SLO{
-					{
-						Name:        "availability",
-						Description: "availability SLO for foo service",
-						Objective:   95.0,
-						Labels:      make(map[string]string),
-						SLI: sloth.SLI{
-							Raw:    nil,
-							Events: nil,
-							Plugin: nil,
-						},
-						Alerting: sloth.Alerting{
-							Name:        "",
-							Labels:      nil,
-							Annotations: nil,
-							PageAlert: sloth.Alert{
-								Disable:     false,
-								Labels:      nil,
-								Annotations: nil,
-							},
-							TicketAlert: sloth.Alert{
-								Disable:     false,
-								Labels:      nil,
-								Annotations: nil,
-							},
-						},
-					},
-				},
-			},
-			{
-				Version: sloth.Version,
-				Service: "bar",
-				Labels:  make(map[string]string),
-				SLOs: []sloth.SLO{
-					{
-						Name:        "availability",
-						Description: "availability SLO for bar service",
-						Objective:   95.0,
-						Labels:      make(map[string]string),
-						SLI: sloth.SLI{
-							Raw:    nil,
-							Events: nil,
-							Plugin: nil,
-						},
-						Alerting: sloth.Alerting{
-							Name:        "",
-							Labels:      nil,
-							Annotations: nil,
-							PageAlert: sloth.Alert{
-								Disable:     false,
-								Labels:      nil,
-								Annotations: nil,
-							},
-							TicketAlert: sloth.Alert{
-								Disable:     false,
-								Labels:      nil,
-								Annotations: nil,
-							},
-						},
-					},
-				},
-			},
-			{
-				Version: sloth.Version,
-				Service: "foobar",
-				Labels:  make(map[string]string),
-				SLOs: []sloth.SLO{
-					{
-						Name:        "availability",
-						Description: "availability SLO for foobar service",
-						Objective:   95.0,
-						Labels:      make(map[string]string),
-						SLI: sloth.SLI{
-							Raw:    nil,
-							Events: nil,
-							Plugin: nil,
-						},
-						Alerting: sloth.Alerting{
-							Name:        "",
-							Labels:      nil,
-							Annotations: nil,
-							PageAlert: sloth.Alert{
-								Disable:     false,
-								Labels:      nil,
-								Annotations: nil,
-							},
-							TicketAlert: sloth.Alert{
-								Disable:     false,
-								Labels:      nil,
-								Annotations: nil,
-							},
-						},
-					},
-				},
-			},
-		}
+const (
+	AvailabilityTarget = 99.5
+	ErrorsMetric       = "chatgpt_" + "errors_total"
+	Window             = time.Hour
+)
+`),
+		"consts_test.go": []byte(`package metrics_test
 
-		for _, exp := range expected {
-			actual, ok := resultSpec[exp.Service]
-			require.True(t, ok)
-			assert.Equal(t, exp, actual)
-		}
-	})
+const AvailabilityTarget = 50
+`),
+	}
 
-	t.Run("Successfully parse multiple Sloth services with multiple SLOs defined, should return 3 specifications", func(t *testing.T) {
-		parser := NewParser(nil)
-		comments := []*ast.CommentGroup{
-			{List: []*ast.Comment{
-				{
-					Text: `@sloth service foobar`,
-				},
-				{
-					Text: `@sloth.slo name availability`,
-				},
-				{
-					Text: `@sloth.slo description availability SLO for foobar service`,
-				},
-				{
-					Text: `@sloth.slo objective 95.0`,
-				},
-			}},
-			{List: []*ast.Comment{
-				{
-					Text: `@sloth.slo name correctness`,
-				},
-				{
-					Text: `@sloth.slo description correctness SLO for foobar service`,
-				},
-				{
-					Text: `@sloth.slo objective 55.0`,
-				},
-			}},
-			{List: []*ast.Comment{
-				{
-					Text: `@sloth service foo`,
-				},
-				{
-					Text: `@sloth.slo name availability`,
-				},
-				{
-					Text: `@sloth.slo description availability SLO for foo service`,
-				},
-				{
-					Text: `@sloth.slo objective 95.0`,
-				},
-			}},
-			{List: []*ast.Comment{
-				{
-					Text: `@sloth.slo name correctness`,
-				},
-				{
-					Text: `@sloth.slo description correctness SLO for foo service`,
-				},
-				{
-					Text: `@sloth.slo objective 85.0`,
-				},
-			}},
-			{List: []*ast.Comment{
-				{
-					Text: `@sloth.slo name freshness`,
-				},
-				{
-					Text: `@sloth.slo description freshness SLO for foo service`,
-				},
-				{
-					Text: `@sloth.slo objective 99.999`,
-				},
-			}},
-			{List: []*ast.Comment{
-				{
-					Text: `@sloth service bar`,
-				},
-				{
-					Text: `@sloth.slo name availability`,
-				},
-				{
-					Text: `@sloth.slo description availability SLO for bar service`,
-				},
-				{
-					Text: `@sloth.slo objective 95.0`,
-				},
-			}},
-		}
-		require.NoError(t, parser.parseSlothAnnotations(comments...))
-		require.Len(t, parser.specs, 3)
-		resultSpec := parser.specs
+	t.Run("Successfully resolve the constants declared in the package of the source file", func(t *testing.T) {
+		symbols := sourceSymbols(sources)
+		pos := token.Position{Filename: "slo.go"}
 
-		expected := []*sloth.Spec{
-			{
-				Version: sloth.Version,
-				Service: "foo",
-				Labels:  make(map[string]string),
-				SLOs: []sloth.SLO{
-					{
-						Name:        "availability",
-						Description: "availability SLO for foo service",
-						Objective:   95.0,
-						Labels:      make(map[string]string),
-						SLI: sloth.SLI{
-							Raw:    nil,
-							Events: nil,
-							Plugin: nil,
-						},
-						Alerting: sloth.Alerting{
-							Name:        "",
-							Labels:      nil,
-							Annotations: nil,
-							PageAlert: sloth.Alert{
-								Disable:     false,
-								Labels:      nil,
-								Annotations: nil,
-							},
-							TicketAlert: sloth.Alert{
-								Disable:     false,
-								Labels:      nil,
-								Annotations: nil,
-							},
-						},
-					},
-					{
-						Name:        "correctness",
-						Description: "correctness SLO for foo service",
-						Objective:   85.0,
-						Labels:      make(map[string]string),
-						SLI: sloth.SLI{
-							Raw:    nil,
-							Events: nil,
-							Plugin: nil,
-						},
-						Alerting: sloth.Alerting{
-							Name:        "",
-							Labels:      nil,
-							Annotations: nil,
-							PageAlert: sloth.Alert{
-								Disable:     false,
-								Labels:      nil,
-								Annotations: nil,
-							},
-							TicketAlert: sloth.Alert{
-								Disable:     false,
-								Labels:      nil,
-								Annotations: nil,
-							},
-						},
-					},
-					{
-						Name:        "freshness",
-						Description: "freshness SLO for foo service",
-						Objective:   99.999,
-						Labels:      make(map[string]string),
-						SLI: sloth.SLI{
-							Raw:    nil,
-							Events: nil,
-							Plugin: nil,
-						},
-						Alerting: sloth.Alerting{
-							Name:        "",
-							Labels:      nil,
-							Annotations: nil,
-							PageAlert: sloth.Alert{
-								Disable:     false,
-								Labels:      nil,
-								Annotations: nil,
-							},
-							TicketAlert: sloth.Alert{
-								Disable:     false,
-								Labels:      nil,
-								Annotations: nil,
-							},
-						},
-					},
-				},
-			},
-			{
-				Version: sloth.Version,
-				Service: "bar",
-				Labels:  make(map[string]string),
-				SLOs: []sloth.SLO{
-					{
-						Name:        "availability",
-						Description: "availability SLO for bar service",
-						Objective:   95.0,
-						Labels:      make(map[string]string),
-						SLI: sloth.SLI{
-							Raw:    nil,
-							Events: nil,
-							Plugin: nil,
-						},
-						Alerting: sloth.Alerting{
-							Name:        "",
-							Labels:      nil,
-							Annotations: nil,
-							PageAlert: sloth.Alert{
-								Disable:     false,
-								Labels:      nil,
-								Annotations: nil,
-							},
-							TicketAlert: sloth.Alert{
-								Disable:     false,
-								Labels:      nil,
-								Annotations: nil,
-							},
-						},
-					},
-				},
-			},
-			{
-				Version: sloth.Version,
-				Service: "foobar",
-				Labels:  make(map[string]string),
-				SLOs: []sloth.SLO{
-					{
-						Name:        "availability",
-						Description: "availability SLO for foobar service",
-						Objective:   95.0,
-						Labels:      make(map[string]string),
-						SLI: sloth.SLI{
-							Raw:    nil,
-							Events: nil,
-							Plugin: nil,
-						},
-						Alerting: sloth.Alerting{
-							Name:        "",
-							Labels:      nil,
-							Annotations: nil,
-							PageAlert: sloth.Alert{
-								Disable:     false,
-								Labels:      nil,
-								Annotations: nil,
-							},
-							TicketAlert: sloth.Alert{
-								Disable:     false,
-								Labels:      nil,
-								Annotations: nil,
-							},
-						},
-					},
-					{
-						Name:        "correctness",
-						Description: "correctness SLO for foobar service",
-						Objective:   55.0,
-						Labels:      make(map[string]string),
-						SLI: sloth.SLI{
-							Raw:    nil,
-							Events: nil,
-							Plugin: nil,
-						},
-						Alerting: sloth.Alerting{
-							Name:        "",
-							Labels:      nil,
-							Annotations: nil,
-							PageAlert: sloth.Alert{
-								Disable:     false,
-								Labels:      nil,
-								Annotations: nil,
-							},
-							TicketAlert: sloth.Alert{
-								Disable:     false,
-								Labels:      nil,
-								Annotations: nil,
-							},
-						},
-					},
-				},
-			},
-		}
+		value, err := symbols.Constant(pos, "AvailabilityTarget")
+		require.NoError(t, err)
+		assert.Equal(t, "99.5", value)
 
-		for _, exp := range expected {
-			actual, ok := resultSpec[exp.Service]
-			require.True(t, ok)
-			assert.Equal(t, exp, actual)
-		}
+		value, err = symbols.Constant(pos, "ErrorsMetric")
+		require.NoError(t, err)
+		assert.Equal(t, "chatgpt_errors_total", value)
 	})
 
-	t.Run("Fail to parse the sloth spec SLO item if sloth annotation name for a given SLO is missing", func(t *testing.T) {
-		parser := NewParser(nil)
-		require.NoError(t, parser.parseSlothAnnotations(&ast.CommentGroup{List: []*ast.Comment{
-			{
-				Text: `@sloth service bar`,
-			},
-			{
-				Text: `@sloth.slo description availability SLO`,
-			},
-			{
-				Text: `@sloth.slo objective 95.0`,
-			},
-		}}))
-		assert.Len(t, (parser.specs["bar"].(*sloth.Spec)).SLOs, 0)
-	})
+	t.Run("Fail to resolve the identifiers which aren't constants of the package", func(t *testing.T) {
+		symbols := sourceSymbols(sources)
+		pos := token.Position{Filename: "slo.go"}
 
-	t.Run("Successfully parse and merge duplicate Sloth service", func(t *testing.T) {
-		parser := NewParser(nil)
-		comments := []*ast.CommentGroup{
-			{List: []*ast.Comment{
-				{
-					Text: `@sloth service foobar`,
-				},
-				{
-					Text: `@sloth.slo name availability`,
-				},
-				{
-					Text: `@sloth.slo description availability SLO for foobar service`,
-				},
-				{
-					Text: `@sloth.slo objective 95.0`,
-				},
-			}},
-			{List: []*ast.Comment{
-				{
-					Text: `@sloth service foobar`,
-				},
-				{
-					Text: `@sloth.slo name foobar_availability`,
-				},
-				{
-					Text: `@sloth.slo description availability SLO for foobar service`,
-				},
-				{
-					Text: `@sloth.slo objective 99.0`,
-				},
-			}},
-		}
-		require.NoError(t, parser.parseSlothAnnotations(comments...))
-		require.Len(t, parser.specs, 1)
-		resultSpec := parser.specs
+		_, err := symbols.Constant(pos, "Missing")
+		assert.EqualError(t, err, `Go constant "Missing" isn't declared in package metrics`)
+		_, err = symbols.Constant(pos, "requests")
+		assert.EqualError(t, err, `Go identifier "requests" of package metrics isn't a constant`)
+		_, err = symbols.Constant(pos, "Window")
+		assert.EqualError(t, err, `Go constant "Window" value can't be resolved, it may depend on an imported package`)
+	})
 
-		expected := []*sloth.Spec{
-			{
-				Version: sloth.Version,
-				Service: "foobar",
-				Labels:  make(map[string]string),
-				SLOs: []sloth.SLO{
-					{
-						Name:        "availability",
-						Description: "availability SLO for foobar service",
-						Objective:   95.0,
-						Labels:      make(map[string]string),
-						SLI: sloth.SLI{
-							Raw:    nil,
-							Events: nil,
-							Plugin: nil,
-						},
-						Alerting: sloth.Alerting{
-							Name:        "",
-							Labels:      nil,
-							Annotations: nil,
-							PageAlert: sloth.Alert{
-								Disable:     false,
-								Labels:      nil,
-								Annotations: nil,
-							},
-							TicketAlert: sloth.Alert{
-								Disable:     false,
-								Labels:      nil,
-								Annotations: nil,
-							},
-						},
-					},
-					{
-						Name:        "foobar_availability",
-						Description: "availability SLO for foobar service",
-						Objective:   99.0,
-						Labels:      make(map[string]string),
-						SLI: sloth.SLI{
-							Raw:    nil,
-							Events: nil,
-							Plugin: nil,
-						},
-						Alerting: sloth.Alerting{
-							Name:        "",
-							Labels:      nil,
-							Annotations: nil,
-							PageAlert: sloth.Alert{
-								Disable:     false,
-								Labels:      nil,
-								Annotations: nil,
-							},
-							TicketAlert: sloth.Alert{
-								Disable:     false,
-								Labels:      nil,
-								Annotations: nil,
-							},
-						},
-					},
-				},
-			},
-		}
+	t.Run("Successfully parse the annotations referencing the constants of the source file", func(t *testing.T) {
+		opts := NewOptions()
+		opts.SourceContent = io.NopCloser(strings.NewReader(string(sources["slo.go"]) + "\nconst AvailabilityTarget = 99.9\nconst ErrorsMetric = \"errors_total\"\n"))
+		specs, err := NewParser(opts).Parse(context.Background())
+		require.NoError(t, err)
 
-		for _, exp := range expected {
-			actual, ok := resultSpec[exp.Service]
-			require.True(t, ok)
-			assert.Equal(t, exp, actual)
-		}
+		spec, ok := specs["chatgpt"].(*sloth.Spec)
+		require.True(t, ok)
+		require.Len(t, spec.SLOs, 1)
+		assert.Equal(t, 99.9, spec.SLOs[0].Objective)
+		assert.Equal(t, "sum(rate(errors_total[{{.window}}]))", spec.SLOs[0].SLI.Raw.ErrorRatioQuery)
 	})
 }
 
-func TestParseK8SAnnotations(t *testing.T) {
+func TestMetrics(t *testing.T) {
 	t.Parallel()
 
-	t.Run("Successfully parse sloth service if service name is defined after slo definition", func(t *testing.T) {
-		parser := NewParser(nil)
-		comments := []*ast.CommentGroup{
-			{List: []*ast.Comment{
-				{
-					Text: `@sloth.slo name availability`,
-				},
-				{
-					Text: `@sloth.slo description availability SLO for foobar service`,
-				},
-				{
-					Text: `@sloth.slo objective 95.0`,
-				},
-			}},
-			{List: []*ast.Comment{
-				{
-					Text: `@sloth service foobar`,
-				},
-			}},
-		}
-		require.NoError(t, parser.parseK8SlothAnnotations(comments...))
-		require.Len(t, parser.specs, 1)
-		resultSpec := parser.specs
+	src := `package main
 
-		expected := []*k8sloth.PrometheusServiceLevel{
-			{
-				TypeMeta: v1.TypeMeta{
-					Kind:       "PrometheusServiceLevel",
-					APIVersion: "sloth.slok.dev/v1",
-				},
-				ObjectMeta: v1.ObjectMeta{
-					Name:   "foobar",
-					Labels: make(map[string]string),
-				},
-				Spec: k8sloth.PrometheusServiceLevelSpec{
-					Service: "foobar",
-					Labels:  make(map[string]string),
-					SLOs: []k8sloth.SLO{
-						{
-							Name:        "availability",
-							Description: "availability SLO for foobar service",
-							Objective:   95.0,
-							Labels:      make(map[string]string),
-							SLI: k8sloth.SLI{
-								Raw:    nil,
-								Events: nil,
-								Plugin: nil,
-							},
-							Alerting: k8sloth.Alerting{
-								Name:        "",
-								Labels:      nil,
-								Annotations: nil,
-								PageAlert: k8sloth.Alert{
-									Disable:     false,
-									Labels:      nil,
-									Annotations: nil,
-								},
-								TicketAlert: k8sloth.Alert{
-									Disable:     false,
-									Labels:      nil,
-									Annotations: nil,
-								},
-							},
-						},
-					},
-				},
-			},
-		}
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	prom "github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "chatgpt"
+
+// @sloth service chatgpt
+
+var (
+	// @sloth.slo name chat-gpt-availability
+	// @sloth.slo objective 95.0
+	// @sloth.sli error_query sum(rate({{.metrics.metricTenantFailedLogins}}{client="chat-gpt"}[{{.window}}]))
+	// @sloth.sli total_query sum(rate({{.metric}}[{{.window}}]))
+	metricTenantLogins = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "auth0",
+			Name:      "tenant_login_operations_total",
+		})
+	metricTenantFailedLogins = prom.With(prometheus.DefaultRegisterer).NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "auth0",
+			Name:      "tenant_failed_login_operations_total",
+		}, []string{"client"})
+	requests = prometheus.NewHistogram(prometheus.HistogramOpts{Name: "http_request_duration_seconds"})
+)
+
+// @sloth.slo name latency
+// @sloth.sli error_ratio_query {{.metric}}
+func main() {}
+`
+
+	t.Run("Successfully resolve the metrics of the annotated declaration and of the package variables", func(t *testing.T) {
+		var reported diagnostics.List
+		opts := NewOptions()
+		opts.SourceFile = "metrics.go"
+		opts.SourceContent = io.NopCloser(strings.NewReader(src))
+		opts.Reporter = &reported
+		specs, err := NewParser(opts).Parse(context.Background())
+		require.NoError(t, err)
 
-		for _, exp := range expected {
-			actual, ok := resultSpec[exp.Name]
-			require.True(t, ok)
-			assert.Equal(t, exp, actual)
-		}
+		spec, ok := specs["chatgpt"].(*sloth.Spec)
+		require.True(t, ok)
+		require.Len(t, spec.SLOs, 1)
+		events := spec.SLOs[0].SLI.Events
+		require.NotNil(t, events)
+		assert.Equal(t, `sum(rate(chatgpt_auth0_tenant_failed_login_operations_total{client="chat-gpt"}[{{.window}}]))`, events.ErrorQuery)
+		assert.Equal(t, `sum(rate(chatgpt_auth0_tenant_login_operations_total[{{.window}}]))`, events.TotalQuery)
+
+		require.Len(t, reported, 1)
+		assert.Equal(t, diagnostics.CodeUnresolvedMetric, reported[0].Code)
+		assert.Equal(t, "metrics.go:33:4", reported[0].Pos.String())
 	})
 
-	t.Run("Successfully parse multiple Sloth services, should return 3 specifications", func(t *testing.T) {
-		parser := NewParser(nil)
-		comments := []*ast.CommentGroup{
-			{List: []*ast.Comment{
-				{
-					Text: `@sloth service foobar`,
-				},
-				{
-					Text: `@sloth.slo name availability`,
-				},
-				{
-					Text: `@sloth.slo description availability SLO for foobar service`,
-				},
-				{
-					Text: `@sloth.slo objective 95.0`,
-				},
-			}},
-			{List: []*ast.Comment{
-				{
-					Text: `@sloth service foo`,
-				},
-				{
-					Text: `@sloth.slo name availability`,
-				},
-				{
-					Text: `@sloth.slo description availability SLO for foo service`,
-				},
-				{
-					Text: `@sloth.slo objective 95.0`,
-				},
-			}},
-			{List: []*ast.Comment{
-				{
-					Text: `@sloth service bar`,
-				},
-				{
-					Text: `@sloth.slo name availability`,
-				},
-				{
-					Text: `@sloth.slo description availability SLO for bar service`,
-				},
-				{
-					Text: `@sloth.slo objective 95.0`,
-				},
-			}},
-		}
-		require.NoError(t, parser.parseK8SlothAnnotations(comments...))
-		require.Len(t, parser.specs, 3)
-		resultSpec := parser.specs
+	t.Run("Successfully return the metrics declared by the package variables", func(t *testing.T) {
+		metrics := sourceSymbols(map[string][]byte{"metrics.go": []byte(src)}).Metrics(token.Position{Filename: "metrics.go"})
+		assert.Empty(t, metrics.Metric)
+		assert.Equal(t, map[string]string{
+			"metricTenantLogins":       "chatgpt_auth0_tenant_login_operations_total",
+			"metricTenantFailedLogins": "chatgpt_auth0_tenant_failed_login_operations_total",
+			"requests":                 "http_request_duration_seconds",
+		}, metrics.Declared)
+	})
 
-		expected := []*k8sloth.PrometheusServiceLevel{
+	t.Run("Successfully return the catalog of the metrics created in the source", func(t *testing.T) {
+		catalog := sourceSymbols(map[string][]byte{"metrics.go": []byte(src)}).Catalog()
+		assert.Equal(t, []collector.Metric{
 			{
-				TypeMeta: v1.TypeMeta{
-					Kind:       "PrometheusServiceLevel",
-					APIVersion: "sloth.slok.dev/v1",
-				},
-				ObjectMeta: v1.ObjectMeta{
-					Name:   "bar",
-					Labels: make(map[string]string),
-				},
-				Spec: k8sloth.PrometheusServiceLevelSpec{
-					Service: "bar",
-					Labels:  make(map[string]string),
-					SLOs: []k8sloth.SLO{
-						{
-							Name:        "availability",
-							Description: "availability SLO for bar service",
-							Objective:   95.0,
-							Labels:      make(map[string]string),
-							SLI: k8sloth.SLI{
-								Raw:    nil,
-								Events: nil,
-								Plugin: nil,
-							},
-							Alerting: k8sloth.Alerting{
-								Name:        "",
-								Labels:      nil,
-								Annotations: nil,
-								PageAlert: k8sloth.Alert{
-									Disable:     false,
-									Labels:      nil,
-									Annotations: nil,
-								},
-								TicketAlert: k8sloth.Alert{
-									Disable:     false,
-									Labels:      nil,
-									Annotations: nil,
-								},
-							},
-						},
-					},
-				},
+				Name:   "chatgpt_auth0_tenant_login_operations_total",
+				Series: []string{"chatgpt_auth0_tenant_login_operations_total"},
 			},
 			{
-				TypeMeta: v1.TypeMeta{
-					Kind:       "PrometheusServiceLevel",
-					APIVersion: "sloth.slok.dev/v1",
-				},
-				ObjectMeta: v1.ObjectMeta{
-					Name:   "foobar",
-					Labels: make(map[string]string),
-				},
-				Spec: k8sloth.PrometheusServiceLevelSpec{
-					Service: "foobar",
-					Labels:  make(map[string]string),
-					SLOs: []k8sloth.SLO{
-						{
-							Name:        "availability",
-							Description: "availability SLO for foobar service",
-							Objective:   95.0,
-							Labels:      make(map[string]string),
-							SLI: k8sloth.SLI{
-								Raw:    nil,
-								Events: nil,
-								Plugin: nil,
-							},
-							Alerting: k8sloth.Alerting{
-								Name:        "",
-								Labels:      nil,
-								Annotations: nil,
-								PageAlert: k8sloth.Alert{
-									Disable:     false,
-									Labels:      nil,
-									Annotations: nil,
-								},
-								TicketAlert: k8sloth.Alert{
-									Disable:     false,
-									Labels:      nil,
-									Annotations: nil,
-								},
-							},
-						},
-					},
-				},
+				Name:   "chatgpt_auth0_tenant_failed_login_operations_total",
+				Series: []string{"chatgpt_auth0_tenant_failed_login_operations_total"},
+				Labels: []string{"client"},
 			},
 			{
-				TypeMeta: v1.TypeMeta{
-					Kind:       "PrometheusServiceLevel",
-					APIVersion: "sloth.slok.dev/v1",
-				},
-				ObjectMeta: v1.ObjectMeta{
-					Name:   "foo",
-					Labels: make(map[string]string),
-				},
-				Spec: k8sloth.PrometheusServiceLevelSpec{
-					Service: "foo",
-					Labels:  make(map[string]string),
-					SLOs: []k8sloth.SLO{
-						{
-							Name:        "availability",
-							Description: "availability SLO for foo service",
-							Objective:   95.0,
-							Labels:      make(map[string]string),
-							SLI: k8sloth.SLI{
-								Raw:    nil,
-								Events: nil,
-								Plugin: nil,
-							},
-							Alerting: k8sloth.Alerting{
-								Name:        "",
-								Labels:      nil,
-								Annotations: nil,
-								PageAlert: k8sloth.Alert{
-									Disable:     false,
-									Labels:      nil,
-									Annotations: nil,
-								},
-								TicketAlert: k8sloth.Alert{
-									Disable:     false,
-									Labels:      nil,
-									Annotations: nil,
-								},
-							},
-						},
-					},
-				},
+				Name: "http_request_duration_seconds",
+				Series: []string{
+					"http_request_duration_seconds",
+					"http_request_duration_seconds_bucket",
+					"http_request_duration_seconds_count",
+					"http_request_duration_seconds_sum",
+				},
+				Labels: []string{"le"},
 			},
-		}
+		}, catalog)
+	})
 
-		for _, exp := range expected {
-			actual, ok := resultSpec[exp.Name]
-			require.True(t, ok)
-			assert.Equal(t, exp, actual)
-		}
+	t.Run("Successfully warn about the SLI queries referencing metrics or labels not declared in the source", func(t *testing.T) {
+		var reported diagnostics.List
+		opts := NewOptions()
+		opts.SourceFile = "metrics.go"
+		opts.SourceContent = io.NopCloser(strings.NewReader(`package main
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// @sloth service chatgpt
+
+// @sloth.slo name requests-availability
+// @sloth.slo objective 95.0
+// @sloth.sli error_query sum(rate(http_requests_total{code=~"5..",method="GET"}[{{.window}}]))
+// @sloth.sli total_query sum(rate(http_request_total[{{.window}}]))
+var requests = prometheus.NewCounterVec(prometheus.CounterOpts{Name: "http_requests_total"}, []string{"code"})
+
+// @sloth.slo name requests-latency
+// @sloth.slo objective 99.0
+// @sloth.sli error_query sum(rate(http_request_duration_seconds_bucket{le="0.5",job="api"}[{{.window}}]))
+// @sloth.sli total_query sum(rate(http_request_duration_seconds_count[{{.window}}]))
+var latency = prometheus.NewHistogram(prometheus.HistogramOpts{Name: "http_request_duration_seconds"})
+`))
+		opts.Reporter = &reported
+		_, err := NewParser(opts).Parse(context.Background())
+		require.NoError(t, err)
+
+		require.Len(t, reported, 2)
+		assert.Equal(t, diagnostics.CodeUndeclaredMetric, reported[0].Code)
+		assert.Equal(t, diagnostics.SeverityWarning, reported[0].Severity)
+		assert.Equal(t, "metrics.go:9:4", reported[0].Pos.String())
+		assert.Contains(t, reported[0].Message, `references the label "method", which isn't declared by the metric "http_requests_total"`)
+		assert.Equal(t, diagnostics.CodeUndeclaredMetric, reported[1].Code)
+		assert.Equal(t, "metrics.go:10:4", reported[1].Pos.String())
+		assert.Contains(t, reported[1].Message, `references the metric "http_request_total", which isn't declared in the source code`)
+	})
+
+	t.Run("Successfully check only the queries of the go files against the metrics declared in the go source", func(t *testing.T) {
+		// the python queries reference the python metrics, which aren't in the catalog of the go metrics
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "metrics.go"), []byte(`package main
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// @sloth service chatgpt
+// @sloth.slo name chat-gpt-availability
+// @sloth.slo objective 95.0
+// @sloth.sli error_query sum(rate(tenant_failed_login_operations_total{client="chat-gpt"}[{{.window}}]))
+// @sloth.sli total_query sum(rate(tenant_login_operations_total{client="chat-gpt"}[{{.window}}]))
+var logins = prometheus.NewCounterVec(prometheus.CounterOpts{Name: "tenant_login_operations_total"}, []string{"client"})
+
+var failedLogins = prometheus.NewCounterVec(prometheus.CounterOpts{Name: "tenant_failed_login_operations_total"}, []string{"client"})
+`), 0o644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "latency.py"), []byte(`from prometheus_client import Histogram
+
+# @sloth service chatgpt
+# @sloth.slo name chat-gpt-latency
+# @sloth.slo objective 99.0
+# @sloth.sli error_query sum(rate(tenant_slow_requests_total{client="chat-gpt"}[{{.window}}]))
+# @sloth.sli total_query sum(rate(tenant_requests_total{client="chat-gpt"}[{{.window}}]))
+LATENCY = Histogram("tenant_request_duration_seconds", "Request latency", ["client"])
+`), 0o644))
+
+		var reported diagnostics.List
+		opts := comments.NewOptions()
+		opts.InputDirectories = []string{dir}
+		opts.Reporter = &reported
+		opts.Strict = true
+		specs, err := comments.NewMultiLanguageParser([]comments.Language{Language, python.Language}, opts).Parse(context.Background())
+		require.NoError(t, err)
+
+		spec, ok := specs["chatgpt"].(*sloth.Spec)
+		require.True(t, ok)
+		require.Len(t, spec.SLOs, 2)
+		assert.Empty(t, reported)
+	})
+
+	t.Run("Fail to parse the go queries referencing metrics not declared in the go source in strict mode", func(t *testing.T) {
+		var reported diagnostics.List
+		opts := comments.NewOptions()
+		opts.SourceFile = "metrics.go"
+		opts.SourceContent = io.NopCloser(strings.NewReader(`package main
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// @sloth service chatgpt
+// @sloth.slo name requests-availability
+// @sloth.sli error_ratio_query sum(rate(http_request_errors_total[{{.window}}]))
+var requests = prometheus.NewCounter(prometheus.CounterOpts{Name: "http_requests_total"})
+`))
+		opts.Reporter = &reported
+		opts.Strict = true
+		_, err := comments.NewMultiLanguageParser([]comments.Language{Language, python.Language}, opts).Parse(context.Background())
+		require.Error(t, err)
+		require.Len(t, reported, 1)
+		assert.Equal(t, diagnostics.CodeUndeclaredMetric, reported[0].Code)
 	})
 }
@@ -0,0 +1,388 @@
+package golang
+
+import (
+	"go/ast"
+	"go/constant"
+	goparser "go/parser"
+	"go/token"
+	"go/types"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/slosive/sloscribe/internal/parser/specification/sloth/collector"
+	"github.com/slosive/sloscribe/internal/parser/specification/sloth/grammar"
+)
+
+const (
+	prometheusPath = "github.com/prometheus/client_golang/prometheus"
+	promautoPath   = "github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// metricKind is the kind of metric created by a constructor, it tells the series and labels the metric exposes
+type metricKind int
+
+const (
+	counterOrGauge metricKind = iota
+	histogram
+	summary
+)
+
+// constructors are the prometheus and promauto metric constructors, the *Vec constructors take the label names as second argument
+var constructors = map[string]metricKind{
+	"NewCounter":      counterOrGauge,
+	"NewCounterVec":   counterOrGauge,
+	"NewCounterFunc":  counterOrGauge,
+	"NewGauge":        counterOrGauge,
+	"NewGaugeVec":     counterOrGauge,
+	"NewGaugeFunc":    counterOrGauge,
+	"NewUntypedFunc":  counterOrGauge,
+	"NewHistogram":    histogram,
+	"NewHistogramVec": histogram,
+	"NewSummary":      summary,
+	"NewSummaryVec":   summary,
+}
+
+type (
+	// symbols are the constants and the Prometheus metrics of the go packages, these are type checked by go/types without their imports
+	symbols struct {
+		// packages are the type checked packages, indexed by the filename of their files
+		packages map[string]*goPackage
+		// documented are the names of the metrics declared by the nodes the comment groups document, indexed by comment group location.
+		// The comment groups are located by their first line, the comments scanned are positioned at their text, not at their marker.
+		documented map[location]string
+		// catalog are all the metrics created in the files
+		catalog []collector.Metric
+	}
+	goPackage struct {
+		types *types.Package
+		// metrics are the names of the metrics declared by the package level variables, indexed by variable name
+		metrics map[string]string
+	}
+	// location is the position of a comment group in its source file
+	location struct {
+		filename string
+		line     int
+	}
+	// noImporter doesn't import any package, the constants depending on imported packages can't be resolved
+	noImporter struct{}
+)
+
+func (noImporter) Import(path string) (*types.Package, error) {
+	return nil, errors.Errorf("package %q isn't imported", path)
+}
+
+// sourceSymbols returns the symbols of the go source files of a directory, the sources are indexed by filename
+func sourceSymbols(sources map[string][]byte) collector.Symbols {
+	fset := token.NewFileSet()
+	files := map[string]*ast.File{}
+	for filename, src := range sources {
+		file, err := goparser.ParseFile(fset, filename, src, goparser.ParseComments|goparser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+		files[filename] = file
+	}
+	return newSymbols(fset, files)
+}
+
+// newSymbols type checks the go files, grouped by package, the files are positioned in fset.
+// Each comment group is associated with the node it documents, to resolve the metric the node declares.
+func newSymbols(fset *token.FileSet, files map[string]*ast.File) *symbols {
+	s := &symbols{packages: map[string]*goPackage{}, documented: map[location]string{}}
+
+	filenames := make([]string, 0, len(files))
+	for filename := range files {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+	// the files of the same directory can declare different packages, i.e: foo and foo_test
+	packages := map[string][]*ast.File{}
+	for _, filename := range filenames {
+		name := files[filename].Name.Name
+		packages[name] = append(packages[name], files[filename])
+	}
+
+	for name, pkgFiles := range packages {
+		info := &types.Info{
+			Types: map[ast.Expr]types.TypeAndValue{},
+			Uses:  map[*ast.Ident]types.Object{},
+		}
+		conf := types.Config{
+			Importer: noImporter{},
+			// the type errors are expected as the imports aren't resolved
+			Error: func(error) {},
+		}
+		typesPkg, _ := conf.Check(name, fset, pkgFiles, info)
+		pkg := &goPackage{types: typesPkg, metrics: map[string]string{}}
+
+		for _, file := range pkgFiles {
+			s.packages[fset.Position(file.Package).Filename] = pkg
+			for _, decl := range file.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.VAR {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					value := spec.(*ast.ValueSpec)
+					for i, ident := range value.Names {
+						if i >= len(value.Values) {
+							break
+						}
+						if metric := findMetric(value.Values[i], info); metric != nil {
+							pkg.metrics[ident.Name] = metric.Name
+						}
+					}
+				}
+			}
+
+			for node, groups := range ast.NewCommentMap(fset, file, file.Comments) {
+				if _, ok := node.(*ast.File); ok {
+					continue
+				}
+				metric := findMetric(node, info)
+				if metric == nil {
+					continue
+				}
+				for _, group := range groups {
+					pos := fset.Position(group.Pos())
+					s.documented[location{filename: pos.Filename, line: pos.Line}] = metric.Name
+				}
+			}
+
+			ast.Inspect(file, func(n ast.Node) bool {
+				if call, ok := n.(*ast.CallExpr); ok {
+					if metric := newMetric(call, info); metric != nil {
+						s.catalog = append(s.catalog, *metric)
+					}
+				}
+				return true
+			})
+		}
+	}
+	return s
+}
+
+// Constant returns the value of the package level constant declared in the package of the comment group
+func (s *symbols) Constant(pos token.Position, ident string) (string, error) {
+	pkg, ok := s.packages[pos.Filename]
+	if !ok {
+		return "", errors.Errorf("Go constant %q can't be resolved, %s isn't a parsed Go source file", ident, pos.Filename)
+	}
+	obj := pkg.types.Scope().Lookup(ident)
+	if obj == nil {
+		return "", errors.Errorf("Go constant %q isn't declared in package %s", ident, pkg.types.Name())
+	}
+	c, ok := obj.(*types.Const)
+	if !ok {
+		return "", errors.Errorf("Go identifier %q of package %s isn't a constant", ident, pkg.types.Name())
+	}
+	return constantValue(c)
+}
+
+// Metrics returns the metric declared by the node the comment group documents and the metrics declared by the package level variables
+func (s *symbols) Metrics(pos token.Position) grammar.Metrics {
+	var metrics grammar.Metrics
+	if pkg, ok := s.packages[pos.Filename]; ok {
+		metrics.Declared = pkg.metrics
+	}
+	metrics.Metric = s.documented[location{filename: pos.Filename, line: pos.Line}]
+	return metrics
+}
+
+// Catalog returns all the metrics created in the files
+func (s *symbols) Catalog() []collector.Metric {
+	return s.catalog
+}
+
+// constantValue returns the annotation value of the constant, the strings are unquoted
+func constantValue(c *types.Const) (string, error) {
+	value := c.Val()
+	switch value.Kind() {
+	case constant.String:
+		return constant.StringVal(value), nil
+	case constant.Float:
+		f, _ := constant.Float64Val(value)
+		return strconv.FormatFloat(f, 'f', -1, 64), nil
+	case constant.Int, constant.Bool:
+		return value.ExactString(), nil
+	default:
+		return "", errors.Errorf("Go constant %q value can't be resolved, it may depend on an imported package", c.Name())
+	}
+}
+
+// findMetric returns the first metric created in the node, nil if the node doesn't create a metric
+func findMetric(node ast.Node, info *types.Info) *collector.Metric {
+	var metric *collector.Metric
+	ast.Inspect(node, func(n ast.Node) bool {
+		if metric != nil {
+			return false
+		}
+		if call, ok := n.(*ast.CallExpr); ok {
+			metric = newMetric(call, info)
+		}
+		return metric == nil
+	})
+	return metric
+}
+
+// newMetric returns the metric created by a prometheus or promauto constructor, i.e: prometheus.NewCounter(prometheus.CounterOpts{...}).
+// Like prometheus.BuildFQName, the metric name is the opts Namespace, Subsystem and Name joined by underscores, the opts fields must be constants.
+// It returns nil if the call isn't a metric constructor or the metric name can't be resolved.
+func newMetric(call *ast.CallExpr, info *types.Info) *collector.Metric {
+	kind, ok := metricConstructor(call, info)
+	if !ok || len(call.Args) == 0 {
+		return nil
+	}
+	opts, ok := call.Args[0].(*ast.CompositeLit)
+	if !ok {
+		return nil
+	}
+
+	metric := &collector.Metric{}
+	fields := map[string]string{}
+	for _, elt := range opts.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		key, ok := kv.Key.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		if key.Name == "ConstLabels" {
+			labels, ok := constLabels(kv.Value, info)
+			metric.Labels = append(metric.Labels, labels...)
+			metric.UnknownLabels = metric.UnknownLabels || !ok
+			continue
+		}
+		if value, ok := stringValue(kv.Value, info); ok {
+			fields[key.Name] = value
+		}
+	}
+	metric.Name = buildFQName(fields["Namespace"], fields["Subsystem"], fields["Name"])
+	if metric.Name == "" {
+		return nil
+	}
+
+	// the label names of the *Vec metrics
+	if sel := call.Fun.(*ast.SelectorExpr); strings.HasSuffix(sel.Sel.Name, "Vec") {
+		labels, ok := labelNames(call.Args[1:], info)
+		metric.Labels = append(metric.Labels, labels...)
+		metric.UnknownLabels = metric.UnknownLabels || !ok
+	}
+
+	metric.Series = []string{metric.Name}
+	switch kind {
+	case histogram:
+		metric.Series = append(metric.Series, metric.Name+"_bucket", metric.Name+"_count", metric.Name+"_sum")
+		metric.Labels = append(metric.Labels, "le")
+	case summary:
+		metric.Series = append(metric.Series, metric.Name+"_count", metric.Name+"_sum")
+		metric.Labels = append(metric.Labels, "quantile")
+	}
+	return metric
+}
+
+// labelNames returns the label names of a *Vec constructor, i.e: []string{"code", "method"}.
+// It returns false if the label names can't be resolved, i.e: they are a variable
+func labelNames(args []ast.Expr, info *types.Info) ([]string, bool) {
+	if len(args) == 0 {
+		return nil, false
+	}
+	names, ok := args[0].(*ast.CompositeLit)
+	if !ok {
+		return nil, false
+	}
+	var labels []string
+	for _, elt := range names.Elts {
+		label, ok := stringValue(elt, info)
+		if !ok {
+			return labels, false
+		}
+		labels = append(labels, label)
+	}
+	return labels, true
+}
+
+// constLabels returns the label names of the metric opts ConstLabels, i.e: prometheus.Labels{"region": "eu"}.
+// It returns false if the label names can't be resolved
+func constLabels(expr ast.Expr, info *types.Info) ([]string, bool) {
+	labels, ok := expr.(*ast.CompositeLit)
+	if !ok {
+		return nil, false
+	}
+	var names []string
+	for _, elt := range labels.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			return names, false
+		}
+		name, ok := stringValue(kv.Key, info)
+		if !ok {
+			return names, false
+		}
+		names = append(names, name)
+	}
+	return names, true
+}
+
+// stringValue returns the value of a string constant expression
+func stringValue(expr ast.Expr, info *types.Info) (string, bool) {
+	value := info.Types[expr].Value
+	if value == nil || value.Kind() != constant.String {
+		return "", false
+	}
+	return constant.StringVal(value), true
+}
+
+// metricConstructor returns the kind of metric created by the call if it is a metric constructor,
+// i.e: prometheus.NewCounterVec, promauto.NewHistogram or promauto.With(registry).NewGauge
+func metricConstructor(call *ast.CallExpr, info *types.Info) (metricKind, bool) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return 0, false
+	}
+	kind, ok := constructors[sel.Sel.Name]
+	if !ok {
+		return 0, false
+	}
+	switch x := sel.X.(type) {
+	case *ast.Ident:
+		path := importPath(x, info)
+		return kind, path == prometheusPath || path == promautoPath
+	case *ast.CallExpr:
+		// promauto.With(registry) returns a Factory creating the metrics
+		with, ok := x.Fun.(*ast.SelectorExpr)
+		if !ok || with.Sel.Name != "With" {
+			return 0, false
+		}
+		pkg, ok := with.X.(*ast.Ident)
+		return kind, ok && importPath(pkg, info) == promautoPath
+	}
+	return 0, false
+}
+
+// importPath returns the path of the package the identifier refers to, empty if it isn't an imported package name
+func importPath(ident *ast.Ident, info *types.Info) string {
+	pkgName, ok := info.Uses[ident].(*types.PkgName)
+	if !ok {
+		return ""
+	}
+	return pkgName.Imported().Path()
+}
+
+// buildFQName joins the non-empty namespace, subsystem and name by underscores, it is empty if the name is empty
+func buildFQName(namespace, subsystem, name string) string {
+	if name == "" {
+		return ""
+	}
+	var parts []string
+	for _, part := range []string{namespace, subsystem, name} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, "_")
+}
@@ -0,0 +1,37 @@
+package java
+
+import (
+	"github.com/slosive/sloscribe/internal/parser/specification/sloth/language"
+	"github.com/slosive/sloscribe/internal/parser/specification/sloth/language/comments"
+)
+
+// Options contains the configuration options available to the Parser
+type Options = comments.Options
+
+// Language is the java comments syntax, it covers line comments and Javadoc block comments
+var Language = comments.Language{
+	Name: "java",
+	Syntax: comments.Syntax{
+		LineComments:     []string{"//"},
+		BlockComments:    []comments.Delimiters{{Start: "/*", End: "*/"}},
+		LeadingAsterisks: true,
+		DocMarkers:       "*",
+		Strings: []comments.Delimiters{
+			{Start: `"""`, End: `"""`},
+			{Start: `"`, End: `"`},
+			{Start: `'`, End: `'`},
+		},
+	},
+	Extensions:   []string{".java"},
+	Interpreters: []string{"java"},
+	ExcludedDirs: []string{"build", "target", ".gradle", ".git"},
+}
+
+func NewOptions() *Options {
+	return comments.NewOptions()
+}
+
+// NewParser client parser performs all checks at initialization time
+func NewParser(opts *Options) language.Language {
+	return comments.NewParser(Language, opts)
+}
@@ -0,0 +1,130 @@
+package java
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	sloth "github.com/slok/sloth/pkg/prometheus/api/v1"
+	"github.com/slosive/sloscribe/internal/diagnostics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parse(t *testing.T, src string) map[string]any {
+	t.Helper()
+	opts := NewOptions()
+	opts.SourceContent = io.NopCloser(strings.NewReader(src))
+	specs, err := NewParser(opts).Parse(context.Background())
+	require.NoError(t, err)
+	return specs
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Successfully parse the Javadoc and line comments of the java files in the target directory", func(t *testing.T) {
+		opts := NewOptions()
+		opts.InputDirectories = []string{"./testdata"}
+		specs, err := NewParser(opts).Parse(context.Background())
+		require.NoError(t, err)
+
+		spec, ok := specs["chatgpt"].(*sloth.Spec)
+		require.True(t, ok)
+		assert.Equal(t, map[string]string{"team": "platform"}, spec.Labels)
+		require.Len(t, spec.SLOs, 2)
+
+		// the Javadoc comment
+		assert.Equal(t, "chat-gpt-availability", spec.SLOs[0].Name)
+		assert.Equal(t, "95% of logins to the chat-gpt app should be successful.", spec.SLOs[0].Description)
+		assert.Equal(t, "ChatGPTAvailability", spec.SLOs[0].Alerting.Name)
+		require.NotNil(t, spec.SLOs[0].SLI.Events)
+		assert.Equal(t, `sum(rate(tenant_login_operations_total{client="chat-gpt"}[{{.window}}]))`, spec.SLOs[0].SLI.Events.TotalQuery)
+
+		// the // comment group
+		assert.Equal(t, "chat-gpt-latency", spec.SLOs[1].Name)
+		require.NotNil(t, spec.SLOs[1].SLI.Raw)
+		assert.Equal(t, `sum(rate(tenant_slow_requests_total[{{.window}}]))`, spec.SLOs[1].SLI.Raw.ErrorRatioQuery)
+	})
+
+	t.Run("Successfully skip the build and target output directories", func(t *testing.T) {
+		opts := NewOptions()
+		opts.InputDirectories = []string{"./testdata"}
+		specs, err := NewParser(opts).Parse(context.Background())
+		require.NoError(t, err)
+		assert.Len(t, specs, 1)
+		assert.NotContains(t, specs, "generated")
+		assert.NotContains(t, specs, "packaged")
+	})
+
+	t.Run("Successfully strip the leading asterisks of the Javadoc lines", func(t *testing.T) {
+		specs := parse(t, `/** @sloth service foobar
+ *
+ * @sloth.slo name availability
+ *@sloth.slo objective 99.5
+ * @sloth.slo description requests * served successfully
+ */
+class Metrics {}
+`)
+		spec, ok := specs["foobar"].(*sloth.Spec)
+		require.True(t, ok)
+		require.Len(t, spec.SLOs, 1)
+		assert.Equal(t, "availability", spec.SLOs[0].Name)
+		assert.Equal(t, 99.5, spec.SLOs[0].Objective)
+		assert.Equal(t, "requests * served successfully", spec.SLOs[0].Description)
+	})
+
+	t.Run("Successfully report the diagnostics at their column in the Javadoc lines", func(t *testing.T) {
+		var reported diagnostics.List
+		opts := NewOptions()
+		opts.SourceFile = "Metrics.java"
+		opts.SourceContent = io.NopCloser(strings.NewReader(`/**
+ * @sloth service foobar
+ *   @sloth.slo objective high
+ */
+class Metrics {}
+`))
+		opts.Reporter = &reported
+		_, err := NewParser(opts).Parse(context.Background())
+		require.NoError(t, err)
+		require.Len(t, reported, 1)
+		assert.Equal(t, "Metrics.java:3:6", reported[0].Pos.String())
+	})
+
+	t.Run("Successfully ignore the comment markers inside the string, text block and character literals", func(t *testing.T) {
+		specs := parse(t, `class Metrics {
+    String url = "http://localhost:9090 // @sloth service not-a-service";
+    String query = """
+        /* @sloth service not-a-service-either */
+        """;
+    char quote = '"';
+    char slash = '/';
+    // @sloth service foobar
+    // @sloth.slo name availability
+    Counter requests;
+}
+`)
+		assert.Len(t, specs, 1)
+		spec, ok := specs["foobar"].(*sloth.Spec)
+		require.True(t, ok)
+		require.Len(t, spec.SLOs, 1)
+		assert.Equal(t, "availability", spec.SLOs[0].Name)
+	})
+
+	t.Run("Successfully end a block comment at the first terminator, java block comments don't nest", func(t *testing.T) {
+		specs := parse(t, `/* outer /* not nested */
+
+// @sloth service foobar
+class Metrics {}
+`)
+		assert.Contains(t, specs, "foobar")
+	})
+
+	t.Run("Fail to parse a non existing java file", func(t *testing.T) {
+		opts := NewOptions()
+		opts.SourceFile = "./testdata/src/Fake.java"
+		_, err := NewParser(opts).Parse(context.Background())
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1 @@
+// @sloth service packaged
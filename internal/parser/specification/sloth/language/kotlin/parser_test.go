@@ -0,0 +1,106 @@
+package kotlin
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	sloth "github.com/slok/sloth/pkg/prometheus/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parse(t *testing.T, src string) map[string]any {
+	t.Helper()
+	opts := NewOptions()
+	opts.SourceContent = io.NopCloser(strings.NewReader(src))
+	specs, err := NewParser(opts).Parse(context.Background())
+	require.NoError(t, err)
+	return specs
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Successfully parse the KDoc and line comments of the kotlin files in the target directory", func(t *testing.T) {
+		opts := NewOptions()
+		opts.InputDirectories = []string{"./testdata"}
+		specs, err := NewParser(opts).Parse(context.Background())
+		require.NoError(t, err)
+
+		spec, ok := specs["chatgpt"].(*sloth.Spec)
+		require.True(t, ok)
+		assert.Equal(t, map[string]string{"team": "platform"}, spec.Labels)
+		require.Len(t, spec.SLOs, 2)
+
+		// the KDoc comment
+		assert.Equal(t, "chat-gpt-availability", spec.SLOs[0].Name)
+		assert.Equal(t, "95% of logins to the chat-gpt app should be successful.", spec.SLOs[0].Description)
+		require.NotNil(t, spec.SLOs[0].SLI.Events)
+		assert.Equal(t, `sum(rate(tenant_login_operations_total{client="chat-gpt"}[{{.window}}]))`, spec.SLOs[0].SLI.Events.TotalQuery)
+
+		// the // comment group, after the nested block comment
+		assert.Equal(t, "chat-gpt-latency", spec.SLOs[1].Name)
+		require.NotNil(t, spec.SLOs[1].SLI.Raw)
+		assert.Equal(t, `sum(rate(tenant_slow_requests_total[{{.window}}]))`, spec.SLOs[1].SLI.Raw.ErrorRatioQuery)
+	})
+
+	t.Run("Successfully skip the build output directory", func(t *testing.T) {
+		opts := NewOptions()
+		opts.InputDirectories = []string{"./testdata"}
+		specs, err := NewParser(opts).Parse(context.Background())
+		require.NoError(t, err)
+		assert.Len(t, specs, 1)
+		assert.NotContains(t, specs, "generated")
+	})
+
+	t.Run("Successfully skip the annotations inside the nested block comments", func(t *testing.T) {
+		specs := parse(t, `/*
+ * disabled /* nested */
+
+// @sloth service not-a-service
+ */
+
+/**
+ * @sloth service foobar
+ * @sloth.slo name availability
+ * @sloth.slo objective 99.5
+ */
+val requests = Counter.builder("requests").register(registry)
+`)
+		assert.Len(t, specs, 1)
+		spec, ok := specs["foobar"].(*sloth.Spec)
+		require.True(t, ok)
+		require.Len(t, spec.SLOs, 1)
+		assert.Equal(t, "availability", spec.SLOs[0].Name)
+		assert.Equal(t, 99.5, spec.SLOs[0].Objective)
+	})
+
+	t.Run("Successfully ignore the comment markers inside the raw strings and character literals", func(t *testing.T) {
+		specs := parse(t, `val glob = """src/*.kt"""
+val quote = '"'
+val url = "http://localhost:9090 // @sloth service not-a-service"
+// @sloth service foobar
+// @sloth.slo name availability
+val requests = Counter.builder("requests").register(registry)
+`)
+		assert.Len(t, specs, 1)
+		spec, ok := specs["foobar"].(*sloth.Spec)
+		require.True(t, ok)
+		require.Len(t, spec.SLOs, 1)
+	})
+
+	t.Run("Successfully detect the kotlin scripts", func(t *testing.T) {
+		assert.True(t, Language.IsSourceFile("build.gradle.kts"))
+		assert.True(t, Language.IsScript("#!/usr/bin/env kscript"))
+		assert.False(t, Language.IsSourceFile("Metrics.java"))
+	})
+
+	t.Run("Fail to parse a non existing kotlin file", func(t *testing.T) {
+		opts := NewOptions()
+		opts.SourceFile = "./testdata/src/Fake.kt"
+		_, err := NewParser(opts).Parse(context.Background())
+		require.Error(t, err)
+	})
+}
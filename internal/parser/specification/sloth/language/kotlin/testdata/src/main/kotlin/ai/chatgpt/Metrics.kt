@@ -0,0 +1,31 @@
+package ai.chatgpt
+
+import io.micrometer.core.instrument.Counter
+import io.micrometer.core.instrument.MeterRegistry
+
+/**
+ * @sloth service chatgpt
+ * @sloth labels team platform
+ */
+class Metrics(registry: MeterRegistry) {
+    private val url = """http://localhost:9090 // @sloth service not-a-service"""
+
+    /**
+     * @sloth.slo name chat-gpt-availability
+     * @sloth.slo objective 95.0
+     * @sloth.sli error_query sum(rate(tenant_failed_login_operations_total{client="chat-gpt"}[{{.window}}])) OR on() vector(0)
+     * @sloth.sli total_query sum(rate(tenant_login_operations_total{client="chat-gpt"}[{{.window}}]))
+     * @sloth.slo description 95% of logins to the chat-gpt app should be successful.
+     * @sloth.alerting name ChatGPTAvailability
+     */
+    private val tenantLogins: Counter = Counter.builder("tenant_login_operations").register(registry)
+
+    /* block comments can be /* nested */
+    // @sloth service not-a-service
+    */
+
+    // @sloth.slo name chat-gpt-latency
+    // @sloth.slo objective 99.0
+    // @sloth.sli error_ratio_query sum(rate(tenant_slow_requests_total[{{.window}}]))
+    private val slowRequests: Counter = Counter.builder("tenant_slow_requests").register(registry)
+}
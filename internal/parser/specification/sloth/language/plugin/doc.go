@@ -0,0 +1,41 @@
+// Package plugin runs the out-of-process language plugins, used to extract the comment groups from the source files
+// written in languages that aren't natively supported.
+//
+// A language plugin is an executable named sloscribe-lang-<name> found in PATH, i.e: sloscribe-lang-ini for --lang ini.
+// The plugin implements the following commands:
+//
+//	sloscribe-lang-<name> --extensions
+//
+// prints the JSON array of the file extensions of the source files written in the language, i.e: [".ini", ".cfg"].
+//
+//	sloscribe-lang-<name> FILE...
+//	sloscribe-lang-<name> -
+//
+// prints the JSON array of the comment groups found in the files, or in the content read from stdin when the file is "-".
+// Lines and columns are 1-based, the text doesn't include the comment markers and the column is the one where the text starts:
+//
+//	[{"file": "app.ini", "groups": [{"comments": [{"line": 1, "column": 2, "text": " @sloth service app"}]}]}]
+//
+// The plugin exits with a non-zero exit code, and writes the error to stderr, if the files can't be parsed.
+//
+// A language module is a WebAssembly module, loaded with --lang-plugin path.wasm, run in a sandbox by the wazero runtime.
+// The module can only import the WASI preview1 functions, its _initialize function is called, if exported, before any other.
+// The module exports the following functions:
+//
+//	alloc(size i32) i32
+//
+// allocates the memory the file content is written to by the host.
+//
+//	extensions() i64
+//
+// returns the JSON array of the file extensions of the source files written in the language.
+//
+//	extract(ptr i32, size i32) i64
+//
+// returns the JSON object with the comment groups found in the file content, or the error if the content can't be parsed:
+//
+//	{"groups": [{"comments": [{"line": 1, "column": 2, "text": " @sloth service app"}]}], "error": ""}
+//
+// The i64 results are the pointer to the JSON output, in the high 32 bits, and its length, in the low 32 bits.
+// A new instance of the module is used for each file.
+package plugin
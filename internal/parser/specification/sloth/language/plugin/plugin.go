@@ -0,0 +1,134 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/ast"
+	"go/token"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/slosive/sloscribe/internal/parser/lang"
+	"github.com/slosive/sloscribe/internal/parser/specification/sloth/language/comments"
+)
+
+type (
+	// File is the plugin output for a parsed file
+	File struct {
+		// File is the path of the parsed file, or "-" for the content read from stdin
+		File   string         `json:"file"`
+		Groups []CommentGroup `json:"groups"`
+	}
+
+	// CommentGroup is a group of adjacent comments, the same way go/parser groups comments in a go file
+	CommentGroup struct {
+		Comments []Comment `json:"comments"`
+	}
+
+	// Comment is a comment line without the comment markers, positioned in the source file
+	Comment struct {
+		// Line is the 1-based line of the comment
+		Line int `json:"line"`
+		// Column is the 1-based column, in bytes, of the comment text, right after the comment marker
+		Column int    `json:"column"`
+		Text   string `json:"text"`
+	}
+)
+
+// stdin is the file argument telling the plugin to parse the content read from stdin
+const stdin = "-"
+
+// Language returns the language parsed by the sloscribe-lang-<name> plugin found in PATH
+func Language(name string) (comments.Language, error) {
+	executable, err := exec.LookPath(lang.Target(name).Plugin())
+	if err != nil {
+		return comments.Language{}, errors.Annotatef(err, "language plugin for %q was not found", name)
+	}
+
+	out, err := run(executable, nil, "--extensions")
+	if err != nil {
+		return comments.Language{}, err
+	}
+	var extensions []string
+	if err := json.Unmarshal(out, &extensions); err != nil {
+		return comments.Language{}, errors.Annotatef(err, "language plugin %s returned invalid extensions", executable)
+	}
+
+	return comments.Language{
+		Name:       name,
+		Extensions: extensions,
+		Extract: func(fset *token.FileSet, filename string, src []byte) ([]*ast.CommentGroup, error) {
+			return extract(executable, fset, filename, src)
+		},
+	}, nil
+}
+
+// extract returns the comment groups found by the plugin in the source file.
+// The file path is passed to the plugin if the file exists, otherwise the source is written to the plugin stdin.
+func extract(executable string, fset *token.FileSet, filename string, src []byte) ([]*ast.CommentGroup, error) {
+	var out []byte
+	var err error
+	if info, statErr := os.Stat(filename); filename != "" && statErr == nil && info.Mode().IsRegular() {
+		out, err = run(executable, nil, filename)
+	} else {
+		out, err = run(executable, src, stdin)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var files []File
+	if err := json.Unmarshal(out, &files); err != nil {
+		return nil, errors.Annotatef(err, "language plugin %s returned invalid comment groups", executable)
+	}
+
+	file := fset.AddFile(filename, -1, len(src))
+	file.SetLinesForContent(src)
+	var groups []*ast.CommentGroup
+	for _, f := range files {
+		for _, g := range f.Groups {
+			group, err := g.commentGroup(file)
+			if err != nil {
+				return nil, errors.Annotatef(err, "language plugin %s returned an invalid comment group", executable)
+			}
+			if group != nil {
+				groups = append(groups, group)
+			}
+		}
+	}
+	return groups, nil
+}
+
+// commentGroup returns the ast.CommentGroup of the plugin comment group, the comments are positioned in the file
+func (g CommentGroup) commentGroup(file *token.File) (*ast.CommentGroup, error) {
+	if len(g.Comments) == 0 || file.Size() == 0 {
+		return nil, nil
+	}
+	list := make([]*ast.Comment, 0, len(g.Comments))
+	for _, c := range g.Comments {
+		if c.Line < 1 || c.Line > file.LineCount() || c.Column < 1 {
+			return nil, errors.Errorf("comment position %d:%d is outside of the file", c.Line, c.Column)
+		}
+		offset := file.Offset(file.LineStart(c.Line)) + c.Column - 1
+		list = append(list, comments.NewComment(file, offset, c.Text))
+	}
+	return &ast.CommentGroup{List: list}, nil
+}
+
+// run runs the plugin executable, writing the input to its stdin, and returns its output
+func run(executable string, input []byte, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(executable, args...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, errors.Annotatef(err, "language plugin %s failed: %s", executable, msg)
+		}
+		return nil, errors.Annotatef(err, "language plugin %s failed", executable)
+	}
+	return stdout.Bytes(), nil
+}
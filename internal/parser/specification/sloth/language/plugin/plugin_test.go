@@ -0,0 +1,125 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"go/token"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	sloth "github.com/slok/sloth/pkg/prometheus/api/v1"
+	"github.com/slosive/sloscribe/internal/parser/specification/sloth/language/comments"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMain builds the reference ini plugin and adds it to PATH
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "sloscribe-plugins")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	build := exec.Command("go", "build", "-o", filepath.Join(dir, "sloscribe-lang-ini"), "../../../../../../plugins/sloscribe-lang-ini")
+	build.Stderr = os.Stderr
+	if err := build.Run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func TestLanguage(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Successfully return the language of the plugin in PATH", func(t *testing.T) {
+		language, err := Language("ini")
+		require.NoError(t, err)
+		assert.Equal(t, "ini", language.Name)
+		assert.Equal(t, []string{".ini", ".cfg"}, language.Extensions)
+		assert.NotNil(t, language.Extract)
+	})
+
+	t.Run("Successfully extract the positioned comment groups from the file", func(t *testing.T) {
+		language, err := Language("ini")
+		require.NoError(t, err)
+
+		filename := "./testdata/config/nested/latency.cfg"
+		src, err := os.ReadFile(filename)
+		require.NoError(t, err)
+
+		fset := token.NewFileSet()
+		groups, err := language.Comments(fset, filename, src)
+		require.NoError(t, err)
+		require.Len(t, groups, 1)
+		assert.True(t, strings.HasPrefix(groups[0].Text(), "@sloth.slo name latency\n"))
+
+		position := fset.Position(groups[0].List[0].Pos())
+		assert.Equal(t, 2, position.Line)
+		assert.Equal(t, 6, position.Column)
+	})
+
+	t.Run("Successfully extract the comment groups from the content", func(t *testing.T) {
+		language, err := Language("ini")
+		require.NoError(t, err)
+
+		fset := token.NewFileSet()
+		groups, err := language.Comments(fset, "", []byte("; @sloth service foo\n[foo]\n# @sloth service bar\n"))
+		require.NoError(t, err)
+		require.Len(t, groups, 2)
+		assert.Equal(t, "@sloth service foo\n", groups[0].Text())
+		assert.Equal(t, "@sloth service bar\n", groups[1].Text())
+		assert.Equal(t, 3, fset.Position(groups[1].Pos()).Line)
+	})
+
+	t.Run("Fail to return the language of a plugin not in PATH", func(t *testing.T) {
+		_, err := Language("cobol")
+		require.Error(t, err)
+	})
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Successfully parse the sloth annotations in the files in the target directory", func(t *testing.T) {
+		language, err := Language("ini")
+		require.NoError(t, err)
+
+		opts := comments.NewOptions()
+		opts.InputDirectories = []string{"./testdata"}
+		specs, err := comments.NewParser(language, opts).Parse(context.Background())
+		require.NoError(t, err)
+		require.Len(t, specs, 1)
+
+		spec, ok := specs["chatgpt"].(*sloth.Spec)
+		require.True(t, ok)
+		require.Len(t, spec.SLOs, 2)
+		assert.Equal(t, "availability", spec.SLOs[0].Name)
+		assert.Equal(t, 95.0, spec.SLOs[0].Objective)
+		require.NotNil(t, spec.SLOs[0].SLI.Events)
+		assert.Equal(t, `sum(rate(tenant_failed_login_operations_total{client="chat-gpt"}[{{.window}}]))`, spec.SLOs[0].SLI.Events.ErrorQuery)
+		assert.Equal(t, "latency", spec.SLOs[1].Name)
+		assert.Equal(t, 99.0, spec.SLOs[1].Objective)
+	})
+
+	t.Run("Successfully parse the sloth annotations from the content reader", func(t *testing.T) {
+		language, err := Language("ini")
+		require.NoError(t, err)
+
+		opts := comments.NewOptions()
+		opts.SourceContent = io.NopCloser(strings.NewReader("; @sloth service foobar\n[foobar]\n"))
+		specs, err := comments.NewParser(language, opts).Parse(context.Background())
+		require.NoError(t, err)
+		_, ok := specs["foobar"]
+		assert.True(t, ok)
+	})
+}
@@ -0,0 +1,156 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"go/ast"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/slosive/sloscribe/internal/parser/specification/sloth/language/comments"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+)
+
+type (
+	// wasmModule is a compiled WebAssembly language module, a new sandboxed instance of the module is used for each file
+	wasmModule struct {
+		path     string
+		runtime  wazero.Runtime
+		compiled wazero.CompiledModule
+	}
+
+	// wasmResult is the output of the WebAssembly language module functions
+	wasmResult struct {
+		Groups []CommentGroup `json:"groups"`
+		Error  string         `json:"error"`
+	}
+)
+
+// WasmLanguage returns the language parsed by the WebAssembly module at the path, i.e: ini.wasm.
+// The module is run with the wazero runtime, it has no access to the host other than the memory shared with it.
+// The language is named after the module file, without the .wasm extension.
+func WasmLanguage(path string) (comments.Language, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return comments.Language{}, errors.Annotatef(err, "language module %s could not be read", path)
+	}
+
+	ctx := context.Background()
+	runtime := wazero.NewRuntime(ctx)
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, runtime); err != nil {
+		return comments.Language{}, errors.Annotate(err, "failed to initialise the WebAssembly runtime")
+	}
+	compiled, err := runtime.CompileModule(ctx, src)
+	if err != nil {
+		return comments.Language{}, errors.Annotatef(err, "language module %s could not be compiled", path)
+	}
+	module := &wasmModule{path: path, runtime: runtime, compiled: compiled}
+
+	out, err := module.call(ctx, "extensions", nil)
+	if err != nil {
+		return comments.Language{}, err
+	}
+	var extensions []string
+	if err := json.Unmarshal(out, &extensions); err != nil {
+		return comments.Language{}, errors.Annotatef(err, "language module %s returned invalid extensions", path)
+	}
+
+	return comments.Language{
+		Name:       strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+		Extensions: extensions,
+		Extract:    module.extract,
+	}, nil
+}
+
+// extract returns the comment groups found by the module in the source file
+func (m *wasmModule) extract(fset *token.FileSet, filename string, src []byte) ([]*ast.CommentGroup, error) {
+	out, err := m.call(context.Background(), "extract", src)
+	if err != nil {
+		return nil, err
+	}
+
+	var result wasmResult
+	if err := json.Unmarshal(out, &result); err != nil {
+		return nil, errors.Annotatef(err, "language module %s returned invalid comment groups", m.path)
+	}
+	if result.Error != "" {
+		return nil, errors.Errorf("language module %s failed: %s", m.path, result.Error)
+	}
+
+	file := fset.AddFile(filename, -1, len(src))
+	file.SetLinesForContent(src)
+	var groups []*ast.CommentGroup
+	for _, g := range result.Groups {
+		group, err := g.commentGroup(file)
+		if err != nil {
+			return nil, errors.Annotatef(err, "language module %s returned an invalid comment group", m.path)
+		}
+		if group != nil {
+			groups = append(groups, group)
+		}
+	}
+	return groups, nil
+}
+
+// call instantiates the module and calls the exported function, the input is written to the module memory
+// and passed to the function as pointer and length. The function returns its output pointer and length packed in an uint64.
+func (m *wasmModule) call(ctx context.Context, name string, input []byte) ([]byte, error) {
+	config := wazero.NewModuleConfig().WithName("").WithStartFunctions("_initialize")
+	instance, err := m.runtime.InstantiateModule(ctx, m.compiled, config)
+	if err != nil {
+		return nil, errors.Annotatef(err, "language module %s could not be instantiated", m.path)
+	}
+	defer instance.Close(ctx)
+
+	fn := instance.ExportedFunction(name)
+	if fn == nil {
+		return nil, errors.Errorf("language module %s doesn't export the %s function", m.path, name)
+	}
+
+	var params []uint64
+	if input != nil {
+		ptr, err := m.write(ctx, instance, input)
+		if err != nil {
+			return nil, err
+		}
+		params = []uint64{uint64(ptr), uint64(len(input))}
+	}
+
+	results, err := fn.Call(ctx, params...)
+	if err != nil {
+		return nil, errors.Annotatef(err, "language module %s failed to run %s", m.path, name)
+	}
+	if len(results) != 1 {
+		return nil, errors.Errorf("language module %s function %s returned %d results, expected 1", m.path, name, len(results))
+	}
+
+	ptr, size := uint32(results[0]>>32), uint32(results[0])
+	out, ok := instance.Memory().Read(ptr, size)
+	if !ok {
+		return nil, errors.Errorf("language module %s function %s output is outside of the module memory", m.path, name)
+	}
+	// copy the output as the module memory is released when the instance is closed
+	return append([]byte(nil), out...), nil
+}
+
+// write allocates memory in the module instance, using the exported alloc function, and writes the input to it
+func (m *wasmModule) write(ctx context.Context, instance api.Module, input []byte) (uint32, error) {
+	alloc := instance.ExportedFunction("alloc")
+	if alloc == nil {
+		return 0, errors.Errorf("language module %s doesn't export the alloc function", m.path)
+	}
+	results, err := alloc.Call(ctx, uint64(len(input)))
+	if err != nil {
+		return 0, errors.Annotatef(err, "language module %s failed to allocate memory", m.path)
+	}
+	ptr := uint32(results[0])
+	if !instance.Memory().Write(ptr, input) {
+		return 0, errors.Errorf("language module %s allocated memory outside of the module memory", m.path)
+	}
+	return ptr, nil
+}
@@ -0,0 +1,77 @@
+//go:build go1.24
+
+package plugin
+
+import (
+	"context"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	sloth "github.com/slok/sloth/pkg/prometheus/api/v1"
+	"github.com/slosive/sloscribe/internal/parser/specification/sloth/language/comments"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The reference ini plugin exports its functions with //go:wasmexport, building it for wasip1 requires go 1.24 or later
+func TestWasmLanguage(t *testing.T) {
+	t.Parallel()
+
+	module := filepath.Join(t.TempDir(), "ini.wasm")
+	build := exec.Command("go", "build", "-buildmode=c-shared", "-o", module, "../../../../../../plugins/sloscribe-lang-ini")
+	build.Env = append(os.Environ(), "GOOS=wasip1", "GOARCH=wasm")
+	output, err := build.CombinedOutput()
+	require.NoError(t, err, string(output))
+
+	t.Run("Successfully return the language of the WebAssembly module", func(t *testing.T) {
+		language, err := WasmLanguage(module)
+		require.NoError(t, err)
+		assert.Equal(t, "ini", language.Name)
+		assert.Equal(t, []string{".ini", ".cfg"}, language.Extensions)
+	})
+
+	t.Run("Successfully extract the positioned comment groups from the content", func(t *testing.T) {
+		language, err := WasmLanguage(module)
+		require.NoError(t, err)
+
+		fset := token.NewFileSet()
+		groups, err := language.Comments(fset, "app.ini", []byte("; @sloth service foo\n[foo]\n  # @sloth service bar\n"))
+		require.NoError(t, err)
+		require.Len(t, groups, 2)
+		assert.Equal(t, "@sloth service foo\n", groups[0].Text())
+		assert.Equal(t, "@sloth service bar\n", groups[1].Text())
+
+		position := fset.Position(groups[1].Pos())
+		assert.Equal(t, 3, position.Line)
+		assert.Equal(t, 4, position.Column)
+	})
+
+	t.Run("Successfully parse the sloth annotations in the files in the target directory", func(t *testing.T) {
+		language, err := WasmLanguage(module)
+		require.NoError(t, err)
+
+		opts := comments.NewOptions()
+		opts.InputDirectories = []string{"./testdata"}
+		specs, err := comments.NewParser(language, opts).Parse(context.Background())
+		require.NoError(t, err)
+
+		spec, ok := specs["chatgpt"].(*sloth.Spec)
+		require.True(t, ok)
+		require.Len(t, spec.SLOs, 2)
+		assert.Equal(t, "availability", spec.SLOs[0].Name)
+		assert.Equal(t, "latency", spec.SLOs[1].Name)
+	})
+
+	t.Run("Fail to return the language of a non existing module", func(t *testing.T) {
+		_, err := WasmLanguage("./testdata/fake.wasm")
+		require.Error(t, err)
+	})
+
+	t.Run("Fail to return the language of an invalid module", func(t *testing.T) {
+		_, err := WasmLanguage("./testdata/config/app.ini")
+		require.Error(t, err)
+	})
+}
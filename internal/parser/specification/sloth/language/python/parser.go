@@ -0,0 +1,39 @@
+package python
+
+import (
+	"github.com/slosive/sloscribe/internal/parser/specification/sloth/language"
+	"github.com/slosive/sloscribe/internal/parser/specification/sloth/language/comments"
+)
+
+// Options contains the configuration options available to the Parser
+type Options = comments.Options
+
+// Language is the python comments syntax, it covers # comments and docstrings
+var Language = comments.Language{
+	Name: "python",
+	Syntax: comments.Syntax{
+		LineComments: []string{"#"},
+		DocStrings: []comments.Delimiters{
+			{Start: `"""`, End: `"""`},
+			{Start: `'''`, End: `'''`},
+		},
+		Strings: []comments.Delimiters{
+			{Start: `"""`, End: `"""`},
+			{Start: `'''`, End: `'''`},
+			{Start: `"`, End: `"`},
+			{Start: `'`, End: `'`},
+		},
+	},
+	Extensions:   []string{".py", ".pyi"},
+	Interpreters: []string{"python"},
+	ExcludedDirs: []string{"__pycache__", ".venv", "venv", ".tox", ".git"},
+}
+
+func NewOptions() *Options {
+	return comments.NewOptions()
+}
+
+// NewParser client parser performs all checks at initialization time
+func NewParser(opts *Options) language.Language {
+	return comments.NewParser(Language, opts)
+}
@@ -0,0 +1,114 @@
+package python
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	sloth "github.com/slok/sloth/pkg/prometheus/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parse(t *testing.T, src string) map[string]any {
+	t.Helper()
+	opts := NewOptions()
+	opts.SourceContent = io.NopCloser(strings.NewReader(src))
+	specs, err := NewParser(opts).Parse(context.Background())
+	require.NoError(t, err)
+	return specs
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Successfully parse the # comments and the docstrings of the python files in the target directory", func(t *testing.T) {
+		opts := NewOptions()
+		opts.InputDirectories = []string{"./testdata"}
+		specs, err := NewParser(opts).Parse(context.Background())
+		require.NoError(t, err)
+		require.Len(t, specs, 1)
+
+		spec, ok := specs["chatgpt"].(*sloth.Spec)
+		require.True(t, ok)
+		// the module docstring
+		assert.Equal(t, map[string]string{"team": "platform"}, spec.Labels)
+		require.Len(t, spec.SLOs, 2)
+
+		// the # comment group
+		assert.Equal(t, "chat-gpt-availability", spec.SLOs[0].Name)
+		assert.Equal(t, "95% of logins to the chat-gpt app should be successful.", spec.SLOs[0].Description)
+		require.NotNil(t, spec.SLOs[0].SLI.Events)
+		assert.Equal(t, `sum(rate(tenant_failed_login_operations_total{client="chat-gpt"}[{{.window}}])) OR on() vector(0)`, spec.SLOs[0].SLI.Events.ErrorQuery)
+
+		// the indented function docstring
+		assert.Equal(t, "chat-gpt-latency", spec.SLOs[1].Name)
+		require.NotNil(t, spec.SLOs[1].SLI.Raw)
+		assert.Equal(t, `sum(rate(tenant_slow_requests_total[{{.window}}]))`, spec.SLOs[1].SLI.Raw.ErrorRatioQuery)
+	})
+
+	t.Run("Successfully skip the __pycache__ and virtualenv directories", func(t *testing.T) {
+		opts := NewOptions()
+		opts.InputDirectories = []string{"./testdata"}
+		specs, err := NewParser(opts).Parse(context.Background())
+		require.NoError(t, err)
+		assert.NotContains(t, specs, "cached")
+		assert.NotContains(t, specs, "virtualenv")
+	})
+
+	t.Run("Successfully ignore the triple quoted strings which aren't docstrings", func(t *testing.T) {
+		specs := parse(t, `# @sloth service foobar
+QUERY = """@sloth.slo name not-an-slo"""
+HELP = '''
+@sloth.slo name not-an-slo-either
+'''
+
+def handler():
+    """@sloth.slo name availability
+    @sloth.sli error_ratio_query sum(rate(errors_total[{{.window}}]))
+    """
+`)
+		spec, ok := specs["foobar"].(*sloth.Spec)
+		require.True(t, ok)
+		require.Len(t, spec.SLOs, 1)
+		assert.Equal(t, "availability", spec.SLOs[0].Name)
+	})
+
+	t.Run("Successfully ignore the # inside the string literals", func(t *testing.T) {
+		specs := parse(t, `URL = "http://localhost:9090/#/ # @sloth service not-a-service"
+LABEL = 'it is # @sloth service not-a-service-either'
+QUOTE = "\" # @sloth service escaped"
+# @sloth service foobar
+`)
+		assert.Len(t, specs, 1)
+		assert.Contains(t, specs, "foobar")
+	})
+
+	t.Run("Successfully split the # comment groups separated by code", func(t *testing.T) {
+		specs := parse(t, `# @sloth service foobar
+# @sloth.slo name availability
+counter = Counter("requests_total", "requests")  # @sloth.slo name trailing
+# @sloth.slo name latency
+`)
+		spec, ok := specs["foobar"].(*sloth.Spec)
+		require.True(t, ok)
+		require.Len(t, spec.SLOs, 3)
+		assert.Equal(t, "availability", spec.SLOs[0].Name)
+		assert.Equal(t, "trailing", spec.SLOs[1].Name)
+		assert.Equal(t, "latency", spec.SLOs[2].Name)
+	})
+
+	t.Run("Successfully detect the scripts run by the versioned python interpreters", func(t *testing.T) {
+		assert.True(t, Language.IsScript("#!/usr/bin/env python3"))
+		assert.True(t, Language.IsScript("#!/usr/bin/python3.11"))
+		assert.False(t, Language.IsScript("#!/usr/bin/env pythonw"))
+	})
+
+	t.Run("Fail to parse a non existing python file", func(t *testing.T) {
+		opts := NewOptions()
+		opts.SourceFile = "./testdata/app/fake.py"
+		_, err := NewParser(opts).Parse(context.Background())
+		require.Error(t, err)
+	})
+}
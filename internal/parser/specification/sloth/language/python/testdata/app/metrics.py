@@ -0,0 +1,23 @@
+"""@sloth service chatgpt
+@sloth labels team platform
+"""
+from prometheus_client import Counter
+
+URL = "http://localhost:9090 # @sloth service not-a-service"
+
+# @sloth.slo name chat-gpt-availability
+# @sloth.slo objective 95.0
+# @sloth.sli error_query sum(rate(tenant_failed_login_operations_total{client="chat-gpt"}[{{.window}}])) OR on() vector(0)
+# @sloth.sli total_query sum(rate(tenant_login_operations_total{client="chat-gpt"}[{{.window}}]))
+# @sloth.slo description 95% of logins to the chat-gpt app should be successful.
+# @sloth.alerting name ChatGPTAvailability
+tenant_logins = Counter("tenant_login_operations", "tenant logins")
+
+
+def latency():
+    """
+    @sloth.slo name chat-gpt-latency
+    @sloth.slo objective 99.0
+    @sloth.sli error_ratio_query sum(rate(tenant_slow_requests_total[{{.window}}]))
+    """
+    return 'it is # not a comment'
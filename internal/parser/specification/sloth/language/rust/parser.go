@@ -0,0 +1,79 @@
+package rust
+
+import (
+	"bytes"
+	"unicode/utf8"
+
+	"github.com/slosive/sloscribe/internal/parser/specification/sloth/language"
+	"github.com/slosive/sloscribe/internal/parser/specification/sloth/language/comments"
+)
+
+// Options contains the configuration options available to the Parser
+type Options = comments.Options
+
+// Language is the rust comments syntax, it covers line, doc and nested block comments
+var Language = comments.Language{
+	Name: "rust",
+	Syntax: comments.Syntax{
+		LineComments:        []string{"//"},
+		BlockComments:       []comments.Delimiters{{Start: "/*", End: "*/"}},
+		NestedBlockComments: true,
+		DocMarkers:          "/!*",
+		Strings:             []comments.Delimiters{{Start: `"`, End: `"`}},
+		Literal:             literal,
+	},
+	Extensions:   []string{".rs"},
+	Interpreters: []string{"rust-script"},
+	ExcludedDirs: []string{"target", ".git"},
+}
+
+func NewOptions() *Options {
+	return comments.NewOptions()
+}
+
+// NewParser client parser performs all checks at initialization time
+func NewParser(opts *Options) language.Language {
+	return comments.NewParser(Language, opts)
+}
+
+// literal returns the end offset of the raw string or character literal starting at offset.
+// Lifetimes, i.e: 'a, aren't literals and are skipped.
+func literal(src []byte, offset int) int {
+	switch src[offset] {
+	case 'r':
+		// raw strings, i.e: r"..." or r#"..."#, can't be escaped
+		if offset > 0 && isIdentifier(src[offset-1]) && src[offset-1] != 'b' {
+			return offset
+		}
+		i := offset + 1
+		for i < len(src) && src[i] == '#' {
+			i++
+		}
+		if i >= len(src) || src[i] != '"' {
+			return offset
+		}
+		end := append([]byte{'"'}, bytes.Repeat([]byte{'#'}, i-offset-1)...)
+		if n := bytes.Index(src[i+1:], end); n >= 0 {
+			return i + 1 + n + len(end)
+		}
+		return len(src)
+	case '\'':
+		// character literals, i.e: 'a', '\n' or '\u{1F600}'
+		i := offset + 1
+		if i+1 < len(src) && src[i] == '\\' {
+			if n := bytes.IndexByte(src[i+2:], '\''); n >= 0 {
+				return i + 2 + n + 1
+			}
+			return offset
+		}
+		_, size := utf8.DecodeRune(src[i:])
+		if i+size < len(src) && src[i+size] == '\'' {
+			return i + size + 1
+		}
+	}
+	return offset
+}
+
+func isIdentifier(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
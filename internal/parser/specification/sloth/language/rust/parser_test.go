@@ -0,0 +1,92 @@
+package rust
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	sloth "github.com/slok/sloth/pkg/prometheus/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Successfully parse the sloth annotations in the rust files in the target directory", func(t *testing.T) {
+		opts := NewOptions()
+		opts.InputDirectories = []string{"./testdata"}
+		specs, err := NewParser(opts).Parse(context.Background())
+		require.NoError(t, err)
+		require.Len(t, specs, 1)
+
+		spec, ok := specs["chatgpt"].(*sloth.Spec)
+		require.True(t, ok)
+		assert.Equal(t, map[string]string{"team": "platform"}, spec.Labels)
+		require.Len(t, spec.SLOs, 2)
+
+		assert.Equal(t, "chat-gpt-availability", spec.SLOs[0].Name)
+		assert.Equal(t, 95.0, spec.SLOs[0].Objective)
+		assert.Equal(t, "95% of logins to the chat-gpt app should be successful.", spec.SLOs[0].Description)
+		assert.Equal(t, "ChatGPTAvailability", spec.SLOs[0].Alerting.Name)
+		require.NotNil(t, spec.SLOs[0].SLI.Events)
+		assert.Equal(t, `sum(rate(tenant_failed_login_operations_total{client="chat-gpt"}[{{.window}}])) OR on() vector(0)`, spec.SLOs[0].SLI.Events.ErrorQuery)
+		assert.Equal(t, `sum(rate(tenant_login_operations_total{client="chat-gpt"}[{{.window}}]))`, spec.SLOs[0].SLI.Events.TotalQuery)
+
+		assert.Equal(t, "chat-gpt-latency", spec.SLOs[1].Name)
+		assert.Equal(t, 99.0, spec.SLOs[1].Objective)
+		require.NotNil(t, spec.SLOs[1].SLI.Raw)
+		assert.Equal(t, `sum(rate(tenant_slow_requests_total[{{.window}}]))`, spec.SLOs[1].SLI.Raw.ErrorRatioQuery)
+	})
+
+	t.Run("Successfully parse the sloth annotations in the target rust file", func(t *testing.T) {
+		opts := NewOptions()
+		opts.SourceFile = "./testdata/src/lib.rs"
+		specs, err := NewParser(opts).Parse(context.Background())
+		require.NoError(t, err)
+		require.Len(t, specs, 1)
+
+		spec, ok := specs["chatgpt"].(*sloth.Spec)
+		require.True(t, ok)
+		require.Len(t, spec.SLOs, 1)
+		assert.Equal(t, "chat-gpt-availability", spec.SLOs[0].Name)
+	})
+
+	t.Run("Successfully parse the sloth annotations from the content reader", func(t *testing.T) {
+		opts := NewOptions()
+		opts.SourceContent = io.NopCloser(strings.NewReader(`
+// @sloth service foobar
+fn main() {}
+`))
+		specs, err := NewParser(opts).Parse(context.Background())
+		require.NoError(t, err)
+		_, ok := specs["foobar"]
+		assert.True(t, ok)
+	})
+
+	t.Run("Fail to parse a non existing rust file", func(t *testing.T) {
+		opts := NewOptions()
+		opts.SourceFile = "./testdata/src/fake.rs"
+		_, err := NewParser(opts).Parse(context.Background())
+		require.Error(t, err)
+	})
+}
+
+func TestLiteral(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Successfully skip raw strings", func(t *testing.T) {
+		src := []byte(`r#"// "not a comment"#;`)
+		assert.Equal(t, len(src)-1, literal(src, 0))
+	})
+
+	t.Run("Successfully skip character literals", func(t *testing.T) {
+		assert.Equal(t, 3, literal([]byte(`'"';`), 0))
+		assert.Equal(t, 4, literal([]byte(`'\'';`), 0))
+	})
+
+	t.Run("Successfully ignore lifetimes", func(t *testing.T) {
+		assert.Equal(t, 0, literal([]byte(`'a str`), 0))
+	})
+}
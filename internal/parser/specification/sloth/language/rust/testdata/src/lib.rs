@@ -0,0 +1,18 @@
+//! @sloth service chatgpt
+//! @sloth labels team platform
+
+use prometheus::{IntCounter, Opts};
+
+const URL: &str = "http://localhost:9090 // @sloth service not-a-service";
+const RAW: &str = r#"/* @sloth service not-a-service-either */"#;
+
+/// @sloth.slo name chat-gpt-availability
+/// @sloth.slo objective 95.0
+/// @sloth.sli error_query sum(rate(tenant_failed_login_operations_total{client="chat-gpt"}[{{.window}}])) OR on() vector(0)
+/// @sloth.sli total_query sum(rate(tenant_login_operations_total{client="chat-gpt"}[{{.window}}]))
+/// @sloth.slo description 95% of logins to the chat-gpt app should be successful.
+/// @sloth.alerting name ChatGPTAvailability
+pub fn tenant_logins<'a>(name: &'a str) -> IntCounter {
+    let quote = '"';
+    IntCounter::with_opts(Opts::new(name, "tenant logins")).unwrap()
+}
@@ -0,0 +1,11 @@
+/*
+@sloth.slo name chat-gpt-latency
+@sloth.slo objective 99.0
+@sloth.sli error_ratio_query sum(rate(tenant_slow_requests_total[{{.window}}]))
+*/
+pub fn latency() {}
+
+/* block comments can be /* nested */
+// @sloth service not-a-service
+*/
+pub fn nested() {}
@@ -0,0 +1,2 @@
+// @sloth service build-output
+// @sloth.slo name ignored
@@ -0,0 +1,73 @@
+package typescript
+
+import (
+	"bytes"
+
+	"github.com/slosive/sloscribe/internal/parser/specification/sloth/language"
+	"github.com/slosive/sloscribe/internal/parser/specification/sloth/language/comments"
+)
+
+// Options contains the configuration options available to the Parser
+type Options = comments.Options
+
+// Language is the typescript and javascript comments syntax, it covers line comments and JSDoc block comments
+var Language = comments.Language{
+	Name: "typescript",
+	Syntax: comments.Syntax{
+		LineComments:     []string{"//"},
+		BlockComments:    []comments.Delimiters{{Start: "/*", End: "*/"}},
+		LeadingAsterisks: true,
+		DocMarkers:       "*",
+		Strings: []comments.Delimiters{
+			{Start: `"`, End: `"`},
+			{Start: `'`, End: `'`},
+			{Start: "`", End: "`"},
+		},
+		Literal: regexLiteral,
+	},
+	Extensions:   []string{".ts", ".tsx", ".js", ".mjs"},
+	Interpreters: []string{"node", "deno", "ts-node", "bun"},
+	ExcludedDirs: []string{"node_modules", ".git"},
+}
+
+func NewOptions() *Options {
+	return comments.NewOptions()
+}
+
+// NewParser client parser performs all checks at initialization time
+func NewParser(opts *Options) language.Language {
+	return comments.NewParser(Language, opts)
+}
+
+// regexLiteral returns the end offset of the regular expression literal starting at offset, i.e: /https?:\/\//.
+// A slash is the start of a regular expression if it can't be a division, given the code before it.
+func regexLiteral(src []byte, offset int) int {
+	if src[offset] != '/' || offset+1 >= len(src) || src[offset+1] == '/' || src[offset+1] == '*' {
+		return offset
+	}
+
+	prev := bytes.TrimRight(src[:offset], " \t\r\n")
+	if len(prev) > 0 && !bytes.ContainsAny(prev[len(prev)-1:], "(,=:[!&|?{};+-*%<>~^") && !bytes.HasSuffix(prev, []byte("return")) {
+		return offset
+	}
+
+	inClass := false
+	for i := offset + 1; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case '\n':
+			// regular expressions can't span multiple lines
+			return offset
+		case '[':
+			inClass = true
+		case ']':
+			inClass = false
+		case '/':
+			if !inClass {
+				return i + 1
+			}
+		}
+	}
+	return offset
+}
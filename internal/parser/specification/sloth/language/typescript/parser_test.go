@@ -0,0 +1,99 @@
+package typescript
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	sloth "github.com/slok/sloth/pkg/prometheus/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Successfully parse the sloth annotations in the typescript and javascript files in the target directory", func(t *testing.T) {
+		opts := NewOptions()
+		opts.InputDirectories = []string{"./testdata"}
+		specs, err := NewParser(opts).Parse(context.Background())
+		require.NoError(t, err)
+		require.Len(t, specs, 1)
+
+		spec, ok := specs["chatgpt"].(*sloth.Spec)
+		require.True(t, ok)
+		assert.Equal(t, map[string]string{"team": "platform"}, spec.Labels)
+		require.Len(t, spec.SLOs, 2)
+
+		// files in the subdirectories are parsed first
+		assert.Equal(t, "chat-gpt-latency", spec.SLOs[0].Name)
+		assert.Equal(t, 99.0, spec.SLOs[0].Objective)
+		require.NotNil(t, spec.SLOs[0].SLI.Raw)
+		assert.Equal(t, `sum(rate(tenant_slow_requests_total[{{.window}}]))`, spec.SLOs[0].SLI.Raw.ErrorRatioQuery)
+
+		assert.Equal(t, "chat-gpt-availability", spec.SLOs[1].Name)
+		assert.Equal(t, 95.0, spec.SLOs[1].Objective)
+		assert.Equal(t, "95% of logins to the chat-gpt app should be successful.", spec.SLOs[1].Description)
+		assert.Equal(t, "ChatGPTAvailability", spec.SLOs[1].Alerting.Name)
+		require.NotNil(t, spec.SLOs[1].SLI.Events)
+		assert.Equal(t, `sum(rate(tenant_failed_login_operations_total{client="chat-gpt"}[{{.window}}])) OR on() vector(0)`, spec.SLOs[1].SLI.Events.ErrorQuery)
+		assert.Equal(t, `sum(rate(tenant_login_operations_total{client="chat-gpt"}[{{.window}}]))`, spec.SLOs[1].SLI.Events.TotalQuery)
+	})
+
+	t.Run("Successfully parse the sloth annotations in the target typescript file", func(t *testing.T) {
+		opts := NewOptions()
+		opts.SourceFile = "./testdata/src/metrics.ts"
+		specs, err := NewParser(opts).Parse(context.Background())
+		require.NoError(t, err)
+		require.Len(t, specs, 1)
+
+		spec, ok := specs["chatgpt"].(*sloth.Spec)
+		require.True(t, ok)
+		require.Len(t, spec.SLOs, 1)
+		assert.Equal(t, "chat-gpt-availability", spec.SLOs[0].Name)
+	})
+
+	t.Run("Successfully parse the sloth annotations from the content reader", func(t *testing.T) {
+		opts := NewOptions()
+		opts.SourceContent = io.NopCloser(strings.NewReader(`
+// @sloth service foobar
+function main() {}
+`))
+		specs, err := NewParser(opts).Parse(context.Background())
+		require.NoError(t, err)
+		_, ok := specs["foobar"]
+		assert.True(t, ok)
+	})
+
+	t.Run("Successfully detect the scripts run by the node interpreters only", func(t *testing.T) {
+		assert.True(t, Language.IsScript("#!/usr/bin/env node"))
+		assert.True(t, Language.IsScript("#!/usr/bin/env -S deno run"))
+		assert.False(t, Language.IsScript("#!/usr/bin/env nodemon"))
+	})
+
+	t.Run("Fail to parse a non existing typescript file", func(t *testing.T) {
+		opts := NewOptions()
+		opts.SourceFile = "./testdata/src/fake.ts"
+		_, err := NewParser(opts).Parse(context.Background())
+		require.Error(t, err)
+	})
+}
+
+func TestRegexLiteral(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Successfully skip regular expression literals", func(t *testing.T) {
+		src := []byte(`x = /\/\/[/]/g;`)
+		assert.Equal(t, len(src)-2, regexLiteral(src, 4))
+	})
+
+	t.Run("Successfully ignore divisions", func(t *testing.T) {
+		assert.Equal(t, 2, regexLiteral([]byte(`a / b // c`), 2))
+	})
+
+	t.Run("Successfully ignore comments", func(t *testing.T) {
+		assert.Equal(t, 0, regexLiteral([]byte(`// c`), 0))
+		assert.Equal(t, 0, regexLiteral([]byte(`/* c */`), 0))
+	})
+}
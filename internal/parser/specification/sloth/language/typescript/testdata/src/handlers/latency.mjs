@@ -0,0 +1,7 @@
+const ratio = 1 / 2; // division, not a regular expression
+
+/** @sloth.slo name chat-gpt-latency
+ *  @sloth.slo objective 99.0
+ *  @sloth.sli error_ratio_query sum(rate(tenant_slow_requests_total[{{.window}}]))
+ */
+export function latency() {}
@@ -0,0 +1,17 @@
+// @sloth service chatgpt
+// @sloth labels team platform
+
+import { Counter } from 'prom-client';
+
+const url = `http://localhost:9090 // @sloth service not-a-service`;
+const pattern = /https?:\/\//;
+
+/**
+ * @sloth.slo name chat-gpt-availability
+ * @sloth.slo objective 95.0
+ * @sloth.sli error_query sum(rate(tenant_failed_login_operations_total{client="chat-gpt"}[{{.window}}])) OR on() vector(0)
+ * @sloth.sli total_query sum(rate(tenant_login_operations_total{client="chat-gpt"}[{{.window}}]))
+ * @sloth.slo description 95% of logins to the chat-gpt app should be successful.
+ * @sloth.alerting name ChatGPTAvailability
+ */
+export const tenantLogins = new Counter({ name: 'tenant_login_operations_total', help: 'tenant logins' });
@@ -2,21 +2,35 @@ package sloth
 
 import (
 	"github.com/slosive/sloscribe/internal/parser/options"
+	"github.com/slosive/sloscribe/internal/parser/specification/sloth/language/comments"
 	"github.com/slosive/sloscribe/internal/parser/specification/sloth/language/golang"
 )
 
 // Parser returns the options.Option to run the parser targeting sloth as a specification
 func Parser(kubernetes bool) options.Option {
 	return func(opts *options.Options) {
+		sourceOpts := sourceOptions(opts, kubernetes)
 		opts.TargetSpecification = newParser(Options{
-			Language: opts.TargetLanguage,
-			GolangOpts: golang.Options{
-				Logger:           opts.Logger,
-				SourceFile:       opts.SourceFile,
-				SourceContent:    opts.SourceContent,
-				InputDirectories: opts.IncludedDirs,
-				Kubernetes:       kubernetes,
-			},
+			Language:        opts.TargetLanguage,
+			LanguagePlugins: opts.LanguagePlugins,
+			GolangOpts:      golang.Options(sourceOpts),
+			CommentsOpts:    sourceOpts,
 		})
 	}
 }
+
+// sourceOptions returns the options of the language parsers, these are the same for every source language
+func sourceOptions(opts *options.Options, kubernetes bool) comments.Options {
+	return comments.Options{
+		Logger:           opts.Logger,
+		SourceFile:       opts.SourceFile,
+		SourceContent:    opts.SourceContent,
+		InputDirectories: opts.IncludedDirs,
+		Kubernetes:       kubernetes,
+		Reporter:         opts.Reporter,
+		Positions:        opts.Positions,
+		Strict:           opts.Strict,
+		ValidateQueries:  opts.ValidateQueries,
+		Variables:        opts.Variables,
+	}
+}
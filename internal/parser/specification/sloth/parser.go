@@ -5,36 +5,125 @@ import (
 
 	"github.com/slosive/sloscribe/internal/parser/lang"
 	"github.com/slosive/sloscribe/internal/parser/specification/sloth/language"
+	"github.com/slosive/sloscribe/internal/parser/specification/sloth/language/comments"
 	"github.com/slosive/sloscribe/internal/parser/specification/sloth/language/golang"
+	"github.com/slosive/sloscribe/internal/parser/specification/sloth/language/java"
+	"github.com/slosive/sloscribe/internal/parser/specification/sloth/language/kotlin"
+	"github.com/slosive/sloscribe/internal/parser/specification/sloth/language/plugin"
+	"github.com/slosive/sloscribe/internal/parser/specification/sloth/language/python"
+	"github.com/slosive/sloscribe/internal/parser/specification/sloth/language/rust"
+	"github.com/slosive/sloscribe/internal/parser/specification/sloth/language/typescript"
 )
 
+// languages maps the supported languages to their comments syntax, the order is the auto detection priority
+var languages = []struct {
+	target   lang.Target
+	language comments.Language
+}{
+	{lang.Go, golang.Language},
+	{lang.Rust, rust.Language},
+	{lang.Python, python.Language},
+	{lang.TypeScript, typescript.Language},
+	{lang.Java, java.Language},
+	{lang.Kotlin, kotlin.Language},
+}
+
 // Parser struct, stores the language parser used to parse the data source
 type parser struct {
 	languageParser language.Language
+	// err is the error returned by Parse if the language parser couldn't be initialised, i.e: missing language plugin
+	err error
 }
 
 // Options is a struct contains all the configurations available for the sloth parser
 type Options struct {
-	Language   lang.Target
-	GolangOpts golang.Options
+	Language lang.Target
+	// LanguagePlugins are the paths to the WebAssembly language modules, their languages are detected before the targeted languages
+	LanguagePlugins []string
+	GolangOpts      golang.Options
+	// CommentsOpts are the options of the comments parser, used by the other languages and when several languages, or auto, are targeted
+	CommentsOpts comments.Options
 }
 
 // newParser client parser performs all checks at initialization time
 func newParser(opts Options) *parser {
 	var selectedLanguageParser language.Language
-	switch opts.Language {
-	case lang.Go:
-		selectedLanguageParser = golang.NewParser(&opts.GolangOpts)
-	case lang.Rust:
-		// TODO implement
+	var err error
+	switch {
+	case len(opts.LanguagePlugins) > 0:
+		var targets []comments.Language
+		targets, err = pluginLanguages(opts.LanguagePlugins, opts.Language)
+		selectedLanguageParser = comments.NewMultiLanguageParser(targets, &opts.CommentsOpts)
+	default:
+		selectedLanguageParser, err = languageParser(opts)
 	}
 	return &parser{
 		languageParser: selectedLanguageParser,
+		err:            err,
 	}
 }
 
+// languageParser returns the parser of the target language, the languages other than go and several languages are parsed by the comments parser
+func languageParser(opts Options) (language.Language, error) {
+	if opts.Language == lang.Go {
+		return golang.NewParser(&opts.GolangOpts), nil
+	}
+	for _, l := range languages {
+		if l.target == opts.Language {
+			return comments.NewParser(l.language, &opts.CommentsOpts), nil
+		}
+	}
+	targets, err := targetLanguages(opts.Language)
+	return comments.NewMultiLanguageParser(targets, &opts.CommentsOpts), err
+}
+
+// pluginLanguages returns the languages of the WebAssembly language modules followed by the targeted languages
+func pluginLanguages(paths []string, target lang.Target) ([]comments.Language, error) {
+	var selected []comments.Language
+	for _, path := range paths {
+		l, err := plugin.WasmLanguage(path)
+		if err != nil {
+			return nil, err
+		}
+		selected = append(selected, l)
+	}
+	targets, err := targetLanguages(target)
+	if err != nil {
+		return nil, err
+	}
+	return append(selected, targets...), nil
+}
+
+// targetLanguages returns the comments syntax of the targeted languages, auto targets all the supported languages.
+// The languages that aren't supported are parsed by the language plugins found in PATH.
+func targetLanguages(target lang.Target) ([]comments.Language, error) {
+	var selected []comments.Language
+	for _, t := range target.Languages() {
+		supported := false
+		for _, l := range languages {
+			if t == lang.Auto || t == l.target {
+				selected = append(selected, l.language)
+				supported = true
+			}
+		}
+		if supported {
+			continue
+		}
+		l, err := plugin.Language(string(t))
+		if err != nil {
+			return nil, err
+		}
+		selected = append(selected, l)
+	}
+	return selected, nil
+}
+
 // Parse parses the sloth specification using the target language parser
 func (p parser) Parse(ctx context.Context) (map[string]any, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+
 	specs, err := p.languageParser.Parse(ctx)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,3 @@
+// Package promql contains the validation of the PromQL queries of the sloth SLIs,
+// parsed by the Prometheus PromQL parser once the sloth templates are replaced.
+package promql
@@ -0,0 +1,69 @@
+package promql
+
+import (
+	"regexp"
+	"sort"
+
+	"github.com/juju/errors"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// SampleWindow is the duration replacing the {{.window}} template of the queries before these are parsed
+const SampleWindow = "5m"
+
+// windowTemplate matches the sloth window template in the SLI queries, i.e: {{.window}}
+var windowTemplate = regexp.MustCompile(`{{\s*\.window\s*}}`)
+
+// Validate returns an error if the SLI query isn't a valid PromQL expression.
+// The {{.window}} template is replaced by SampleWindow before the query is parsed.
+func Validate(query string) error {
+	_, err := parser.ParseExpr(windowTemplate.ReplaceAllString(query, SampleWindow))
+	if err == nil {
+		return nil
+	}
+	// the positions of the parse errors refer to the query with the template replaced, only the messages are returned
+	var parseErrs parser.ParseErrors
+	if errors.As(err, &parseErrs) && len(parseErrs) > 0 {
+		return errors.New(parseErrs[0].Err.Error())
+	}
+	return err
+}
+
+// Selector is a vector selector of a query, i.e: http_requests_total{code=~"5.."}
+type Selector struct {
+	// Metric is the metric name of the selector, it is empty if the name is matched by a regular expression
+	Metric string
+	// Labels are the names of the labels matched by the selector, sorted
+	Labels []string
+}
+
+// Selectors returns the vector selectors of the SLI query, in order of appearance.
+// The {{.window}} template is replaced by SampleWindow before the query is parsed.
+func Selectors(query string) ([]Selector, error) {
+	expr, err := parser.ParseExpr(windowTemplate.ReplaceAllString(query, SampleWindow))
+	if err != nil {
+		return nil, err
+	}
+	var selectors []Selector
+	parser.Inspect(expr, func(node parser.Node, _ []parser.Node) error {
+		vs, ok := node.(*parser.VectorSelector)
+		if !ok {
+			return nil
+		}
+		selector := Selector{Metric: vs.Name}
+		for _, matcher := range vs.LabelMatchers {
+			if matcher.Name != labels.MetricName {
+				selector.Labels = append(selector.Labels, matcher.Name)
+				continue
+			}
+			if matcher.Type == labels.MatchEqual {
+				selector.Metric = matcher.Value
+			}
+		}
+		sort.Strings(selector.Labels)
+		selectors = append(selectors, selector)
+		return nil
+	})
+	return selectors, nil
+}
@@ -0,0 +1,50 @@
+package promql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Successfully validate the queries using the window template", func(t *testing.T) {
+		assert.NoError(t, Validate(`sum(rate(http_request_duration_seconds_count{job="myservice",code=~"(5..|429)"}[{{.window}}]))`))
+		assert.NoError(t, Validate(`sum(rate(http_request_duration_seconds_count[{{ .window }}])) / sum(rate(http_requests_total[{{.window}}]))`))
+	})
+
+	t.Run("Fail to validate the queries with a syntax error", func(t *testing.T) {
+		err := Validate(`sum(rate(http_request_duration_seconds_count{code=~"(5..|429)"[{{.window}}]))`)
+		assert.Error(t, err)
+		assert.NotContains(t, err.Error(), "1:")
+
+		assert.Error(t, Validate(`sum(rate(http_requests_total[{{.window}}])`))
+		assert.Error(t, Validate(`rate(http_requests_total)`))
+	})
+}
+
+func TestSelectors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Successfully return the vector selectors of the query", func(t *testing.T) {
+		selectors, err := Selectors(`sum(rate(tenant_failed_login_operations_total{client="chat-gpt",code=~"5.."}[{{.window}}])) / sum(rate({__name__="tenant_login_operations_total"}[{{.window}}])) OR on() vector(0)`)
+		require.NoError(t, err)
+		assert.Equal(t, []Selector{
+			{Metric: "tenant_failed_login_operations_total", Labels: []string{"client", "code"}},
+			{Metric: "tenant_login_operations_total"},
+		}, selectors)
+	})
+
+	t.Run("Successfully return an empty metric name if it is matched by a regular expression", func(t *testing.T) {
+		selectors, err := Selectors(`sum(rate({__name__=~"tenant_.*",client="chat-gpt"}[{{.window}}]))`)
+		require.NoError(t, err)
+		assert.Equal(t, []Selector{{Labels: []string{"client"}}}, selectors)
+	})
+
+	t.Run("Fail to return the vector selectors of a query with a syntax error", func(t *testing.T) {
+		_, err := Selectors(`sum(rate(http_requests_total[{{.window}}])`)
+		assert.Error(t, err)
+	})
+}
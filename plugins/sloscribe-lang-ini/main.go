@@ -0,0 +1,100 @@
+// Command sloscribe-lang-ini is the reference sloscribe language plugin, it extracts the comment groups from ini files.
+// Comments are the lines starting with ; or #, adjacent comment lines belong to the same group.
+//
+// Install it in PATH and parse the ini files with:
+//
+//	sloscribe init --lang ini --dirs ./config
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+type (
+	file struct {
+		File   string         `json:"file"`
+		Groups []commentGroup `json:"groups"`
+	}
+
+	commentGroup struct {
+		Comments []comment `json:"comments"`
+	}
+
+	comment struct {
+		Line   int    `json:"line"`
+		Column int    `json:"column"`
+		Text   string `json:"text"`
+	}
+)
+
+var extensions = []string{".ini", ".cfg"}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: sloscribe-lang-ini --extensions | FILE... | -")
+	}
+
+	encoder := json.NewEncoder(stdout)
+	if len(args) == 1 && args[0] == "--extensions" {
+		return encoder.Encode(extensions)
+	}
+
+	files := make([]file, 0, len(args))
+	for _, name := range args {
+		var src []byte
+		var err error
+		if name == "-" {
+			src, err = io.ReadAll(stdin)
+		} else {
+			src, err = os.ReadFile(name)
+		}
+		if err != nil {
+			return err
+		}
+		files = append(files, file{File: name, Groups: scan(src)})
+	}
+	return encoder.Encode(files)
+}
+
+// scan returns the groups of adjacent comment lines in the ini source
+func scan(src []byte) []commentGroup {
+	groups := []commentGroup{}
+	var current []comment
+
+	scanner := bufio.NewScanner(bytes.NewReader(src))
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		trimmed := strings.TrimLeft(text, " \t")
+		if !strings.HasPrefix(trimmed, ";") && !strings.HasPrefix(trimmed, "#") {
+			// sections, keys and empty lines separate the comment groups
+			if len(current) > 0 {
+				groups = append(groups, commentGroup{Comments: current})
+				current = nil
+			}
+			continue
+		}
+		// the text starts right after the ; or # marker
+		current = append(current, comment{
+			Line:   line,
+			Column: len(text) - len(trimmed) + 2,
+			Text:   strings.TrimRight(trimmed[1:], "\r"),
+		})
+	}
+	if len(current) > 0 {
+		groups = append(groups, commentGroup{Comments: current})
+	}
+	return groups
+}
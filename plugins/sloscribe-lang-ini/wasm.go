@@ -0,0 +1,67 @@
+//go:build wasip1
+
+// The functions are exported with //go:wasmexport, building the module requires go 1.24 or later
+
+package main
+
+import (
+	"encoding/json"
+	"unsafe"
+)
+
+// buffers keeps the memory shared with the host referenced, so that it isn't garbage collected
+var buffers = map[uint32][]byte{}
+
+// alloc allocates the memory the host writes the file content to
+//
+//go:wasmexport alloc
+func alloc(size uint32) uint32 {
+	if size == 0 {
+		size = 1
+	}
+	buf := make([]byte, size)
+	ptr := uint32(uintptr(unsafe.Pointer(&buf[0])))
+	buffers[ptr] = buf
+	return ptr
+}
+
+// free releases the memory allocated by alloc, or returned by extensions and extract
+//
+//go:wasmexport free
+func free(ptr uint32) {
+	delete(buffers, ptr)
+}
+
+// extensions returns the JSON array of the ini file extensions
+//
+//go:wasmexport extensions
+func wasmExtensions() uint64 {
+	out, err := json.Marshal(extensions)
+	if err != nil {
+		return result(map[string]string{"error": err.Error()})
+	}
+	return pack(out)
+}
+
+// extract returns the comment groups found in the ini content written at ptr
+//
+//go:wasmexport extract
+func extract(ptr, size uint32) uint64 {
+	src := buffers[ptr][:size]
+	return result(map[string]any{"groups": scan(src)})
+}
+
+func result(v any) uint64 {
+	out, err := json.Marshal(v)
+	if err != nil {
+		out = []byte(`{"error": "failed to encode the comment groups"}`)
+	}
+	return pack(out)
+}
+
+// pack returns the pointer to the output in the high 32 bits and its length in the low 32 bits
+func pack(out []byte) uint64 {
+	ptr := alloc(uint32(len(out)))
+	copy(buffers[ptr], out)
+	return uint64(ptr)<<32 | uint64(len(out))
+}